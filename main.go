@@ -5,11 +5,7 @@ import (
 	"flag"
 	"log"
 
-	"github.com/hashicorp/terraform-plugin-framework/providerserver"
-	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
-	"github.com/hashicorp/terraform-plugin-mux/tf5to6server"
-	"github.com/hashicorp/terraform-plugin-mux/tf6muxserver"
 
 	"github.com/netactuate/terraform-provider-netactuate/netactuate"
 )
@@ -25,29 +21,7 @@ func main() {
 
 	ctx := context.Background()
 
-	// Wrap the SDK v2 provider with tf5to6server to upgrade it to protocol 6
-	upgradedSdkProvider, err := tf5to6server.UpgradeServer(
-		ctx,
-		netactuate.NewSDKProvider(netactuate.ProviderVersion).GRPCProvider,
-	)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Create the new Plugin Framework provider
-	frameworkProvider := providerserver.NewProtocol6(
-		netactuate.NewFrameworkProvider(netactuate.ProviderVersion),
-	)
-
-	// Mux the providers together
-	providers := []func() tfprotov6.ProviderServer{
-		func() tfprotov6.ProviderServer {
-			return upgradedSdkProvider
-		},
-		frameworkProvider,
-	}
-
-	muxServer, err := tf6muxserver.NewMuxServer(ctx, providers...)
+	providerServer, err := netactuate.NewProviderServer(ctx, netactuate.ProviderVersion)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -59,7 +33,7 @@ func main() {
 
 	err = tf6server.Serve(
 		ProviderAddr,
-		muxServer.ProviderServer,
+		providerServer,
 		serveOpts...,
 	)
 	if err != nil {
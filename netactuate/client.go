@@ -0,0 +1,147 @@
+package netactuate
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/netactuate/gona/gona"
+)
+
+// APIVersion identifies a generation of the NetActuate API.
+type APIVersion string
+
+const (
+	// APIVersionV2 is the current (vapi2) generation of the NetActuate API.
+	APIVersionV2 APIVersion = "v2"
+
+	DefaultAPIVersion = APIVersionV2
+)
+
+// Capability names a backend feature whose availability depends on the
+// API generation the client talks to.
+type Capability string
+
+const (
+	CapabilityBGPSessions  Capability = "bgp_sessions"
+	CapabilityCloudPools   Capability = "cloud_pools"
+	CapabilityServerUnlink Capability = "server_unlink"
+)
+
+type apiGeneration struct {
+	endpoint     string
+	capabilities []Capability
+}
+
+// apiGenerations lists every API generation the provider knows how to talk
+// to. New generations are opted into with the provider's api_version setting.
+var apiGenerations = map[APIVersion]apiGeneration{
+	APIVersionV2: {
+		endpoint: gona.BaseEndpoint,
+		capabilities: []Capability{
+			CapabilityBGPSessions,
+			CapabilityCloudPools,
+			CapabilityServerUnlink,
+		},
+	},
+}
+
+// ClientInterface is the NetActuate API surface used by the provider. It is
+// implemented by *gona.Client and by the decorators wrapping it.
+type ClientInterface interface {
+	GetServers(ctx context.Context) ([]gona.Server, error)
+	GetServer(ctx context.Context, id int) (gona.Server, error)
+	CreateServer(ctx context.Context, r *gona.CreateServerRequest) (gona.ServerBuild, error)
+	BuildServer(ctx context.Context, id int, r *gona.BuildServerRequest) (gona.ServerBuild, error)
+	DeleteServer(ctx context.Context, id int, cancelBilling bool) error
+	UnlinkServer(ctx context.Context, id int) error
+	StartServer(ctx context.Context, id int) error
+	StopServer(ctx context.Context, id int) error
+
+	GetIPs(ctx context.Context, mbPkgID int) (gona.IPs, error)
+
+	GetLocations(ctx context.Context) ([]gona.Location, error)
+	GetLocationForPool(ctx context.Context, pool gona.CloudPool) ([]gona.Location, error)
+	GetOSs(ctx context.Context) ([]gona.OS, error)
+	GetPlans(ctx context.Context) ([]gona.Plan, error)
+	GetPackages(ctx context.Context) ([]gona.Package, error)
+	GetPackage(ctx context.Context, id int) (gona.Package, error)
+
+	GetSSHKeys(ctx context.Context) ([]gona.SSHKey, error)
+	GetSSHKey(ctx context.Context, id int) (gona.SSHKey, error)
+	CreateSSHKey(ctx context.Context, name, key string) (gona.SSHKey, error)
+	DeleteSSHKey(ctx context.Context, id int) error
+
+	GetBGPSession(ctx context.Context, id int) (*gona.BGPSession, error)
+	GetBGPSessions(ctx context.Context, mbPkgID int) ([]*gona.BGPSession, error)
+	CreateBGPSessions(ctx context.Context, mbPkgID int, groupID int, isIPV6 bool, redundant bool) (*gona.BGPSession, error)
+}
+
+var _ ClientInterface = (*gona.Client)(nil)
+
+// Client wraps the NetActuate API client together with the API generation
+// it was configured for, so resources can check for backend capabilities.
+type Client struct {
+	ClientInterface
+	apiVersion APIVersion
+	clock      clock
+}
+
+// NewClient creates a client for the given API generation. An empty apiURL
+// selects the default endpoint of that generation.
+func NewClient(apiKey, apiURL string, version APIVersion) (*Client, error) {
+	generation, ok := apiGenerations[version]
+	if !ok {
+		return nil, codedErrorf(CodeClientSetupFailed, "unsupported api_version %q, expected one of: %s",
+			version, strings.Join(supportedAPIVersions(), ", "))
+	}
+
+	if apiURL == "" {
+		apiURL = generation.endpoint
+	}
+
+	return &Client{
+		ClientInterface: gona.NewClientCustom(apiKey, apiURL),
+		apiVersion:      version,
+		clock:           realClock{},
+	}, nil
+}
+
+// Use routes every subsequent API call made through the client via the given
+// middleware. Middleware registered first sees calls first.
+func (c *Client) Use(middleware ...Middleware) {
+	if len(middleware) == 0 {
+		return
+	}
+	c.ClientInterface = &middlewareClient{next: c.ClientInterface, middleware: middleware}
+}
+
+// APIVersion returns the API generation the client was configured for.
+func (c *Client) APIVersion() APIVersion {
+	return c.apiVersion
+}
+
+// Supports reports whether the configured API generation offers capability.
+func (c *Client) Supports(capability Capability) bool {
+	return slices.Contains(apiGenerations[c.apiVersion].capabilities, capability)
+}
+
+func (c *Client) pollClock() clock {
+	return c.clock
+}
+
+func (c *Client) require(capability Capability) error {
+	if !c.Supports(capability) {
+		return codedErrorf(CodeCapabilityUnavailable, "%s is not available with NetActuate API %s", capability, c.apiVersion)
+	}
+	return nil
+}
+
+func supportedAPIVersions() []string {
+	versions := make([]string, 0, len(apiGenerations))
+	for version := range apiGenerations {
+		versions = append(versions, string(version))
+	}
+	slices.Sort(versions)
+	return versions
+}
@@ -0,0 +1,86 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/netactuate/gona/gona"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultMetadataCacheTTL is how long CacheMiddleware keeps the metadata
+// listed by the API.
+const DefaultMetadataCacheTTL = 5 * time.Minute
+
+// metadataCalls are the calls listing the locations, images and plans,
+// which every server resource looks up while planning and applying, and
+// which only NetActuate changes.
+var metadataCalls = map[string]bool{
+	"GetLocations":       true,
+	"GetLocationForPool": true,
+	"GetOSs":             true,
+	"GetPlans":           true,
+}
+
+// CacheMiddleware keeps the results of the metadata calls for ttl, so a
+// large apply lists the locations and images once instead of once per
+// server. Concurrent identical calls are made once and share the result.
+// Errors aren't cached.
+func CacheMiddleware(ttl time.Duration) Middleware {
+	return cacheMiddleware(ttl, realClock{})
+}
+
+type cachedResult struct {
+	res     any
+	expires time.Time
+}
+
+func cacheMiddleware(ttl time.Duration, clk clock) Middleware {
+	var (
+		mu      sync.Mutex
+		results = make(map[string]cachedResult)
+		group   singleflight.Group
+	)
+
+	return func(ctx context.Context, call Call, next Invoker) (any, error) {
+		if !metadataCalls[call.Method] {
+			return next(ctx)
+		}
+		key := fmt.Sprintf("%s%v", call.Method, call.Args)
+
+		mu.Lock()
+		cached, ok := results[key]
+		mu.Unlock()
+		if ok && clk.Now().Before(cached.expires) {
+			return cloneResult(cached.res), nil
+		}
+
+		res, err, _ := group.Do(key, func() (any, error) {
+			res, err := next(ctx)
+			if err == nil {
+				mu.Lock()
+				results[key] = cachedResult{res: res, expires: clk.Now().Add(ttl)}
+				mu.Unlock()
+			}
+			return res, err
+		})
+		return cloneResult(res), err
+	}
+}
+
+// cloneResult copies the lists returned by the metadata calls, so callers
+// filtering them in place don't change the cached ones.
+func cloneResult(res any) any {
+	switch res := res.(type) {
+	case []gona.Location:
+		return slices.Clone(res)
+	case []gona.OS:
+		return slices.Clone(res)
+	case []gona.Plan:
+		return slices.Clone(res)
+	}
+	return res
+}
@@ -0,0 +1,108 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheMiddleware(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeClient()
+	clk := newFakeClock()
+	c := newFakeAPIClient(fake)
+	c.Use(cacheMiddleware(time.Minute, clk))
+
+	for range 3 {
+		_, err := c.GetOSs(ctx)
+		require.NoError(t, err)
+		_, err = c.GetLocationForPool(ctx, gona.CloudPoolDefault)
+		require.NoError(t, err)
+		_, err = c.GetServers(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{
+		"GetOSs()", "GetLocationForPool(Default)", "GetServers()",
+		"GetServers()",
+		"GetServers()",
+	}, fake.GetCalls(), "only metadata calls should be cached")
+
+	// Expired results are fetched again.
+	<-clk.After(time.Minute)
+	_, err := c.GetOSs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "GetOSs()", fake.GetCalls()[len(fake.GetCalls())-1])
+}
+
+func TestCacheMiddleware_Copies(t *testing.T) {
+	ctx := context.Background()
+	c := newFakeAPIClient(NewFakeClient())
+	c.Use(cacheMiddleware(time.Minute, newFakeClock()))
+
+	oss, err := c.GetOSs(ctx)
+	require.NoError(t, err)
+	want := oss[0]
+	oss[0] = gona.OS{}
+
+	oss, err = c.GetOSs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, want, oss[0], "changing a result must not change the cached one")
+}
+
+func TestCacheMiddleware_Errors(t *testing.T) {
+	ctx := context.Background()
+	boom := errors.New("boom")
+	calls := 0
+	cache := cacheMiddleware(time.Minute, newFakeClock())
+	next := func(context.Context) (any, error) {
+		calls++
+		if calls == 1 {
+			return nil, boom
+		}
+		return []gona.Plan{{Name: "VR1x1x25"}}, nil
+	}
+
+	_, err := cache(ctx, Call{Method: "GetPlans"}, next)
+	assert.ErrorIs(t, err, boom)
+	res, err := cache(ctx, Call{Method: "GetPlans"}, next)
+	require.NoError(t, err)
+	assert.Equal(t, []gona.Plan{{Name: "VR1x1x25"}}, res)
+	assert.Equal(t, 2, calls, "errors should not be cached")
+}
+
+func TestCacheMiddleware_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	cache := cacheMiddleware(time.Minute, realClock{})
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	next := func(context.Context) (any, error) {
+		calls.Add(1)
+		<-release
+		return []gona.OS{{ID: 1000}}, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := cache(ctx, Call{Method: "GetOSs"}, next)
+			assert.NoError(t, err)
+			assert.Equal(t, []gona.OS{{ID: 1000}}, res)
+		}()
+	}
+	// Let the goroutines pile up on the call in flight.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load(), "concurrent calls should be made once")
+}
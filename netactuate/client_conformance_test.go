@@ -0,0 +1,92 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gonaMethodsNotWrapped lists the exported *gona.Client methods deliberately
+// left out of ClientInterface, with the reason why.
+var gonaMethodsNotWrapped = map[string]string{}
+
+var clientInterfaceType = reflect.TypeFor[ClientInterface]()
+
+// TestClientInterfaceCoversGona fails when gona gains API methods that are
+// neither part of ClientInterface nor listed in gonaMethodsNotWrapped, so
+// the decorators and FakeClient are extended when gona is upgraded.
+func TestClientInterfaceCoversGona(t *testing.T) {
+	gonaType := reflect.TypeFor[*gona.Client]()
+
+	var missing []string
+	for i := range gonaType.NumMethod() {
+		method := gonaType.Method(i)
+		if _, ok := clientInterfaceType.MethodByName(method.Name); ok {
+			continue
+		}
+		if _, ok := gonaMethodsNotWrapped[method.Name]; ok {
+			continue
+		}
+		// Drop the receiver from the signature.
+		signature := strings.Replace(method.Type.String(), "func(*gona.Client, ", "func(", 1)
+		missing = append(missing, method.Name+" "+signature)
+	}
+
+	if len(missing) > 0 {
+		t.Errorf("gona.Client methods not covered by ClientInterface:\n\t%s\n\n"+
+			"Add them to ClientInterface, middlewareClient and FakeClient, "+
+			"or list them in gonaMethodsNotWrapped with the reason why.",
+			strings.Join(missing, "\n\t"))
+	}
+
+	for name := range gonaMethodsNotWrapped {
+		_, ok := gonaType.MethodByName(name)
+		assert.True(t, ok, "gonaMethodsNotWrapped lists %s, which gona.Client no longer has", name)
+	}
+}
+
+// TestMiddlewareClientCallNames checks that every method of middlewareClient
+// reports itself under its own name, which middleware such as the retry
+// layer relies on.
+func TestMiddlewareClientCallNames(t *testing.T) {
+	errStop := errors.New("stop")
+
+	var calls []Call
+	c := &middlewareClient{
+		next: NewFakeClient(),
+		middleware: []Middleware{func(_ context.Context, call Call, _ Invoker) (any, error) {
+			calls = append(calls, call)
+			return nil, errStop
+		}},
+	}
+	client := reflect.ValueOf(c)
+
+	for i := range clientInterfaceType.NumMethod() {
+		method := clientInterfaceType.Method(i)
+		t.Run(method.Name, func(t *testing.T) {
+			calls = nil
+
+			args := []reflect.Value{reflect.ValueOf(context.Background())}
+			for i := 1; i < method.Type.NumIn(); i++ {
+				args = append(args, reflect.Zero(method.Type.In(i)))
+			}
+			out := client.MethodByName(method.Name).Call(args)
+
+			require.Len(t, calls, 1)
+			assert.Equal(t, method.Name, calls[0].Method)
+			assert.Len(t, calls[0].Args, method.Type.NumIn()-1, "every argument but the context should be recorded")
+			assert.Same(t, errStop, out[len(out)-1].Interface(), "the middleware result should be returned")
+		})
+	}
+
+	for name := range unsafeRetryCalls {
+		_, ok := clientInterfaceType.MethodByName(name)
+		assert.True(t, ok, "unsafeRetryCalls lists %s, which is not a ClientInterface method", name)
+	}
+}
@@ -0,0 +1,101 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/netactuate/gona/gona"
+)
+
+// redacted replaces secrets in logged requests.
+const redacted = "REDACTED"
+
+// LoggingMiddleware logs every attempt of an API call with tflog at debug
+// level: the method, its arguments, how long it took and its result or
+// error. Passwords, scripts and the API key are redacted. The level is
+// controlled with TF_LOG_PROVIDER_NETACTUATE, e.g. TF_LOG_PROVIDER_NETACTUATE=debug.
+func LoggingMiddleware() Middleware {
+	return loggingMiddleware(realClock{})
+}
+
+func loggingMiddleware(clk clock) Middleware {
+	return func(ctx context.Context, call Call, next Invoker) (any, error) {
+		start := clk.Now()
+		res, err := next(ctx)
+
+		fields := map[string]any{
+			"method":      call.Method,
+			"args":        redactAPIKey(fmt.Sprintf("%+v", sanitizeArgs(call.Args))),
+			"duration_ms": clk.Now().Sub(start).Milliseconds(),
+		}
+		if err != nil {
+			fields["error"] = redactAPIKey(err.Error())
+			tflog.Debug(ctx, "NetActuate API call failed", fields)
+		} else {
+			fields["result"] = redactAPIKey(fmt.Sprintf("%+v", dereference(res)))
+			tflog.Debug(ctx, "NetActuate API call", fields)
+		}
+
+		return res, err
+	}
+}
+
+// sanitizeArgs returns the arguments of a call with secrets redacted. The
+// requests creating servers carry the root password and the cloud-init and
+// user data scripts, which commonly embed credentials.
+func sanitizeArgs(args []any) []any {
+	sanitized := make([]any, len(args))
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case *gona.CreateServerRequest:
+			r := *arg
+			r.Password = redactNonEmpty(r.Password)
+			r.CloudConfig = redactNonEmpty(r.CloudConfig)
+			r.ScriptContent = redactNonEmpty(r.ScriptContent)
+			sanitized[i] = r
+		case *gona.BuildServerRequest:
+			r := *arg
+			r.Password = redactNonEmpty(r.Password)
+			r.CloudConfig = redactNonEmpty(r.CloudConfig)
+			r.ScriptContent = redactNonEmpty(r.ScriptContent)
+			sanitized[i] = r
+		default:
+			sanitized[i] = arg
+		}
+	}
+	return sanitized
+}
+
+func redactNonEmpty(s string) string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// dereference returns what the pointer results of the API calls, such as
+// *gona.BGPSession, point to, so logs show their fields instead of an
+// address. Session passwords are redacted, like they are kept out of state.
+func dereference(res any) any {
+	switch res := res.(type) {
+	case *gona.BGPSession:
+		if res != nil {
+			return redactBGPSession(*res)
+		}
+	case []*gona.BGPSession:
+		sessions := make([]gona.BGPSession, 0, len(res))
+		for _, s := range res {
+			if s != nil {
+				sessions = append(sessions, redactBGPSession(*s))
+			}
+		}
+		return sessions
+	}
+	return res
+}
+
+func redactBGPSession(s gona.BGPSession) gona.BGPSession {
+	s.Password = redactNonEmpty(anyToString(s.Password))
+	return s
+}
@@ -0,0 +1,77 @@
+package netactuate
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	var out bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &out)
+
+	clk := newFakeClock()
+	logging := loggingMiddleware(clk)
+
+	req := &gona.CreateServerRequest{FQDN: "web01.example.com", Password: "hunter2", ScriptContent: "c2VjcmV0"}
+	_, err := logging(ctx, Call{Method: "CreateServer", Args: []any{req}}, func(context.Context) (any, error) {
+		<-clk.After(250 * time.Millisecond)
+		return gona.ServerBuild{ServerID: 101}, nil
+	})
+	require.NoError(t, err)
+
+	_, err = logging(ctx, Call{Method: "GetServer", Args: []any{101}}, func(context.Context) (any, error) {
+		return nil, gonaError(404, 404)
+	})
+	require.Error(t, err)
+
+	entries, err := tflogtest.MultilineJSONDecode(&out)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	created := entries[0]
+	assert.Equal(t, "NetActuate API call", created["@message"])
+	assert.Equal(t, "CreateServer", created["method"])
+	assert.Equal(t, float64(250), created["duration_ms"])
+	assert.Contains(t, created["args"], "web01.example.com")
+	assert.NotContains(t, created["args"], "hunter2")
+	assert.NotContains(t, created["args"], "c2VjcmV0")
+	assert.Contains(t, created["result"], "ServerID:101")
+	assert.Equal(t, "hunter2", req.Password, "the request itself must not be changed")
+
+	failed := entries[1]
+	assert.Equal(t, "NetActuate API call failed", failed["@message"])
+	assert.NotContains(t, failed["error"], "s3cr3t")
+	assert.Contains(t, failed["error"], "key=REDACTED")
+}
+
+func TestLoggingMiddleware_BGPSessionPasswords(t *testing.T) {
+	var out bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &out)
+	logging := loggingMiddleware(newFakeClock())
+
+	session := &gona.BGPSession{ID: 7, GroupID: 12, Password: "s3ss10n-s3cr3t"}
+	for _, res := range []any{session, []*gona.BGPSession{session, nil}} {
+		_, err := logging(ctx, Call{Method: "GetBGPSessions", Args: []any{101}}, func(context.Context) (any, error) {
+			return res, nil
+		})
+		require.NoError(t, err)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&out)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.Contains(t, entry["result"], "GroupID:12")
+		assert.Contains(t, entry["result"], "Password:REDACTED")
+		assert.NotContains(t, entry["result"], "s3ss10n-s3cr3t")
+	}
+	assert.NotContains(t, out.String(), "s3ss10n-s3cr3t")
+	assert.Equal(t, "s3ss10n-s3cr3t", session.Password, "the result itself must not be changed")
+}
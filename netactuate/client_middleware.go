@@ -0,0 +1,211 @@
+package netactuate
+
+import (
+	"context"
+
+	"github.com/netactuate/gona/gona"
+)
+
+// Call describes a single NetActuate API call made through the client.
+type Call struct {
+	Method string
+	Args   []any
+}
+
+// Invoker performs the API call described by a Call and returns its result.
+type Invoker func(ctx context.Context) (any, error)
+
+// Middleware intercepts API calls made through the client, e.g. for audit
+// logging. It must call next to perform the call and normally returns what
+// next returned. Results replaced by a middleware must keep their type.
+type Middleware func(ctx context.Context, call Call, next Invoker) (any, error)
+
+// middlewareClient is a ClientInterface decorator running every call through
+// a chain of middleware.
+type middlewareClient struct {
+	next       ClientInterface
+	middleware []Middleware
+}
+
+var _ ClientInterface = (*middlewareClient)(nil)
+
+func (c *middlewareClient) invoke(ctx context.Context, call Call, invoke Invoker) (any, error) {
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		mw, next := c.middleware[i], invoke
+		invoke = func(ctx context.Context) (any, error) {
+			return mw(ctx, call, next)
+		}
+	}
+	return invoke(ctx)
+}
+
+func (c *middlewareClient) GetServers(ctx context.Context) ([]gona.Server, error) {
+	res, err := c.invoke(ctx, Call{Method: "GetServers", Args: nil}, func(ctx context.Context) (any, error) {
+		return c.next.GetServers(ctx)
+	})
+	result, _ := res.([]gona.Server)
+	return result, err
+}
+
+func (c *middlewareClient) GetServer(ctx context.Context, id int) (gona.Server, error) {
+	res, err := c.invoke(ctx, Call{Method: "GetServer", Args: []any{id}}, func(ctx context.Context) (any, error) {
+		return c.next.GetServer(ctx, id)
+	})
+	result, _ := res.(gona.Server)
+	return result, err
+}
+
+func (c *middlewareClient) CreateServer(ctx context.Context, r *gona.CreateServerRequest) (gona.ServerBuild, error) {
+	res, err := c.invoke(ctx, Call{Method: "CreateServer", Args: []any{r}}, func(ctx context.Context) (any, error) {
+		return c.next.CreateServer(ctx, r)
+	})
+	result, _ := res.(gona.ServerBuild)
+	return result, err
+}
+
+func (c *middlewareClient) BuildServer(ctx context.Context, id int, r *gona.BuildServerRequest) (gona.ServerBuild, error) {
+	res, err := c.invoke(ctx, Call{Method: "BuildServer", Args: []any{id, r}}, func(ctx context.Context) (any, error) {
+		return c.next.BuildServer(ctx, id, r)
+	})
+	result, _ := res.(gona.ServerBuild)
+	return result, err
+}
+
+func (c *middlewareClient) DeleteServer(ctx context.Context, id int, cancelBilling bool) error {
+	_, err := c.invoke(ctx, Call{Method: "DeleteServer", Args: []any{id, cancelBilling}}, func(ctx context.Context) (any, error) {
+		return nil, c.next.DeleteServer(ctx, id, cancelBilling)
+	})
+	return err
+}
+
+func (c *middlewareClient) UnlinkServer(ctx context.Context, id int) error {
+	_, err := c.invoke(ctx, Call{Method: "UnlinkServer", Args: []any{id}}, func(ctx context.Context) (any, error) {
+		return nil, c.next.UnlinkServer(ctx, id)
+	})
+	return err
+}
+
+func (c *middlewareClient) StartServer(ctx context.Context, id int) error {
+	_, err := c.invoke(ctx, Call{Method: "StartServer", Args: []any{id}}, func(ctx context.Context) (any, error) {
+		return nil, c.next.StartServer(ctx, id)
+	})
+	return err
+}
+
+func (c *middlewareClient) StopServer(ctx context.Context, id int) error {
+	_, err := c.invoke(ctx, Call{Method: "StopServer", Args: []any{id}}, func(ctx context.Context) (any, error) {
+		return nil, c.next.StopServer(ctx, id)
+	})
+	return err
+}
+
+func (c *middlewareClient) GetIPs(ctx context.Context, mbPkgID int) (gona.IPs, error) {
+	res, err := c.invoke(ctx, Call{Method: "GetIPs", Args: []any{mbPkgID}}, func(ctx context.Context) (any, error) {
+		return c.next.GetIPs(ctx, mbPkgID)
+	})
+	result, _ := res.(gona.IPs)
+	return result, err
+}
+
+func (c *middlewareClient) GetLocations(ctx context.Context) ([]gona.Location, error) {
+	res, err := c.invoke(ctx, Call{Method: "GetLocations", Args: nil}, func(ctx context.Context) (any, error) {
+		return c.next.GetLocations(ctx)
+	})
+	result, _ := res.([]gona.Location)
+	return result, err
+}
+
+func (c *middlewareClient) GetLocationForPool(ctx context.Context, pool gona.CloudPool) ([]gona.Location, error) {
+	res, err := c.invoke(ctx, Call{Method: "GetLocationForPool", Args: []any{pool}}, func(ctx context.Context) (any, error) {
+		return c.next.GetLocationForPool(ctx, pool)
+	})
+	result, _ := res.([]gona.Location)
+	return result, err
+}
+
+func (c *middlewareClient) GetOSs(ctx context.Context) ([]gona.OS, error) {
+	res, err := c.invoke(ctx, Call{Method: "GetOSs", Args: nil}, func(ctx context.Context) (any, error) {
+		return c.next.GetOSs(ctx)
+	})
+	result, _ := res.([]gona.OS)
+	return result, err
+}
+
+func (c *middlewareClient) GetPlans(ctx context.Context) ([]gona.Plan, error) {
+	res, err := c.invoke(ctx, Call{Method: "GetPlans", Args: nil}, func(ctx context.Context) (any, error) {
+		return c.next.GetPlans(ctx)
+	})
+	result, _ := res.([]gona.Plan)
+	return result, err
+}
+
+func (c *middlewareClient) GetPackages(ctx context.Context) ([]gona.Package, error) {
+	res, err := c.invoke(ctx, Call{Method: "GetPackages", Args: nil}, func(ctx context.Context) (any, error) {
+		return c.next.GetPackages(ctx)
+	})
+	result, _ := res.([]gona.Package)
+	return result, err
+}
+
+func (c *middlewareClient) GetPackage(ctx context.Context, id int) (gona.Package, error) {
+	res, err := c.invoke(ctx, Call{Method: "GetPackage", Args: []any{id}}, func(ctx context.Context) (any, error) {
+		return c.next.GetPackage(ctx, id)
+	})
+	result, _ := res.(gona.Package)
+	return result, err
+}
+
+func (c *middlewareClient) GetSSHKeys(ctx context.Context) ([]gona.SSHKey, error) {
+	res, err := c.invoke(ctx, Call{Method: "GetSSHKeys", Args: nil}, func(ctx context.Context) (any, error) {
+		return c.next.GetSSHKeys(ctx)
+	})
+	result, _ := res.([]gona.SSHKey)
+	return result, err
+}
+
+func (c *middlewareClient) GetSSHKey(ctx context.Context, id int) (gona.SSHKey, error) {
+	res, err := c.invoke(ctx, Call{Method: "GetSSHKey", Args: []any{id}}, func(ctx context.Context) (any, error) {
+		return c.next.GetSSHKey(ctx, id)
+	})
+	result, _ := res.(gona.SSHKey)
+	return result, err
+}
+
+func (c *middlewareClient) CreateSSHKey(ctx context.Context, name, key string) (gona.SSHKey, error) {
+	res, err := c.invoke(ctx, Call{Method: "CreateSSHKey", Args: []any{name, key}}, func(ctx context.Context) (any, error) {
+		return c.next.CreateSSHKey(ctx, name, key)
+	})
+	result, _ := res.(gona.SSHKey)
+	return result, err
+}
+
+func (c *middlewareClient) DeleteSSHKey(ctx context.Context, id int) error {
+	_, err := c.invoke(ctx, Call{Method: "DeleteSSHKey", Args: []any{id}}, func(ctx context.Context) (any, error) {
+		return nil, c.next.DeleteSSHKey(ctx, id)
+	})
+	return err
+}
+
+func (c *middlewareClient) GetBGPSession(ctx context.Context, id int) (*gona.BGPSession, error) {
+	res, err := c.invoke(ctx, Call{Method: "GetBGPSession", Args: []any{id}}, func(ctx context.Context) (any, error) {
+		return c.next.GetBGPSession(ctx, id)
+	})
+	result, _ := res.(*gona.BGPSession)
+	return result, err
+}
+
+func (c *middlewareClient) GetBGPSessions(ctx context.Context, mbPkgID int) ([]*gona.BGPSession, error) {
+	res, err := c.invoke(ctx, Call{Method: "GetBGPSessions", Args: []any{mbPkgID}}, func(ctx context.Context) (any, error) {
+		return c.next.GetBGPSessions(ctx, mbPkgID)
+	})
+	result, _ := res.([]*gona.BGPSession)
+	return result, err
+}
+
+func (c *middlewareClient) CreateBGPSessions(ctx context.Context, mbPkgID int, groupID int, isIPV6 bool, redundant bool) (*gona.BGPSession, error) {
+	res, err := c.invoke(ctx, Call{Method: "CreateBGPSessions", Args: []any{mbPkgID, groupID, isIPV6, redundant}}, func(ctx context.Context) (any, error) {
+		return c.next.CreateBGPSessions(ctx, mbPkgID, groupID, isIPV6, redundant)
+	})
+	result, _ := res.(*gona.BGPSession)
+	return result, err
+}
@@ -0,0 +1,89 @@
+package netactuate
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	DefaultMaxRetries   = 4
+	DefaultRetryWaitMax = 30 * time.Second
+
+	// retryWaitMin is the backoff before the first retry, it doubles with
+	// every further attempt up to the configured maximum.
+	retryWaitMin = time.Second
+)
+
+// unsafeRetryCalls are the calls that create something. When they fail with
+// a server or network error the request may still have been carried out, so
+// they are only retried when the API rejected them for the rate limit.
+var unsafeRetryCalls = map[string]bool{
+	"CreateServer":      true,
+	"BuildServer":       true,
+	"CreateSSHKey":      true,
+	"CreateBGPSessions": true,
+}
+
+// RetryMiddleware retries API calls failing with a rate limit, server or
+// network error up to maxRetries times, with exponential backoff and jitter
+// capped at waitMax. Calls creating objects are only retried after a rate
+// limit.
+func RetryMiddleware(maxRetries int, waitMax time.Duration) Middleware {
+	return retryMiddleware(maxRetries, waitMax, realClock{}, rand.Int64N)
+}
+
+func retryMiddleware(maxRetries int, waitMax time.Duration, clk clock, randN func(n int64) int64) Middleware {
+	return func(ctx context.Context, call Call, next Invoker) (any, error) {
+		for attempt := 0; ; attempt++ {
+			res, err := next(ctx)
+			if err == nil || attempt >= maxRetries || !shouldRetry(call, err) {
+				return res, err
+			}
+
+			select {
+			case <-ctx.Done():
+				return res, err
+			case <-clk.After(retryBackoff(attempt, waitMax, randN)):
+			}
+		}
+	}
+}
+
+func shouldRetry(call Call, err error) bool {
+	switch errorKind(err) {
+	case ErrorKindRateLimited:
+		return true
+	case ErrorKindTransient:
+		return !unsafeRetryCalls[call.Method]
+	default:
+		return false
+	}
+}
+
+// retryBackoff returns how long to wait after the given failed attempt:
+// between half and all of retryWaitMin doubled for every attempt, capped at
+// waitMax.
+func retryBackoff(attempt int, waitMax time.Duration, randN func(n int64) int64) time.Duration {
+	backoff := waitMax
+	if attempt < 32 && retryWaitMin<<attempt < waitMax {
+		backoff = retryWaitMin << attempt
+	}
+
+	half := backoff / 2
+	if half <= 0 {
+		return backoff
+	}
+	return half + time.Duration(randN(int64(half)+1))
+}
+
+// newRetryMiddleware validates the retry provider settings.
+func newRetryMiddleware(maxRetries int, waitMaxSeconds int) (Middleware, error) {
+	if maxRetries < 0 {
+		return nil, codedErrorf(CodeClientSetupFailed, "max_retries must not be negative, got %d", maxRetries)
+	}
+	if waitMaxSeconds < 1 {
+		return nil, codedErrorf(CodeClientSetupFailed, "retry_wait_max must be at least 1 second, got %d", waitMaxSeconds)
+	}
+	return RetryMiddleware(maxRetries, time.Duration(waitMaxSeconds)*time.Second), nil
+}
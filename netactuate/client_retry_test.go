@@ -0,0 +1,129 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// maxJitter makes retryBackoff wait as long as possible.
+func maxJitter(n int64) int64 {
+	return n - 1
+}
+
+func TestRetryBackoff(t *testing.T) {
+	noJitter := func(int64) int64 { return 0 }
+
+	assert.Equal(t, 500*time.Millisecond, retryBackoff(0, 30*time.Second, noJitter))
+	assert.Equal(t, 4*time.Second, retryBackoff(3, 30*time.Second, noJitter))
+	assert.Equal(t, 15*time.Second, retryBackoff(10, 30*time.Second, noJitter))
+	assert.Equal(t, 15*time.Second, retryBackoff(100, 30*time.Second, noJitter))
+
+	assert.Equal(t, 8*time.Second, retryBackoff(3, 30*time.Second, maxJitter))
+	assert.Equal(t, 30*time.Second, retryBackoff(10, 30*time.Second, maxJitter))
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	tests := []struct {
+		desc      string
+		method    string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{desc: "success", method: "GetServer", errs: []error{nil}, wantCalls: 1},
+		{desc: "transient then success", method: "GetServer", errs: []error{gonaError(503, 503), nil}, wantCalls: 2},
+		{desc: "rate limited", method: "GetServer", errs: []error{gonaError(429, 429), gonaError(429, 429), nil}, wantCalls: 3},
+		{desc: "gives up", method: "GetServer", errs: []error{gonaError(502, 502)}, wantCalls: 3, wantErr: true},
+		{desc: "not found", method: "GetServer", errs: []error{gonaError(404, 404)}, wantCalls: 1, wantErr: true},
+		{desc: "other error", method: "GetServer", errs: []error{errors.New("boom")}, wantCalls: 1, wantErr: true},
+		{desc: "create after server error", method: "CreateServer", errs: []error{gonaError(500, 500)}, wantCalls: 1, wantErr: true},
+		{desc: "create after rate limit", method: "CreateServer", errs: []error{gonaError(429, 429), nil}, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			clk := newFakeClock()
+			start := clk.Now()
+			retry := retryMiddleware(2, 30*time.Second, clk, maxJitter)
+
+			calls := 0
+			_, err := retry(context.Background(), Call{Method: tt.method}, func(context.Context) (any, error) {
+				err := tt.errs[min(calls, len(tt.errs)-1)]
+				calls++
+				return nil, err
+			})
+
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.wantCalls == 3 && tt.wantErr {
+				assert.Equal(t, 3*time.Second, clk.Now().Sub(start), "should back off 1s, then 2s")
+			}
+		})
+	}
+}
+
+func TestRetryMiddleware_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	retry := RetryMiddleware(5, time.Hour)
+	_, err := retry(ctx, Call{Method: "GetServer"}, func(context.Context) (any, error) {
+		calls++
+		return nil, gonaError(503, 503)
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestNewRetryMiddleware(t *testing.T) {
+	_, err := newRetryMiddleware(-1, 30)
+	assert.ErrorContains(t, err, "max_retries must not be negative")
+
+	_, err = newRetryMiddleware(4, 0)
+	assert.ErrorContains(t, err, "retry_wait_max must be at least 1 second")
+
+	_, err = newRetryMiddleware(0, 1)
+	assert.NoError(t, err)
+}
+
+func TestProviderRetries(t *testing.T) {
+	ctx := context.Background()
+
+	requests := 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"result":"failure","code":503,"message":"maintenance"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":"success","code":200,"data":[]}`))
+	}))
+	t.Cleanup(api.Close)
+
+	p := NewSDKProvider("test")
+	diags := p.Configure(ctx, terraform.NewResourceConfigRaw(map[string]any{
+		"api_key":        "test-api-key",
+		"api_url":        api.URL + "/api/",
+		"retry_wait_max": 1,
+	}))
+	require.False(t, diags.HasError(), "%v", diags)
+
+	_, err := p.Meta().(*Client).GetServers(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+}
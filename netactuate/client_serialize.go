@@ -0,0 +1,73 @@
+package netactuate
+
+import (
+	"context"
+	"sync"
+)
+
+// serializedCalls are the calls racing in the API when they are made
+// concurrently for the same server, with the position of the server ID in
+// their arguments.
+var serializedCalls = map[string]int{
+	"CreateBGPSessions": 0,
+}
+
+// serverLocks are shared by the API clients of the SDK and Framework
+// providers, which both create BGP sessions, e.g. for netactuate_bgp_sessions
+// and netactuate_anycast_node resources of the same server.
+var serverLocks = &keyedLocks{}
+
+// SerializeMiddleware makes the calls racing in the API, like the creation
+// of BGP sessions, one at a time per server, so resources applied in
+// parallel for the same server don't fail or create duplicates. Calls for
+// different servers still run concurrently.
+func SerializeMiddleware() Middleware {
+	return serializeMiddleware(serverLocks)
+}
+
+func serializeMiddleware(locks *keyedLocks) Middleware {
+	return func(ctx context.Context, call Call, next Invoker) (any, error) {
+		i, ok := serializedCalls[call.Method]
+		if !ok || i >= len(call.Args) {
+			return next(ctx)
+		}
+		id, ok := call.Args[i].(int)
+		if !ok {
+			return next(ctx)
+		}
+
+		unlock, err := locks.lock(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+		return next(ctx)
+	}
+}
+
+// keyedLocks is a set of mutexes by ID. Waiting for one can be cancelled
+// with the context.
+type keyedLocks struct {
+	mu    sync.Mutex
+	locks map[int]chan struct{}
+}
+
+func (l *keyedLocks) lock(ctx context.Context, id int) (func(), error) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[int]chan struct{})
+	}
+	ch, ok := l.locks[id]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		l.locks[id] = ch
+	}
+	l.mu.Unlock()
+
+	select {
+	case ch <- struct{}{}:
+		return func() { <-ch }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
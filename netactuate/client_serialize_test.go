@@ -0,0 +1,101 @@
+package netactuate
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializeMiddleware(t *testing.T) {
+	ctx := context.Background()
+	serialize := serializeMiddleware(&keyedLocks{})
+
+	var running, maxRunning atomic.Int32
+	next := func(context.Context) (any, error) {
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		return nil, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := serialize(ctx, Call{Method: "CreateBGPSessions", Args: []any{1234, 4242, true, false}}, next)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), maxRunning.Load(), "BGP sessions of a server should be created one at a time")
+}
+
+func TestSerializeMiddleware_OtherServers(t *testing.T) {
+	ctx := context.Background()
+	serialize := serializeMiddleware(&keyedLocks{})
+
+	// The first call holds the lock of server 1234 until released.
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_, _ = serialize(ctx, Call{Method: "CreateBGPSessions", Args: []any{1234, 4242, true, false}}, func(context.Context) (any, error) {
+			close(started)
+			<-release
+			return nil, nil
+		})
+	}()
+	<-started
+	defer close(release)
+
+	called := false
+	next := func(context.Context) (any, error) {
+		called = true
+		return nil, nil
+	}
+	_, err := serialize(ctx, Call{Method: "CreateBGPSessions", Args: []any{5678, 4242, true, false}}, next)
+	require.NoError(t, err)
+	assert.True(t, called, "calls for other servers should not wait")
+
+	called = false
+	_, err = serialize(ctx, Call{Method: "GetBGPSessions", Args: []any{1234}}, next)
+	require.NoError(t, err)
+	assert.True(t, called, "other calls should not wait")
+}
+
+func TestSerializeMiddleware_Cancel(t *testing.T) {
+	serialize := serializeMiddleware(&keyedLocks{})
+	call := Call{Method: "CreateBGPSessions", Args: []any{1234, 4242, true, false}}
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_, _ = serialize(context.Background(), call, func(context.Context) (any, error) {
+			close(started)
+			<-release
+			return nil, nil
+		})
+	}()
+	<-started
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := serialize(ctx, call, func(context.Context) (any, error) {
+		t.Error("the call should not be made after the context is cancelled")
+		return nil, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,133 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClient(t *testing.T) {
+	c, err := NewClient("test-api-key", "", DefaultAPIVersion)
+	assert.NoError(t, err)
+	assert.Equal(t, APIVersionV2, c.APIVersion())
+
+	for _, capability := range []Capability{CapabilityBGPSessions, CapabilityCloudPools, CapabilityServerUnlink} {
+		assert.True(t, c.Supports(capability), "v2 should support %s", capability)
+		assert.NoError(t, c.require(capability))
+	}
+	assert.False(t, c.Supports("next_gen_only"))
+	assert.EqualError(t, c.require("next_gen_only"), "[NA1103] next_gen_only is not available with NetActuate API v2")
+}
+
+func TestNewClient_UnsupportedVersion(t *testing.T) {
+	c, err := NewClient("test-api-key", "", "v9")
+	assert.Nil(t, c)
+	assert.EqualError(t, err, `[NA1102] unsupported api_version "v9", expected one of: v2`)
+}
+
+// newTestAPI serves canned NetActuate API response data keyed by request
+// path, relative to the /api/ endpoint.
+func newTestAPI(t *testing.T, data map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, ok := data[strings.TrimPrefix(r.URL.Path, "/api/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"result": "error", "code": 404, "message": "not found"}`)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"result": "success", "code": 200, "data": %s}`, d)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_Use(t *testing.T) {
+	ctx := context.Background()
+	api := newTestAPI(t, map[string]string{
+		"cloud/server": `{"mbpkgid": 42, "fqdn": "web01.example.com", "status": "RUNNING"}`,
+	})
+
+	c, err := NewClient("test-api-key", api.URL+"/api/", DefaultAPIVersion)
+	assert.NoError(t, err)
+
+	var events []string
+	record := func(name string) Middleware {
+		return func(ctx context.Context, call Call, next Invoker) (any, error) {
+			events = append(events, fmt.Sprintf("%s before %s%v", name, call.Method, call.Args))
+			result, err := next(ctx)
+			events = append(events, fmt.Sprintf("%s after %s err=%v", name, call.Method, err != nil))
+			return result, err
+		}
+	}
+	c.Use(record("outer"), record("inner"))
+
+	server, err := c.GetServer(ctx, 42)
+	assert.NoError(t, err)
+	assert.Equal(t, "web01.example.com", server.Name)
+
+	_, err = c.GetSSHKey(ctx, 7)
+	assert.True(t, IsNotFound(err))
+
+	assert.Equal(t, []string{
+		"outer before GetServer[42]",
+		"inner before GetServer[42]",
+		"inner after GetServer err=false",
+		"outer after GetServer err=false",
+		"outer before GetSSHKey[7]",
+		"inner before GetSSHKey[7]",
+		"inner after GetSSHKey err=true",
+		"outer after GetSSHKey err=true",
+	}, events)
+}
+
+func TestClient_UseReplacesResult(t *testing.T) {
+	api := newTestAPI(t, map[string]string{
+		"cloud/server": `{"mbpkgid": 42, "status": "BUILDING"}`,
+	})
+
+	c, err := NewClient("test-api-key", api.URL+"/api/", DefaultAPIVersion)
+	assert.NoError(t, err)
+
+	c.Use(func(ctx context.Context, call Call, next Invoker) (any, error) {
+		result, err := next(ctx)
+		if server, ok := result.(gona.Server); ok {
+			server.ServerStatus = "RUNNING"
+			return server, err
+		}
+		return result, err
+	})
+
+	server, err := c.GetServer(context.Background(), 42)
+	assert.NoError(t, err)
+	assert.Equal(t, "RUNNING", server.ServerStatus)
+}
+
+func TestWithMiddleware(t *testing.T) {
+	ctx := context.Background()
+	api := newTestAPI(t, map[string]string{
+		"cloud/servers": `[]`,
+	})
+
+	var calls []Call
+	p := NewSDKProvider("test", WithMiddleware(func(ctx context.Context, call Call, next Invoker) (any, error) {
+		calls = append(calls, call)
+		return next(ctx)
+	}))
+
+	diags := p.Configure(ctx, terraform.NewResourceConfigRaw(map[string]any{
+		"api_key": "test-api-key",
+		"api_url": api.URL + "/api/",
+	}))
+	assert.False(t, diags.HasError())
+
+	_, err := p.Meta().(*Client).GetServers(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Call{{Method: "GetServers"}}, calls)
+}
@@ -0,0 +1,39 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutMiddleware bounds every attempt of an API call to timeout. An
+// attempt running out of time fails as a transient error, so it is retried
+// like a network error when the retry middleware runs around it.
+func TimeoutMiddleware(timeout time.Duration) Middleware {
+	return func(ctx context.Context, call Call, next Invoker) (any, error) {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		res, err := next(attemptCtx)
+		if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+			return res, &APIError{
+				Kind: ErrorKindTransient,
+				Err:  fmt.Errorf("%s timed out after %s: %w", call.Method, timeout, err),
+			}
+		}
+		return res, err
+	}
+}
+
+// newTimeoutMiddleware validates the request_timeout provider setting. It
+// returns no middleware for 0, which disables the timeout.
+func newTimeoutMiddleware(timeoutSeconds int) ([]Middleware, error) {
+	if timeoutSeconds < 0 {
+		return nil, codedErrorf(CodeClientSetupFailed, "request_timeout must not be negative, got %d", timeoutSeconds)
+	}
+	if timeoutSeconds == 0 {
+		return nil, nil
+	}
+	return []Middleware{TimeoutMiddleware(time.Duration(timeoutSeconds) * time.Second)}, nil
+}
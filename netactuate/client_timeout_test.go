@@ -0,0 +1,84 @@
+package netactuate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	timeout := TimeoutMiddleware(10 * time.Millisecond)
+
+	_, err := timeout(context.Background(), Call{Method: "GetServer"}, func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	require.Error(t, err)
+	assert.True(t, IsRetryable(err), "a timed out attempt should be retried")
+	assert.ErrorContains(t, err, "GetServer timed out after 10ms")
+
+	res, err := timeout(context.Background(), Call{Method: "GetServer"}, func(context.Context) (any, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", res)
+}
+
+func TestTimeoutMiddleware_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := TimeoutMiddleware(time.Hour)(ctx, Call{Method: "GetServer"}, func(ctx context.Context) (any, error) {
+		return nil, ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, IsRetryable(err))
+}
+
+func TestNewTimeoutMiddleware(t *testing.T) {
+	_, err := newTimeoutMiddleware(-1)
+	assert.ErrorContains(t, err, "request_timeout must not be negative")
+
+	middleware, err := newTimeoutMiddleware(0)
+	assert.NoError(t, err)
+	assert.Empty(t, middleware)
+
+	middleware, err = newTimeoutMiddleware(30)
+	assert.NoError(t, err)
+	assert.Len(t, middleware, 1)
+}
+
+func TestProviderRequestTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	requests := 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			// Hang until the client gives up on the first attempt.
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":"success","code":200,"data":[]}`))
+	}))
+	t.Cleanup(api.Close)
+
+	p := NewSDKProvider("test")
+	diags := p.Configure(ctx, terraform.NewResourceConfigRaw(map[string]any{
+		"api_key":         "test-api-key",
+		"api_url":         api.URL + "/api/",
+		"retry_wait_max":  1,
+		"request_timeout": 1,
+	}))
+	require.False(t, diags.HasError(), "%v", diags)
+
+	_, err := p.Meta().(*Client).GetServers(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+}
@@ -0,0 +1,177 @@
+package netactuate
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/netactuate/gona/gona"
+)
+
+// FlattenServer converts a gona.Server into the attribute map of the
+// netactuate_server data source.
+func FlattenServer(server gona.Server) map[string]any {
+	return map[string]any{
+		"hostname":    server.Name,
+		"package":     server.Package,
+		"plan_id":     server.PlanID,
+		"location_id": server.LocationID,
+		"image":       server.OS,
+		"image_id":    server.OSID,
+		"ip_v4":       server.PrimaryIPv4,
+		"ip_v6":       server.PrimaryIPv6,
+		"status":      server.ServerStatus,
+		"state":       server.PowerStatus,
+	}
+}
+
+// FlattenServerSummary converts a gona.Server into a list element of the
+// data sources enumerating several servers.
+func FlattenServerSummary(server gona.Server) map[string]any {
+	return map[string]any{
+		"id":           server.ID,
+		"hostname":     server.Name,
+		"location_id":  server.LocationID,
+		"primary_ipv4": server.PrimaryIPv4,
+		"primary_ipv6": server.PrimaryIPv6,
+		"status":       server.ServerStatus,
+		"state":        server.PowerStatus,
+	}
+}
+
+// FlattenLocation converts a gona.Location into a list element of the
+// netactuate_locations data source.
+func FlattenLocation(location gona.Location) map[string]any {
+	return map[string]any{
+		"id":        location.ID,
+		"name":      location.Name,
+		"iata_code": location.IATACode,
+		"continent": location.Continent,
+		"flag":      location.Flag,
+		"disabled":  location.Disabled == 1,
+	}
+}
+
+// FlattenOS converts a gona.OS into a list element of the netactuate_oses
+// data source.
+func FlattenOS(os gona.OS) map[string]any {
+	return map[string]any{
+		"id":      os.ID,
+		"name":    os.Os,
+		"type":    os.Type,
+		"subtype": os.Subtype,
+		"size":    os.Size,
+		"bits":    os.Bits,
+		"tech":    os.Tech,
+	}
+}
+
+// FlattenIPs converts the addresses assigned to a server into the public IP
+// attributes of the netactuate_server data source, using the address with
+// the lowest ID of each family. Families without any address are left out.
+func FlattenIPs(ips gona.IPs) map[string]any {
+	m := make(map[string]any)
+
+	if len(ips.IPv4) > 0 {
+		m["public_ipv4"] = slices.MinFunc(ips.IPv4, compareIPs).IP
+	}
+	if len(ips.IPv6) > 0 {
+		m["public_ipv6"] = slices.MinFunc(ips.IPv6, compareIPs).IP
+	}
+
+	return m
+}
+
+func compareIPs(a, b gona.IP) int {
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// FlattenBGPSessions converts BGP sessions into the elements of a sessions
+// list, ordered by session ID.
+func FlattenBGPSessions(sessions []*gona.BGPSession) []map[string]any {
+	result := make([]map[string]any, 0, len(sessions))
+	for _, session := range sortBGPSessions(sessions) {
+		result = append(result, FlattenBGPSession(session))
+	}
+	return result
+}
+
+// FlattenBGPSession converts a gona.BGPSession into a list element of the
+// netactuate_bgp_sessions data source.
+func FlattenBGPSession(session *gona.BGPSession) map[string]any {
+	return map[string]any{
+		"id": session.ID,
+		// "mb_id": session.MbID,
+		"description":      session.Description,
+		"routes_received":  anyToString(session.RoutesReceived),
+		"config_status":    fmt.Sprint(session.ConfigStatus),
+		"last_update":      anyToString(session.LastUpdate),
+		"locked":           session.IsLocked(),
+		"group_id":         session.GroupID,
+		"group_name":       session.GroupName,
+		"location_name":    session.Location,
+		"customer_peer_ip": session.CustomerIP,
+		"provider_peer_ip": session.ProviderPeerIP,
+		"provider_ip_type": session.ProviderIPType,
+		"customer_asn":     session.CustomerAsn,
+		"provider_asn":     session.ProviderAsn,
+		"state":            anyToString(session.State),
+	}
+}
+
+// FlattenBGPPeers summarizes the BGP sessions of a server into the bgp_peers
+// block of the netactuate_server data source. It returns nil when there are
+// no sessions.
+func FlattenBGPPeers(sessions []*gona.BGPSession) map[string]any {
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	var peerV4 []string
+	var peerV6 []string
+
+	bgpPeers := make(map[string]any)
+
+	sessions = sortBGPSessions(sessions)
+	session := sessions[0]
+
+	bgpPeers["group_id"] = session.GroupID
+	bgpPeers["localasn"] = session.CustomerAsn
+	bgpPeers["peerasn"] = session.ProviderAsn
+
+	for _, session := range sessions {
+		if session.IsProviderIPTypeV4() {
+			bgpPeers["localpeerv4"] = session.CustomerIP
+			peerV4 = append(peerV4, session.ProviderPeerIP)
+		} else {
+			bgpPeers["localpeerv6"] = session.CustomerIP
+			peerV6 = append(peerV6, session.ProviderPeerIP)
+		}
+	}
+
+	if len(peerV4) > 0 {
+		bgpPeers["ipv4"] = peerV4
+	}
+	if len(peerV6) > 0 {
+		bgpPeers["ipv6"] = peerV6
+	}
+
+	return bgpPeers
+}
+
+// sortBGPSessions returns a copy of sessions ordered by ID, so list outputs
+// don't churn with the order the API happens to return them in.
+func sortBGPSessions(sessions []*gona.BGPSession) []*gona.BGPSession {
+	return slices.SortedFunc(slices.Values(sessions), func(a, b *gona.BGPSession) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+}
+
+// anyToString converts the loosely typed JSON fields of gona models into a
+// string, mapping JSON null to an empty string.
+func anyToString(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,122 @@
+package netactuate
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/netactuate/gona/gona"
+)
+
+// ServerModel is the Plugin Framework representation of a gona.Server.
+type ServerModel struct {
+	ID          types.Int64  `tfsdk:"id"`
+	Hostname    types.String `tfsdk:"hostname"`
+	Package     types.String `tfsdk:"package"`
+	PlanID      types.Int64  `tfsdk:"plan_id"`
+	LocationID  types.Int64  `tfsdk:"location_id"`
+	Image       types.String `tfsdk:"image"`
+	ImageID     types.Int64  `tfsdk:"image_id"`
+	PrimaryIPv4 types.String `tfsdk:"primary_ipv4"`
+	PrimaryIPv6 types.String `tfsdk:"primary_ipv6"`
+	Status      types.String `tfsdk:"status"`
+	State       types.String `tfsdk:"state"`
+	CloudPool   types.String `tfsdk:"cloud_pool"`
+}
+
+// NewServerModel converts a gona.Server into a ServerModel.
+func NewServerModel(server gona.Server) ServerModel {
+	return ServerModel{
+		ID:          types.Int64Value(int64(server.ID)),
+		Hostname:    types.StringValue(server.Name),
+		Package:     types.StringValue(server.Package),
+		PlanID:      types.Int64Value(int64(server.PlanID)),
+		LocationID:  types.Int64Value(int64(server.LocationID)),
+		Image:       types.StringValue(server.OS),
+		ImageID:     types.Int64Value(int64(server.OSID)),
+		PrimaryIPv4: types.StringValue(server.PrimaryIPv4),
+		PrimaryIPv6: types.StringValue(server.PrimaryIPv6),
+		Status:      types.StringValue(server.ServerStatus),
+		State:       types.StringValue(server.PowerStatus),
+		CloudPool:   types.StringValue(cloudPoolName(server)),
+	}
+}
+
+// IPModel is the Plugin Framework representation of a gona.IP.
+type IPModel struct {
+	ID        types.Int64  `tfsdk:"id"`
+	IP        types.String `tfsdk:"ip"`
+	Primary   types.Bool   `tfsdk:"primary"`
+	Reverse   types.String `tfsdk:"reverse"`
+	Gateway   types.String `tfsdk:"gateway"`
+	Netmask   types.String `tfsdk:"netmask"`
+	Broadcast types.String `tfsdk:"broadcast"`
+}
+
+// NewIPModels converts a list of gona.IP into IPModels, preserving order.
+func NewIPModels(ips []gona.IP) []IPModel {
+	models := make([]IPModel, len(ips))
+
+	for i, ip := range ips {
+		models[i] = IPModel{
+			ID:        types.Int64Value(int64(ip.ID)),
+			IP:        types.StringValue(ip.IP),
+			Primary:   types.BoolValue(ip.Primary == 1),
+			Reverse:   types.StringValue(ip.Reverse),
+			Gateway:   types.StringValue(ip.Gateway),
+			Netmask:   types.StringValue(ip.Netmask),
+			Broadcast: types.StringValue(ip.Broadcast),
+		}
+	}
+
+	return models
+}
+
+// BGPSessionModel is the Plugin Framework representation of a
+// gona.BGPSession.
+type BGPSessionModel struct {
+	ID             types.Int64  `tfsdk:"id"`
+	Description    types.String `tfsdk:"description"`
+	RoutesReceived types.String `tfsdk:"routes_received"`
+	ConfigStatus   types.String `tfsdk:"config_status"`
+	LastUpdate     types.String `tfsdk:"last_update"`
+	Locked         types.Bool   `tfsdk:"locked"`
+	GroupID        types.Int64  `tfsdk:"group_id"`
+	GroupName      types.String `tfsdk:"group_name"`
+	LocationName   types.String `tfsdk:"location_name"`
+	CustomerPeerIP types.String `tfsdk:"customer_peer_ip"`
+	ProviderPeerIP types.String `tfsdk:"provider_peer_ip"`
+	ProviderIPType types.String `tfsdk:"provider_ip_type"`
+	CustomerASN    types.Int64  `tfsdk:"customer_asn"`
+	ProviderASN    types.Int64  `tfsdk:"provider_asn"`
+	State          types.String `tfsdk:"state"`
+}
+
+// NewBGPSessionModel converts a gona.BGPSession into a BGPSessionModel.
+func NewBGPSessionModel(session *gona.BGPSession) BGPSessionModel {
+	return BGPSessionModel{
+		ID:             types.Int64Value(int64(session.ID)),
+		Description:    types.StringValue(session.Description),
+		RoutesReceived: anyToStringValue(session.RoutesReceived),
+		ConfigStatus:   types.StringValue(fmt.Sprint(session.ConfigStatus)),
+		LastUpdate:     anyToStringValue(session.LastUpdate),
+		Locked:         types.BoolValue(session.IsLocked()),
+		GroupID:        types.Int64Value(int64(session.GroupID)),
+		GroupName:      types.StringValue(session.GroupName),
+		LocationName:   types.StringValue(session.Location),
+		CustomerPeerIP: types.StringValue(session.CustomerIP),
+		ProviderPeerIP: types.StringValue(session.ProviderPeerIP),
+		ProviderIPType: types.StringValue(session.ProviderIPType),
+		CustomerASN:    types.Int64Value(int64(session.CustomerAsn)),
+		ProviderASN:    types.Int64Value(int64(session.ProviderAsn)),
+		State:          anyToStringValue(session.State),
+	}
+}
+
+// anyToStringValue converts the loosely typed JSON fields of gona models into
+// a string value, mapping JSON null to a Terraform null.
+func anyToStringValue(v any) types.String {
+	if v == nil {
+		return types.StringNull()
+	}
+	return types.StringValue(fmt.Sprint(v))
+}
@@ -0,0 +1,185 @@
+package netactuate
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+)
+
+func testServer() gona.Server {
+	return gona.Server{
+		Name:         "web01.example.com",
+		ID:           1234,
+		OS:           "Ubuntu 22.04 LTS x64",
+		OSID:         5787,
+		PrimaryIPv4:  "192.0.2.10",
+		PrimaryIPv6:  "2001:db8::10",
+		PlanID:       4,
+		Package:      "VR1x1x25",
+		Location:     "AMS - Amsterdam, NL",
+		LocationID:   11,
+		ServerStatus: "RUNNING",
+		PowerStatus:  "online",
+		Installed:    1,
+	}
+}
+
+func testIPs() gona.IPs {
+	return gona.IPs{
+		IPv4: []gona.IP{
+			{ID: 1, Primary: 1, IP: "192.0.2.10", Gateway: "192.0.2.1", Netmask: "255.255.255.0", Broadcast: "192.0.2.255", Reverse: "web01.example.com"},
+			{ID: 2, IP: "192.0.2.11", Gateway: "192.0.2.1", Netmask: "255.255.255.0", Broadcast: "192.0.2.255"},
+		},
+		IPv6: []gona.IP{
+			{ID: 3, Primary: 1, IP: "2001:db8::10", Gateway: "2001:db8::1", Netmask: "64"},
+		},
+	}
+}
+
+func testBGPSessions() []*gona.BGPSession {
+	return []*gona.BGPSession{
+		{
+			ID:             10,
+			CustomerIP:     "192.0.2.10",
+			GroupID:        7,
+			Locked:         1,
+			Description:    "v4 session",
+			State:          "Established",
+			RoutesReceived: float64(2),
+			ConfigStatus:   1,
+			ProviderPeerIP: "192.0.2.1",
+			Location:       "Amsterdam",
+			GroupName:      "anycast",
+			ProviderIPType: string(gona.IPv4),
+			ProviderAsn:    36236,
+			CustomerAsn:    65000,
+		},
+		{
+			ID:             11,
+			CustomerIP:     "2001:db8::10",
+			GroupID:        7,
+			Description:    "v6 session",
+			ProviderPeerIP: "2001:db8::1",
+			GroupName:      "anycast",
+			ProviderIPType: string(gona.IPv6),
+			ProviderAsn:    36236,
+			CustomerAsn:    65000,
+		},
+	}
+}
+
+func TestFlattenServer(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourceServer().Schema, map[string]any{"id": 1234})
+
+	var diags diag.Diagnostics
+	setValues(FlattenServer(testServer()), d, &diags)
+	setValues(FlattenIPs(testIPs()), d, &diags)
+	setValue("bgp_peers", []map[string]any{FlattenBGPPeers(testBGPSessions())}, d, &diags)
+
+	assert.Empty(t, diags, "flattened attributes should match the data source schema")
+	assert.Equal(t, "web01.example.com", d.Get("hostname"))
+	assert.Equal(t, 5787, d.Get("image_id"))
+	assert.Equal(t, "192.0.2.10", d.Get("ip_v4"))
+	assert.Equal(t, "192.0.2.10", d.Get("public_ipv4"))
+	assert.Equal(t, "2001:db8::10", d.Get("public_ipv6"))
+	assert.Equal(t, "RUNNING", d.Get("status"))
+	assert.Equal(t, "online", d.Get("state"))
+	assert.Equal(t, 65000, d.Get("bgp_peers.0.localasn"))
+	assert.Equal(t, "2001:db8::10", d.Get("bgp_peers.0.localpeerv6"))
+}
+
+func TestFlattenServerSummary(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourceCloudPoolServers().Schema, map[string]any{"cloud_pool": "Default"})
+
+	var diags diag.Diagnostics
+	setValue("servers", []map[string]any{FlattenServerSummary(testServer())}, d, &diags)
+
+	assert.Empty(t, diags, "flattened attributes should match the data source schema")
+	assert.Equal(t, 1234, d.Get("servers.0.id"))
+	assert.Equal(t, "2001:db8::10", d.Get("servers.0.primary_ipv6"))
+}
+
+func TestFlattenIPs_Empty(t *testing.T) {
+	assert.Empty(t, FlattenIPs(gona.IPs{}))
+	assert.Equal(t, map[string]any{"public_ipv6": "2001:db8::10"}, FlattenIPs(gona.IPs{IPv6: testIPs().IPv6}))
+}
+
+func TestFlattenIPs_LowestID(t *testing.T) {
+	ips := testIPs()
+	slices.Reverse(ips.IPv4)
+
+	assert.Equal(t, "192.0.2.10", FlattenIPs(ips)["public_ipv4"])
+}
+
+func TestFlattenBGPSession(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourceBGPSessions().Schema, map[string]any{"mbpkgid": 1234})
+
+	sessions := testBGPSessions()
+	slices.Reverse(sessions)
+
+	var diags diag.Diagnostics
+	setValue("sessions", FlattenBGPSessions(sessions), d, &diags)
+
+	assert.Empty(t, diags, "flattened attributes should match the data source schema")
+	assert.Equal(t, 10, d.Get("sessions.0.id"))
+	assert.Equal(t, true, d.Get("sessions.0.locked"))
+	assert.Equal(t, "1", d.Get("sessions.0.config_status"))
+	assert.Equal(t, "Established", d.Get("sessions.0.state"))
+	assert.Equal(t, 36236, d.Get("sessions.1.provider_asn"))
+	assert.Equal(t, false, d.Get("sessions.1.locked"))
+}
+
+func TestFlattenBGPPeers(t *testing.T) {
+	assert.Nil(t, FlattenBGPPeers(nil))
+
+	peers := FlattenBGPPeers(testBGPSessions())
+	assert.Equal(t, 7, peers["group_id"])
+	assert.Equal(t, 65000, peers["localasn"])
+	assert.Equal(t, 36236, peers["peerasn"])
+	assert.Equal(t, "192.0.2.10", peers["localpeerv4"])
+	assert.Equal(t, "2001:db8::10", peers["localpeerv6"])
+	assert.Equal(t, []string{"192.0.2.1"}, peers["ipv4"])
+	assert.Equal(t, []string{"2001:db8::1"}, peers["ipv6"])
+}
+
+func TestNewServerModel(t *testing.T) {
+	m := NewServerModel(testServer())
+
+	assert.Equal(t, types.Int64Value(1234), m.ID)
+	assert.Equal(t, types.StringValue("web01.example.com"), m.Hostname)
+	assert.Equal(t, types.Int64Value(11), m.LocationID)
+	assert.Equal(t, types.StringValue("Ubuntu 22.04 LTS x64"), m.Image)
+	assert.Equal(t, types.StringValue("RUNNING"), m.Status)
+	assert.Equal(t, types.StringValue("Default"), m.CloudPool)
+}
+
+func TestNewIPModels(t *testing.T) {
+	m := NewIPModels(testIPs().IPv4)
+
+	assert.Len(t, m, 2)
+	assert.Equal(t, types.StringValue("192.0.2.10"), m[0].IP)
+	assert.Equal(t, types.BoolValue(true), m[0].Primary)
+	assert.Equal(t, types.StringValue("web01.example.com"), m[0].Reverse)
+	assert.Equal(t, types.BoolValue(false), m[1].Primary)
+	assert.Empty(t, NewIPModels(nil))
+}
+
+func TestNewBGPSessionModel(t *testing.T) {
+	sessions := testBGPSessions()
+
+	m := NewBGPSessionModel(sessions[0])
+	assert.Equal(t, types.Int64Value(10), m.ID)
+	assert.Equal(t, types.StringValue("2"), m.RoutesReceived)
+	assert.Equal(t, types.StringValue("Established"), m.State)
+	assert.Equal(t, types.BoolValue(true), m.Locked)
+	assert.Equal(t, types.Int64Value(65000), m.CustomerASN)
+
+	m = NewBGPSessionModel(sessions[1])
+	assert.True(t, m.State.IsNull(), "JSON null state should map to a Terraform null")
+	assert.True(t, m.LastUpdate.IsNull())
+}
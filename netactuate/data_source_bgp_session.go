@@ -2,22 +2,38 @@ package netactuate
 
 import (
 	"context"
-	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/netactuate/gona/gona"
 )
 
+var ipFamilies = []string{string(gona.IPv4), string(gona.IPv6)}
+
 func dataSourceBGPSessions() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceBGPSessionsRead,
+		Description: "The BGP sessions of a server, optionally filtered by IP family or state.",
 		Schema: map[string]*schema.Schema{
 			"mbpkgid": {
 				Type:     schema.TypeInt,
 				Required: true,
 			},
+			"ip_family": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: withDiagCode(CodeInvalidIPFamily, validation.ToDiagFunc(validation.StringInSlice(ipFamilies, true))),
+				Description:      "Only return sessions of this IP family, `ipv4` or `ipv6`, compared case-insensitively",
+			},
+			"state": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return sessions in this BGP state, e.g. `Established`, compared case-insensitively",
+			},
 			"sessions": {
 				Type:     schema.TypeList,
 				Computed: true,
@@ -95,43 +111,29 @@ func dataSourceBGPSessions() *schema.Resource {
 }
 
 func dataSourceBGPSessionsRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	c := m.(*gona.Client)
+	c := m.(*Client)
+
+	if err := c.require(CapabilityBGPSessions); err != nil {
+		return errDiag(CodeCapabilityUnavailable, err)
+	}
 
 	MbPkgID := d.Get("mbpkgid").(int)
+	family := d.Get("ip_family").(string)
+	state := d.Get("state").(string)
 
 	sessions, err := c.GetBGPSessions(ctx, MbPkgID)
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiag(err)
 	}
 
-	result := make([]map[string]any, len(sessions))
-
-	for i, session := range sessions {
-		s := make(map[string]any)
-
-		s["id"] = session.ID
-		// s["mb_id"] = session.MbID
-		s["description"] = session.Description
-		s["routes_received"] = session.RoutesReceived
-		s["config_status"] = fmt.Sprint(session.ConfigStatus)
-		s["last_update"] = session.LastUpdate
-		s["locked"] = session.IsLocked()
-		s["group_id"] = session.GroupID
-		s["group_name"] = session.GroupName
-		s["location_name"] = session.Location
-		s["customer_peer_ip"] = session.CustomerIP
-		s["provider_peer_ip"] = session.ProviderPeerIP
-		s["provider_ip_type"] = session.ProviderIPType
-		s["customer_asn"] = session.CustomerAsn
-		s["provider_asn"] = session.ProviderAsn
-		s["state"] = session.State
-
-		result[i] = s
-	}
+	sessions = slices.DeleteFunc(sessions, func(session *gona.BGPSession) bool {
+		return (family != "" && !strings.EqualFold(session.ProviderIPType, family)) ||
+			(state != "" && !strings.EqualFold(anyToString(session.State), state))
+	})
 
-	err = d.Set("sessions", result)
+	err = d.Set("sessions", FlattenBGPSessions(sessions))
 	if err != nil {
-		return diag.FromErr(err)
+		return errDiag(CodeStateUpdateFailed, err)
 	}
 
 	d.SetId(strconv.Itoa(MbPkgID))
@@ -0,0 +1,59 @@
+package netactuate
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceBGPSessionState() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceBGPSessionStateRead,
+		Description: "Current state of a single BGP session. Only the session itself is fetched, so it is cheap to refresh frequently.",
+		Schema: map[string]*schema.Schema{
+			"session_id": {
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"routes_received": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceBGPSessionStateRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	c := m.(*Client)
+
+	if err := c.require(CapabilityBGPSessions); err != nil {
+		return errDiag(CodeCapabilityUnavailable, err)
+	}
+
+	sessionID := d.Get("session_id").(int)
+
+	session, err := c.GetBGPSession(ctx, sessionID)
+	if err != nil {
+		return apiErrorDiag(err)
+	}
+	if session == nil {
+		return errorDiag(CodeBGPSessionNotFound, "BGP session %d not found", sessionID)
+	}
+
+	var diags diag.Diagnostics
+
+	setValue("state", anyToString(session.State), d, &diags)
+	setValue("routes_received", anyToString(session.RoutesReceived), d, &diags)
+
+	if diags == nil {
+		d.SetId(strconv.Itoa(sessionID))
+	}
+
+	return diags
+}
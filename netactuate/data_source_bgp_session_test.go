@@ -0,0 +1,55 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceBGPSessionsRead(t *testing.T) {
+	fake := NewFakeClient()
+	id := fake.AddServer(gona.Server{Name: "edge1.example.com", Installed: 1}).LastServerID()
+	c := newFakeAPIClient(fake)
+
+	_, err := c.CreateBGPSessions(context.Background(), id, 1, true, false)
+	require.NoError(t, err)
+	fake.BGPSessionState = "Idle"
+	_, err = c.CreateBGPSessions(context.Background(), id, 2, false, false)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		config map[string]any
+		want   []string
+	}{
+		{name: "all", config: map[string]any{}, want: []string{"1/ipv4/Established", "1/ipv6/Established", "2/ipv4/Idle"}},
+		{name: "family", config: map[string]any{"ip_family": "IPv4"}, want: []string{"1/ipv4/Established", "2/ipv4/Idle"}},
+		{name: "state", config: map[string]any{"state": "established"}, want: []string{"1/ipv4/Established", "1/ipv6/Established"}},
+		{name: "combined", config: map[string]any{"ip_family": "ipv6", "state": "idle"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config["mbpkgid"] = id
+			d := schema.TestResourceDataRaw(t, dataSourceBGPSessions().Schema, tt.config)
+			require.Empty(t, dataSourceBGPSessionsRead(context.Background(), d, c))
+
+			var got []string
+			for _, s := range d.Get("sessions").([]any) {
+				s := s.(map[string]any)
+				got = append(got, fmt.Sprintf("%d/%s/%s", s["group_id"], s["provider_ip_type"], s["state"]))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDataSourceBGPSessions_InvalidIPFamily(t *testing.T) {
+	diags := dataSourceBGPSessions().Schema["ip_family"].ValidateDiagFunc("ipv5", nil)
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "[NA1024]")
+}
@@ -0,0 +1,116 @@
+package netactuate
+
+import (
+	"context"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/netactuate/gona/gona"
+)
+
+var cloudPoolNames = []string{
+	gona.CloudPoolDefault.Name(),
+	gona.CloudPoolGeneralCompute.Name(),
+	gona.CloudPoolAMDEPYC.Name(),
+}
+
+func dataSourceCloudPoolServers() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceCloudPoolServersRead,
+		Description: "The servers of a cloud pool. Like `netactuate_servers`, terminated servers are left out.",
+		Schema: map[string]*schema.Schema{
+			"cloud_pool": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: withDiagCode(CodeInvalidCloudPool, validation.ToDiagFunc(validation.StringInSlice(cloudPoolNames, false))),
+				Description:      "Name of the cloud pool to list servers for",
+			},
+			"servers": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"hostname": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"location_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"primary_ipv4": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"primary_ipv6": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceCloudPoolServersRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	c := m.(*Client)
+
+	if err := c.require(CapabilityCloudPools); err != nil {
+		return errDiag(CodeCapabilityUnavailable, err)
+	}
+
+	pool := d.Get("cloud_pool").(string)
+
+	servers, err := c.GetServers(ctx)
+	if err != nil {
+		return apiErrorDiag(err)
+	}
+
+	result := make([]map[string]any, 0)
+
+	for _, server := range servers {
+		if server.ServerStatus == "TERMINATED" || cloudPoolName(server) != pool {
+			continue
+		}
+
+		result = append(result, FlattenServerSummary(server))
+	}
+
+	slices.SortFunc(result, func(a, b map[string]any) int {
+		return a["id"].(int) - b["id"].(int)
+	})
+
+	var diags diag.Diagnostics
+
+	setValue("servers", result, d, &diags)
+
+	if diags == nil {
+		d.SetId(pool)
+	}
+
+	return diags
+}
+
+// cloudPoolName returns the name of the cloud pool a server belongs to,
+// treating servers without an explicit pool as members of the default one.
+func cloudPoolName(server gona.Server) string {
+	if server.CloudPool == "" {
+		return gona.CloudPoolDefault.Name()
+	}
+	return server.CloudPool
+}
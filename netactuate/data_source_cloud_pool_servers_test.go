@@ -0,0 +1,30 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceCloudPoolServersRead(t *testing.T) {
+	fake := NewFakeClient()
+	web := fake.AddServer(gona.Server{Name: "web.example.com", ServerStatus: "RUNNING"}).LastServerID()
+	db := fake.AddServer(gona.Server{Name: "db.example.com", ServerStatus: "STOPPED", CloudPool: gona.CloudPoolDefault.Name()}).LastServerID()
+	fake.AddServer(gona.Server{Name: "epyc.example.com", ServerStatus: "RUNNING", CloudPool: gona.CloudPoolAMDEPYC.Name()}).
+		AddServer(gona.Server{Name: "web.example.com", ServerStatus: "TERMINATED"})
+
+	d := schema.TestResourceDataRaw(t, dataSourceCloudPoolServers().Schema, map[string]any{"cloud_pool": gona.CloudPoolDefault.Name()})
+	diags := dataSourceCloudPoolServersRead(context.Background(), d, newFakeAPIClient(fake))
+	require.Empty(t, diags)
+	assert.Equal(t, gona.CloudPoolDefault.Name(), d.Id())
+
+	var ids []int
+	for _, s := range d.Get("servers").([]any) {
+		ids = append(ids, s.(map[string]any)["id"].(int))
+	}
+	assert.Equal(t, []int{web, db}, ids, "terminated servers should be left out")
+}
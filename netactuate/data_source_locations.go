@@ -0,0 +1,112 @@
+package netactuate
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/netactuate/gona/gona"
+)
+
+func dataSourceLocations() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceLocationsRead,
+		Description: "All NetActuate deployment locations, optionally filtered by continent, IATA code or availability.",
+		Schema: map[string]*schema.Schema{
+			"continent": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return locations on this continent, compared case-insensitively",
+			},
+			"iata_code": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the location with this IATA airport code, compared case-insensitively",
+			},
+			"disabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only return locations where deployments are disabled (true) or enabled (false)",
+			},
+			"locations": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"iata_code": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"continent": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"flag": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"disabled": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceLocationsRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	c := m.(*Client)
+
+	locations, err := c.GetLocations(ctx)
+	if err != nil {
+		return apiErrorDiag(err)
+	}
+
+	continent := d.Get("continent").(string)
+	iataCode := d.Get("iata_code").(string)
+
+	// A false bool can't be told apart from an unset one through GetOk.
+	v, disabledSet := d.GetOkExists("disabled")
+	disabled := v.(bool)
+
+	filters := []string{continent, iataCode, ""}
+	if disabledSet {
+		filters[2] = strconv.FormatBool(disabled)
+	}
+
+	locations = slices.DeleteFunc(locations, func(l gona.Location) bool {
+		return (continent != "" && !strings.EqualFold(l.Continent, continent)) ||
+			(iataCode != "" && !strings.EqualFold(l.IATACode, iataCode)) ||
+			(disabledSet && (l.Disabled == 1) != disabled)
+	})
+	slices.SortFunc(locations, func(a, b gona.Location) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	result := make([]map[string]any, len(locations))
+	for i, location := range locations {
+		result[i] = FlattenLocation(location)
+	}
+
+	var diags diag.Diagnostics
+	setValue("locations", result, d, &diags)
+	if diags == nil {
+		d.SetId("locations/" + strings.Join(filters, "/"))
+	}
+
+	return diags
+}
@@ -0,0 +1,51 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceLocationsRead(t *testing.T) {
+	api := newTestAPI(t, map[string]string{
+		"cloud/locations": `[
+			{"id": 12, "name": "Frankfurt, DE", "iata_code": "FRA", "continent": "Europe", "flag": "de", "disabled": 0},
+			{"id": 3, "name": "Amsterdam, NL", "iata_code": "AMS", "continent": "Europe", "flag": "nl", "disabled": 0},
+			{"id": 7, "name": "Paris, FR", "iata_code": "CDG", "continent": "Europe", "flag": "fr", "disabled": 1},
+			{"id": 1, "name": "Ashburn, VA", "iata_code": "IAD", "continent": "North America", "flag": "us", "disabled": 0}
+		]`,
+	})
+	c, err := NewClient("test-api-key", api.URL+"/api/", DefaultAPIVersion)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		config map[string]any
+		want   []int
+	}{
+		{name: "all", config: map[string]any{}, want: []int{1, 3, 7, 12}},
+		{name: "continent", config: map[string]any{"continent": "europe"}, want: []int{3, 7, 12}},
+		{name: "iata code", config: map[string]any{"iata_code": "ams"}, want: []int{3}},
+		{name: "enabled", config: map[string]any{"continent": "Europe", "disabled": false}, want: []int{3, 12}},
+		{name: "disabled", config: map[string]any{"disabled": true}, want: []int{7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceLocations().Schema, tt.config)
+
+			diags := dataSourceLocationsRead(context.Background(), d, c)
+			require.Empty(t, diags)
+			assert.NotEmpty(t, d.Id())
+
+			var ids []int
+			for _, l := range d.Get("locations").([]any) {
+				ids = append(ids, l.(map[string]any)["id"].(int))
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
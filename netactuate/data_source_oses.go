@@ -0,0 +1,124 @@
+package netactuate
+
+import (
+	"cmp"
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/netactuate/gona/gona"
+)
+
+func dataSourceOSes() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceOSesRead,
+		Description: "All OS images available for NetActuate servers, optionally filtered by type, subtype, bits or name.",
+		Schema: map[string]*schema.Schema{
+			"type": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return images of this type, e.g. `linux`, compared case-insensitively",
+			},
+			"subtype": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return images of this subtype, e.g. `ubuntu`, compared case-insensitively",
+			},
+			"bits": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return images with this architecture width, e.g. `64`",
+			},
+			"name_regex": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: withDiagCode(CodeInvalidNameRegex, validation.ToDiagFunc(validation.StringIsValidRegExp)),
+				Description:      "Only return images whose name matches this regular expression",
+			},
+			"oses": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subtype": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"size": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"bits": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"tech": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceOSesRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	c := m.(*Client)
+
+	oses, err := c.GetOSs(ctx)
+	if err != nil {
+		return apiErrorDiag(err)
+	}
+
+	osType := d.Get("type").(string)
+	subtype := d.Get("subtype").(string)
+	bits := d.Get("bits").(string)
+	nameRegex := d.Get("name_regex").(string)
+
+	var re *regexp.Regexp
+	if nameRegex != "" {
+		if re, err = regexp.Compile(nameRegex); err != nil {
+			return errDiag(CodeInvalidNameRegex, err)
+		}
+	}
+
+	oses = slices.DeleteFunc(oses, func(os gona.OS) bool {
+		return (osType != "" && !strings.EqualFold(os.Type, osType)) ||
+			(subtype != "" && !strings.EqualFold(os.Subtype, subtype)) ||
+			(bits != "" && os.Bits != bits) ||
+			(re != nil && !re.MatchString(os.Os))
+	})
+	slices.SortFunc(oses, func(a, b gona.OS) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	result := make([]map[string]any, len(oses))
+	for i, os := range oses {
+		result[i] = FlattenOS(os)
+	}
+
+	var diags diag.Diagnostics
+	setValue("oses", result, d, &diags)
+	if diags == nil {
+		d.SetId("oses/" + strings.Join([]string{osType, subtype, bits, nameRegex}, "/"))
+	}
+
+	return diags
+}
@@ -0,0 +1,52 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceOSesRead(t *testing.T) {
+	api := newTestAPI(t, map[string]string{
+		"cloud/images": `[
+			{"id": 1200, "os": "Ubuntu 24.04 LTS x64", "type": "linux", "subtype": "ubuntu", "bits": "64", "tech": "kvm"},
+			{"id": 1000, "os": "Ubuntu 22.04 LTS x64", "type": "linux", "subtype": "ubuntu", "bits": "64", "tech": "kvm"},
+			{"id": 900, "os": "Ubuntu 22.10 x64", "type": "linux", "subtype": "ubuntu", "bits": "64", "tech": "kvm"},
+			{"id": 800, "os": "Debian 12 x64", "type": "linux", "subtype": "debian", "bits": "64", "tech": "kvm"},
+			{"id": 700, "os": "Windows Server 2022", "type": "windows", "subtype": "windows", "bits": "64", "tech": "kvm"}
+		]`,
+	})
+	c, err := NewClient("test-api-key", api.URL+"/api/", DefaultAPIVersion)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		config map[string]any
+		want   []int
+	}{
+		{name: "all", config: map[string]any{}, want: []int{700, 800, 900, 1000, 1200}},
+		{name: "type", config: map[string]any{"type": "Linux"}, want: []int{800, 900, 1000, 1200}},
+		{name: "subtype", config: map[string]any{"subtype": "debian"}, want: []int{800}},
+		{name: "lts", config: map[string]any{"subtype": "ubuntu", "bits": "64", "name_regex": `LTS`}, want: []int{1000, 1200}},
+		{name: "no match", config: map[string]any{"bits": "32"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceOSes().Schema, tt.config)
+
+			diags := dataSourceOSesRead(context.Background(), d, c)
+			require.Empty(t, diags)
+			assert.NotEmpty(t, d.Id())
+
+			var ids []int
+			for _, os := range d.Get("oses").([]any) {
+				ids = append(ids, os.(map[string]any)["id"].(int))
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
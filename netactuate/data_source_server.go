@@ -2,7 +2,9 @@ package netactuate
 
 import (
 	"context"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -12,14 +14,24 @@ import (
 func dataSourceServer() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceServerRead,
+		Description: "A NetActuate server, looked up by its `id` (mbpkgid) or its `hostname`.",
 		Schema: map[string]*schema.Schema{
 			"id": {
-				Type:     schema.TypeInt,
-				Required: true,
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "hostname"},
+				Description:  "ID (mbpkgid) of the server",
 			},
+			"refresh_ips": refreshFlag("Read the IP addresses of the server with an extra API call"),
+			"refresh_bgp": refreshFlag("Read the BGP sessions of the server for `bgp_peers`, with an API call per session"),
 			"hostname": {
-				Type:     schema.TypeString,
-				Computed: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "hostname"},
+				Description: "Hostname (FQDN) of the server, to look it up instead of by `id`. The match ignores case and a " +
+					"trailing dot and skips terminated servers. It fails unless exactly one server matches",
 			},
 			"plan_id": {
 				Type:     schema.TypeInt,
@@ -108,75 +120,43 @@ func dataSourceServer() *schema.Resource {
 }
 
 func dataSourceServerRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	c := m.(*gona.Client)
+	c := m.(*Client)
 
-	server, err := c.GetServer(ctx, d.Get("id").(int))
-	if err != nil {
-		return diag.FromErr(err)
+	var server gona.Server
+	if hostname, ok := d.GetOk("hostname"); ok {
+		var diags diag.Diagnostics
+		if server, diags = findServerByHostname(ctx, c, hostname.(string)); diags.HasError() {
+			return diags
+		}
+	} else {
+		var err error
+		if server, err = c.GetServer(ctx, d.Get("id").(int)); err != nil {
+			return apiErrorDiag(err)
+		}
 	}
 
 	// TODO: Optimize to avoid serial API calls
 
-	ips, err := c.GetIPs(ctx, server.ID)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	bgpSessions, err := c.GetBGPSessions(ctx, server.ID)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
 	var diags diag.Diagnostics
 
-	setValue("hostname", server.Name, d, &diags)
-	setValue("package", server.Package, d, &diags)
-	setValue("plan_id", server.PlanID, d, &diags)
-	setValue("location_id", server.LocationID, d, &diags)
-	setValue("image", server.OS, d, &diags)
-	setValue("image_id", server.OSID, d, &diags)
-	setValue("ip_v4", server.PrimaryIPv4, d, &diags)
-	setValue("ip_v6", server.PrimaryIPv6, d, &diags)
-	setValue("status", server.ServerStatus, d, &diags)
-	setValue("state", server.PowerStatus, d, &diags)
-
-	if len(ips.IPv4) > 0 {
-		setValue("public_ipv4", ips.IPv4[0].IP, d, &diags)
-	}
-	if len(ips.IPv6) > 0 {
-		setValue("public_ipv6", ips.IPv6[0].IP, d, &diags)
-	}
-
-	if len(bgpSessions) > 0 {
-		var peerV4 []string
-		var peerV6 []string
-
-		bgpPeers := make(map[string]any)
+	setValues(FlattenServer(server), d, &diags)
 
-		session := (bgpSessions)[0]
-
-		bgpPeers["group_id"] = session.GroupID
-		bgpPeers["localasn"] = session.CustomerAsn
-		bgpPeers["peerasn"] = session.ProviderAsn
-
-		for _, session := range bgpSessions {
-			if session.IsProviderIPTypeV4() {
-				bgpPeers["localpeerv4"] = session.CustomerIP
-				peerV4 = append(peerV4, session.ProviderPeerIP)
-			} else {
-				bgpPeers["localpeerv6"] = session.CustomerIP
-				peerV6 = append(peerV6, session.ProviderPeerIP)
-			}
+	if refreshEnabled(d, "refresh_ips") {
+		ips, err := c.GetIPs(ctx, server.ID)
+		if err != nil {
+			return apiErrorDiag(err)
 		}
+		setValues(FlattenIPs(ips), d, &diags)
+	}
 
-		if len(peerV4) > 0 {
-			bgpPeers["ipv4"] = peerV4
+	if refreshEnabled(d, "refresh_bgp") {
+		bgpSessions, err := c.GetBGPSessions(ctx, server.ID)
+		if err != nil {
+			return apiErrorDiag(err)
 		}
-		if len(peerV6) > 0 {
-			bgpPeers["ipv6"] = peerV6
+		if bgpPeers := FlattenBGPPeers(bgpSessions); bgpPeers != nil {
+			setValue("bgp_peers", []map[string]any{bgpPeers}, d, &diags)
 		}
-
-		setValue("bgp_peers", []map[string]any{bgpPeers}, d, &diags)
 	}
 
 	if diags == nil {
@@ -185,3 +165,38 @@ func dataSourceServerRead(ctx context.Context, d *schema.ResourceData, m any) di
 
 	return diags
 }
+
+// findServerByHostname returns the only server that isn't terminated with
+// the hostname. Hostnames are compared like DNS names, ignoring case and a
+// trailing dot.
+func findServerByHostname(ctx context.Context, c *Client, hostname string) (gona.Server, diag.Diagnostics) {
+	servers, err := c.GetServers(ctx)
+	if err != nil {
+		return gona.Server{}, apiErrorDiag(err)
+	}
+
+	want := strings.TrimSuffix(hostname, ".")
+	var matches []gona.Server
+	for _, server := range servers {
+		if server.ServerStatus != "TERMINATED" && strings.EqualFold(strings.TrimSuffix(server.Name, "."), want) {
+			matches = append(matches, server)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return gona.Server{}, errorDiag(CodeServerNotFound, "No server with hostname %q found", hostname)
+	case 1:
+		return matches[0], nil
+	}
+
+	slices.SortFunc(matches, func(a, b gona.Server) int {
+		return a.ID - b.ID
+	})
+	ids := make([]string, 0, len(matches))
+	for _, server := range matches {
+		ids = append(ids, strconv.Itoa(server.ID))
+	}
+	return gona.Server{}, errorDiag(CodeAmbiguousServerHostname,
+		"%d servers with hostname %q found (%s), look the server up by id instead", len(matches), hostname, strings.Join(ids, ", "))
+}
@@ -0,0 +1,61 @@
+package netactuate
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceServerStatus() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceServerStatusRead,
+		Description: "Current status of a single server, e.g. to gate deploys on its health. Only the server itself is fetched, " +
+			"without its IPs or BGP sessions, so it is cheap to refresh frequently.",
+		Schema: map[string]*schema.Schema{
+			"mbpkgid": {
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+			"server_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Status of the server as reported by the API, e.g. `BUILDING`, `RUNNING` or `TERMINATED`, blank once its package is cancelled",
+			},
+			"power_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Power status of the server as reported by the API, e.g. `RUNNING` or `STOPPED`",
+			},
+			"installed": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the image of the server is installed",
+			},
+		},
+	}
+}
+
+func dataSourceServerStatusRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	c := m.(*Client)
+
+	id := d.Get("mbpkgid").(int)
+
+	server, err := c.GetServer(ctx, id)
+	if err != nil {
+		return apiErrorDiag(err)
+	}
+
+	var diags diag.Diagnostics
+
+	setValue("server_status", server.ServerStatus, d, &diags)
+	setValue("power_status", server.PowerStatus, d, &diags)
+	setValue("installed", server.Installed == 1, d, &diags)
+
+	if diags == nil {
+		d.SetId(strconv.Itoa(id))
+	}
+
+	return diags
+}
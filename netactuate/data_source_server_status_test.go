@@ -0,0 +1,35 @@
+package netactuate
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceServerStatusRead(t *testing.T) {
+	fake := NewFakeClient()
+	id := fake.AddServer(gona.Server{Name: "web01.example.com", ServerStatus: "BUILDING", PowerStatus: "STOPPED"}).LastServerID()
+	c := newFakeAPIClient(fake)
+
+	d := schema.TestResourceDataRaw(t, dataSourceServerStatus().Schema, map[string]any{"mbpkgid": id})
+	require.Empty(t, dataSourceServerStatusRead(context.Background(), d, c))
+
+	assert.Equal(t, strconv.Itoa(id), d.Id())
+	assert.Equal(t, "BUILDING", d.Get("server_status"))
+	assert.Equal(t, "STOPPED", d.Get("power_status"))
+	assert.Equal(t, false, d.Get("installed"))
+	assert.Equal(t, []string{"GetServer(" + strconv.Itoa(id) + ")"}, fake.GetCalls(), "only the server should be fetched")
+}
+
+func TestDataSourceServerStatusRead_NotFound(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourceServerStatus().Schema, map[string]any{"mbpkgid": 404})
+
+	diags := dataSourceServerStatusRead(context.Background(), d, newFakeAPIClient(NewFakeClient()))
+	require.True(t, diags.HasError())
+	assert.Empty(t, d.Id())
+}
@@ -0,0 +1,57 @@
+package netactuate
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceServerRead_Hostname(t *testing.T) {
+	fake := NewFakeClient()
+	web := fake.AddServer(gona.Server{Name: "web.example.com", ServerStatus: "RUNNING", PrimaryIPv4: "192.0.2.10"}).LastServerID()
+	fake.AddServer(gona.Server{Name: "db.example.com", ServerStatus: "TERMINATED"})
+	db := fake.AddServer(gona.Server{Name: "db.example.com", ServerStatus: "RUNNING"}).LastServerID()
+	dup1 := fake.AddServer(gona.Server{Name: "dup.example.com", ServerStatus: "RUNNING"}).LastServerID()
+	dup2 := fake.AddServer(gona.Server{Name: "dup.example.com", ServerStatus: "STOPPED"}).LastServerID()
+	c := newFakeAPIClient(fake)
+
+	tests := []struct {
+		name     string
+		hostname string
+		wantID   int
+		wantErr  string
+	}{
+		{name: "exact", hostname: "web.example.com", wantID: web},
+		{name: "case and trailing dot", hostname: "WEB.example.com.", wantID: web},
+		{name: "terminated skipped", hostname: "db.example.com", wantID: db},
+		{name: "not found", hostname: "mail.example.com", wantErr: `[NA1016] No server with hostname "mail.example.com" found`},
+		{name: "ambiguous", hostname: "dup.example.com", wantErr: `[NA1017] 2 servers with hostname "dup.example.com" found (` +
+			strconv.Itoa(dup1) + ", " + strconv.Itoa(dup2) + "), look the server up by id instead"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceServer().Schema, map[string]any{
+				"hostname":    tt.hostname,
+				"refresh_ips": false,
+				"refresh_bgp": false,
+			})
+
+			diags := dataSourceServerRead(context.Background(), d, c)
+			if tt.wantErr != "" {
+				require.True(t, diags.HasError())
+				assert.Equal(t, tt.wantErr, diags[0].Summary)
+				return
+			}
+
+			require.Empty(t, diags)
+			assert.Equal(t, strconv.Itoa(tt.wantID), d.Id())
+			assert.Equal(t, tt.wantID, d.Get("id"))
+		})
+	}
+}
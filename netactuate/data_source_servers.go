@@ -0,0 +1,100 @@
+package netactuate
+
+import (
+	"cmp"
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/netactuate/gona/gona"
+)
+
+func dataSourceServers() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceServersRead,
+		Description: "All servers of the account, optionally filtered by status, location, cloud pool or hostname. " +
+			"Terminated servers are only returned when filtering by the `TERMINATED` status.",
+		Schema: map[string]*schema.Schema{
+			"status": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return servers with this status, e.g. `RUNNING`, compared case-insensitively",
+			},
+			"location_id": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Only return servers in the location with this ID",
+			},
+			"cloud_pool": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: withDiagCode(CodeInvalidCloudPool, validation.ToDiagFunc(validation.StringInSlice(cloudPoolNames, false))),
+				Description:      "Only return servers in this cloud pool",
+			},
+			"name_regex": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: withDiagCode(CodeInvalidNameRegex, validation.ToDiagFunc(validation.StringIsValidRegExp)),
+				Description:      "Only return servers whose hostname matches this regular expression",
+			},
+			"servers": dataSourceCloudPoolServers().Schema["servers"],
+		},
+	}
+}
+
+func dataSourceServersRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	c := m.(*Client)
+
+	status := d.Get("status").(string)
+	locationID := d.Get("location_id").(int)
+	pool := d.Get("cloud_pool").(string)
+	nameRegex := d.Get("name_regex").(string)
+
+	if pool != "" {
+		if err := c.require(CapabilityCloudPools); err != nil {
+			return errDiag(CodeCapabilityUnavailable, err)
+		}
+	}
+
+	var re *regexp.Regexp
+	if nameRegex != "" {
+		var err error
+		if re, err = regexp.Compile(nameRegex); err != nil {
+			return errDiag(CodeInvalidNameRegex, err)
+		}
+	}
+
+	servers, err := c.GetServers(ctx)
+	if err != nil {
+		return apiErrorDiag(err)
+	}
+
+	servers = slices.DeleteFunc(servers, func(server gona.Server) bool {
+		return (status == "" && server.ServerStatus == "TERMINATED") ||
+			(status != "" && !strings.EqualFold(server.ServerStatus, status)) ||
+			(locationID != 0 && server.LocationID != locationID) ||
+			(pool != "" && cloudPoolName(server) != pool) ||
+			(re != nil && !re.MatchString(server.Name))
+	})
+	slices.SortFunc(servers, func(a, b gona.Server) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	result := make([]map[string]any, len(servers))
+	for i, server := range servers {
+		result[i] = FlattenServerSummary(server)
+	}
+
+	var diags diag.Diagnostics
+	setValue("servers", result, d, &diags)
+	if diags == nil {
+		d.SetId("servers/" + strings.Join([]string{status, strconv.Itoa(locationID), pool, nameRegex}, "/"))
+	}
+
+	return diags
+}
@@ -0,0 +1,51 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceServersRead(t *testing.T) {
+	fake := NewFakeClient()
+	web1 := fake.AddServer(gona.Server{Name: "web1.example.com", ServerStatus: "RUNNING", LocationID: 3}).LastServerID()
+	web2 := fake.AddServer(gona.Server{Name: "web2.example.com", ServerStatus: "STOPPED", LocationID: 12, CloudPool: gona.CloudPoolAMDEPYC.Name()}).LastServerID()
+	db := fake.AddServer(gona.Server{Name: "db.example.com", ServerStatus: "RUNNING", LocationID: 12}).LastServerID()
+	old := fake.AddServer(gona.Server{Name: "web1.example.com", ServerStatus: "TERMINATED", LocationID: 3}).LastServerID()
+	c := newFakeAPIClient(fake)
+
+	tests := []struct {
+		name   string
+		config map[string]any
+		want   []int
+	}{
+		{name: "all", config: map[string]any{}, want: []int{web1, web2, db}},
+		{name: "status", config: map[string]any{"status": "running"}, want: []int{web1, db}},
+		{name: "terminated", config: map[string]any{"status": "TERMINATED"}, want: []int{old}},
+		{name: "location", config: map[string]any{"location_id": 12}, want: []int{web2, db}},
+		{name: "cloud pool", config: map[string]any{"cloud_pool": gona.CloudPoolDefault.Name()}, want: []int{web1, db}},
+		{name: "name regex", config: map[string]any{"name_regex": `^web\d`}, want: []int{web1, web2}},
+		{name: "combined", config: map[string]any{"name_regex": "web", "location_id": 12}, want: []int{web2}},
+		{name: "none", config: map[string]any{"status": "BUILDING"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceServers().Schema, tt.config)
+
+			diags := dataSourceServersRead(context.Background(), d, c)
+			require.Empty(t, diags)
+			assert.NotEmpty(t, d.Id())
+
+			var ids []int
+			for _, s := range d.Get("servers").([]any) {
+				ids = append(ids, s.(map[string]any)["id"].(int))
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
@@ -2,7 +2,9 @@ package netactuate
 
 import (
 	"context"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -12,40 +14,67 @@ import (
 func dataSourceSshKey() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceSshKeyRead,
+		Description: "An SSH key of the account, looked up by its `id`, `name` or `fingerprint`.",
 		Schema: map[string]*schema.Schema{
 			"id": {
-				Type:     schema.TypeInt,
-				Required: true,
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name", "fingerprint"},
+				Description:  "ID of the key",
 			},
 			"name": {
-				Type:     schema.TypeString,
-				Computed: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name", "fingerprint"},
+				Description:  "Name of the key, to look it up instead of by `id`",
 			},
 			"key": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
 			"fingerprint": {
-				Type:     schema.TypeString,
-				Computed: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name", "fingerprint"},
+				Description: "Fingerprint of the key as returned by the API. A lookup matches either that fingerprint " +
+					"or the SHA256 fingerprint of the key as printed by `ssh-keygen -l`, e.g. `SHA256:Pa9OjCGK...`",
 			},
 		},
 	}
 }
 
 func dataSourceSshKeyRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	c := m.(*gona.Client)
+	c := m.(*Client)
 
-	sshKey, err := c.GetSSHKey(ctx, d.Get("id").(int))
-	if err != nil {
-		return diag.FromErr(err)
+	var sshKey gona.SSHKey
+	if id, ok := d.GetOk("id"); ok {
+		var err error
+		if sshKey, err = c.GetSSHKey(ctx, id.(int)); err != nil {
+			return apiErrorDiag(err)
+		}
+	} else {
+		keys, err := c.GetSSHKeys(ctx)
+		if err != nil {
+			return apiErrorDiag(err)
+		}
+
+		var diags diag.Diagnostics
+		if sshKey, diags = findSSHKey(keys, d.Get("name").(string), d.Get("fingerprint").(string)); diags.HasError() {
+			return diags
+		}
 	}
 
 	var diags diag.Diagnostics
 
 	setValue("name", sshKey.Name, d, &diags)
 	setValue("key", sshKey.Key, d, &diags)
-	setValue("fingerprint", sshKey.Fingerprint, d, &diags)
+	// Keep a configured fingerprint, it may be the SHA256 one.
+	if _, ok := d.GetOk("fingerprint"); !ok {
+		setValue("fingerprint", sshKey.Fingerprint, d, &diags)
+	}
 
 	if diags == nil {
 		d.SetId(strconv.Itoa(sshKey.ID))
@@ -53,3 +82,38 @@ func dataSourceSshKeyRead(ctx context.Context, d *schema.ResourceData, m any) di
 
 	return diags
 }
+
+// findSSHKey returns the only key with the name, or with the fingerprint.
+// The fingerprint matches the one of the API or the SHA256 fingerprint of
+// the key.
+func findSSHKey(keys []gona.SSHKey, name, fingerprint string) (gona.SSHKey, diag.Diagnostics) {
+	by, value := "name", name
+	if fingerprint != "" {
+		by, value = "fingerprint", fingerprint
+	}
+
+	var matches []gona.SSHKey
+	for _, key := range keys {
+		if name != "" && key.Name == name ||
+			fingerprint != "" && (key.Fingerprint == fingerprint || sshKeyFingerprint(key.Key).ValueString() == fingerprint) {
+			matches = append(matches, key)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return gona.SSHKey{}, errorDiag(CodeSSHKeyNotFound, "No SSH key with %s %q found", by, value)
+	case 1:
+		return matches[0], nil
+	}
+
+	slices.SortFunc(matches, func(a, b gona.SSHKey) int {
+		return a.ID - b.ID
+	})
+	ids := make([]string, 0, len(matches))
+	for _, key := range matches {
+		ids = append(ids, strconv.Itoa(key.ID))
+	}
+	return gona.SSHKey{}, errorDiag(CodeAmbiguousSSHKey,
+		"%d SSH keys with %s %q found (%s), look the key up by id instead", len(matches), by, value, strings.Join(ids, ", "))
+}
@@ -0,0 +1,55 @@
+package netactuate
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceSshKeyRead(t *testing.T) {
+	fake := NewFakeClient()
+	c := newFakeAPIClient(fake)
+
+	deploy, err := c.CreateSSHKey(context.Background(), "deploy", testSSHPublicKey)
+	require.NoError(t, err)
+	dup1, err := c.CreateSSHKey(context.Background(), "dup", "ssh-ed25519 AAAA one")
+	require.NoError(t, err)
+	dup2, err := c.CreateSSHKey(context.Background(), "dup", "ssh-ed25519 AAAA two")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		config  map[string]any
+		wantID  int
+		wantErr string
+	}{
+		{name: "id", config: map[string]any{"id": deploy.ID}, wantID: deploy.ID},
+		{name: "name", config: map[string]any{"name": "deploy"}, wantID: deploy.ID},
+		{name: "fingerprint", config: map[string]any{"fingerprint": testSSHKeyFingerprint}, wantID: deploy.ID},
+		{name: "unknown name", config: map[string]any{"name": "ci"}, wantErr: `[NA1019] No SSH key with name "ci" found`},
+		{name: "ambiguous name", config: map[string]any{"name": "dup"}, wantErr: `[NA1020] 2 SSH keys with name "dup" found (` +
+			strconv.Itoa(dup1.ID) + ", " + strconv.Itoa(dup2.ID) + "), look the key up by id instead"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceSshKey().Schema, tt.config)
+
+			diags := dataSourceSshKeyRead(context.Background(), d, c)
+			if tt.wantErr != "" {
+				require.True(t, diags.HasError())
+				assert.Equal(t, tt.wantErr, diags[0].Summary)
+				return
+			}
+
+			require.Empty(t, diags)
+			assert.Equal(t, strconv.Itoa(tt.wantID), d.Id())
+			assert.Equal(t, "deploy", d.Get("name"))
+			assert.Equal(t, testSSHPublicKey, d.Get("key"))
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package netactuate
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/hashicorp/go-cty/cty"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// DiagCode is a stable, machine-readable identifier of a diagnostic emitted
+// by the provider. Summaries start with the code in brackets, e.g.
+// "[NA1001] ...". Codes are never reused for a different condition.
+type DiagCode string
+
+// Configuration and validation errors.
+const (
+	CodeInvalidHostname         DiagCode = "NA1001"
+	CodeInvalidResourceID       DiagCode = "NA1002"
+	CodeInvalidImportID         DiagCode = "NA1003"
+	CodeBillingOptInRequired    DiagCode = "NA1004"
+	CodeBillingContractRequired DiagCode = "NA1005"
+	CodeLocationRequired        DiagCode = "NA1006"
+	CodeLocationNotFound        DiagCode = "NA1007"
+	CodeImageNotFound           DiagCode = "NA1008"
+	CodeInvalidPowerState       DiagCode = "NA1009"
+	CodeInvalidNameRegex        DiagCode = "NA1010"
+	CodeInvalidASN              DiagCode = "NA1011"
+	CodeInvalidCloudPool        DiagCode = "NA1012"
+	CodeInvalidEncryptionKey    DiagCode = "NA1013"
+	CodeInvalidTimestamp        DiagCode = "NA1014"
+	CodeInvalidExpiryAction     DiagCode = "NA1015"
+	CodeServerNotFound          DiagCode = "NA1016"
+	CodeAmbiguousServerHostname DiagCode = "NA1017"
+	CodeInvalidSSHKey           DiagCode = "NA1018"
+	CodeSSHKeyNotFound          DiagCode = "NA1019"
+	CodeAmbiguousSSHKey         DiagCode = "NA1020"
+	CodeUnsupportedByOS         DiagCode = "NA1021"
+	CodeLocationDisabled        DiagCode = "NA1022"
+	CodeLocationNotInPool       DiagCode = "NA1023"
+	CodeInvalidIPFamily         DiagCode = "NA1024"
+	CodePublicKeyFileUnreadable DiagCode = "NA1025"
+	CodeConflictingArguments    DiagCode = "NA1026"
+	CodeInvalidDuration         DiagCode = "NA1027"
+	CodeValueOutOfRange         DiagCode = "NA1028"
+)
+
+// Provider setup errors.
+const (
+	CodeMissingAPIKey         DiagCode = "NA1101"
+	CodeClientSetupFailed     DiagCode = "NA1102"
+	CodeCapabilityUnavailable DiagCode = "NA1103"
+)
+
+// NetActuate API errors, one per ErrorKind.
+const (
+	CodeAPIRequestFailed    DiagCode = "NA2001"
+	CodeAPINotFound         DiagCode = "NA2002"
+	CodeAPIRateLimited      DiagCode = "NA2003"
+	CodeAPIConflict         DiagCode = "NA2004"
+	CodeAPIAuthFailed       DiagCode = "NA2005"
+	CodeAPIUnavailable      DiagCode = "NA2006"
+	CodeAPIValidationFailed DiagCode = "NA2007"
+)
+
+// Errors and warnings while managing resources.
+const (
+	CodeServerStatusTimeout       DiagCode = "NA3001"
+	CodePowerStateTimeout         DiagCode = "NA3002"
+	CodeFinalStateNotRecorded     DiagCode = "NA3003"
+	CodeBGPSessionNotFound        DiagCode = "NA3004"
+	CodeBGPSessionsNotDeleted     DiagCode = "NA3005"
+	CodeBGPSessionsNotImported    DiagCode = "NA3006"
+	CodeStateUpdateFailed         DiagCode = "NA3007"
+	CodeStateEncryptionFailed     DiagCode = "NA3008"
+	CodeRelocationNotRolledBack   DiagCode = "NA3009"
+	CodeRelocatedServerNotDeleted DiagCode = "NA3010"
+	CodeServerExpired             DiagCode = "NA3011"
+	CodeBGPSessionsNotEstablished DiagCode = "NA3012"
+	CodeServerIPsChanged          DiagCode = "NA3013"
+	CodeStateUpgradeFailed        DiagCode = "NA3014"
+	CodeServerBuildStuck          DiagCode = "NA3015"
+	CodeBGPSessionsNotListed      DiagCode = "NA3016"
+)
+
+var diagCodeNames = map[DiagCode]string{
+	CodeInvalidHostname:         "InvalidHostname",
+	CodeInvalidResourceID:       "InvalidResourceID",
+	CodeInvalidImportID:         "InvalidImportID",
+	CodeBillingOptInRequired:    "BillingOptInRequired",
+	CodeBillingContractRequired: "BillingContractRequired",
+	CodeLocationRequired:        "LocationRequired",
+	CodeLocationNotFound:        "LocationNotFound",
+	CodeImageNotFound:           "ImageNotFound",
+	CodeInvalidPowerState:       "InvalidPowerState",
+	CodeInvalidNameRegex:        "InvalidNameRegex",
+	CodeInvalidASN:              "InvalidASN",
+	CodeInvalidCloudPool:        "InvalidCloudPool",
+	CodeInvalidEncryptionKey:    "InvalidEncryptionKey",
+	CodeInvalidTimestamp:        "InvalidTimestamp",
+	CodeInvalidExpiryAction:     "InvalidExpiryAction",
+	CodeServerNotFound:          "ServerNotFound",
+	CodeAmbiguousServerHostname: "AmbiguousServerHostname",
+	CodeInvalidSSHKey:           "InvalidSSHKey",
+	CodeSSHKeyNotFound:          "SSHKeyNotFound",
+	CodeAmbiguousSSHKey:         "AmbiguousSSHKey",
+	CodeUnsupportedByOS:         "UnsupportedByOS",
+	CodeLocationDisabled:        "LocationDisabled",
+	CodeLocationNotInPool:       "LocationNotInPool",
+	CodeInvalidIPFamily:         "InvalidIPFamily",
+	CodePublicKeyFileUnreadable: "PublicKeyFileUnreadable",
+	CodeConflictingArguments:    "ConflictingArguments",
+	CodeInvalidDuration:         "InvalidDuration",
+	CodeValueOutOfRange:         "ValueOutOfRange",
+
+	CodeMissingAPIKey:         "MissingAPIKey",
+	CodeClientSetupFailed:     "ClientSetupFailed",
+	CodeCapabilityUnavailable: "CapabilityUnavailable",
+
+	CodeAPIRequestFailed:    "APIRequestFailed",
+	CodeAPINotFound:         "APINotFound",
+	CodeAPIRateLimited:      "APIRateLimited",
+	CodeAPIConflict:         "APIConflict",
+	CodeAPIAuthFailed:       "APIAuthFailed",
+	CodeAPIUnavailable:      "APIUnavailable",
+	CodeAPIValidationFailed: "APIValidationFailed",
+
+	CodeServerStatusTimeout:       "ServerStatusTimeout",
+	CodePowerStateTimeout:         "PowerStateTimeout",
+	CodeFinalStateNotRecorded:     "FinalStateNotRecorded",
+	CodeBGPSessionNotFound:        "BGPSessionNotFound",
+	CodeBGPSessionsNotDeleted:     "BGPSessionsNotDeleted",
+	CodeBGPSessionsNotImported:    "BGPSessionsNotImported",
+	CodeStateUpdateFailed:         "StateUpdateFailed",
+	CodeStateEncryptionFailed:     "StateEncryptionFailed",
+	CodeRelocationNotRolledBack:   "RelocationNotRolledBack",
+	CodeRelocatedServerNotDeleted: "RelocatedServerNotDeleted",
+	CodeServerExpired:             "ServerExpired",
+	CodeBGPSessionsNotEstablished: "BGPSessionsNotEstablished",
+	CodeServerIPsChanged:          "ServerIPsChanged",
+	CodeStateUpgradeFailed:        "StateUpgradeFailed",
+	CodeServerBuildStuck:          "ServerBuildStuck",
+	CodeBGPSessionsNotListed:      "BGPSessionsNotListed",
+}
+
+// Name returns the symbolic name of the code, e.g. "InvalidHostname".
+func (c DiagCode) Name() string {
+	return diagCodeNames[c]
+}
+
+func (c DiagCode) String() string {
+	return string(c) + " " + c.Name()
+}
+
+// DiagnosticTranslator rewrites the summary of a diagnostic, e.g. to
+// localize it. It receives the code and the English summary without the
+// code prefix, which is always kept.
+type DiagnosticTranslator func(code DiagCode, summary string) string
+
+var diagTranslator atomic.Pointer[DiagnosticTranslator]
+
+// SetDiagnosticTranslator installs a translator for the diagnostic
+// summaries of every provider in the process, nil removes it. Diagnostics
+// are also produced while validating configuration, before a provider
+// instance is configured, so it can't be set per provider.
+func SetDiagnosticTranslator(t DiagnosticTranslator) {
+	if t == nil {
+		diagTranslator.Store(nil)
+		return
+	}
+	diagTranslator.Store(&t)
+}
+
+// codedSummary prefixes summary with the code, translating it first.
+func codedSummary(code DiagCode, summary string) string {
+	if t := diagTranslator.Load(); t != nil {
+		summary = (*t)(code, summary)
+	}
+	return "[" + string(code) + "] " + summary
+}
+
+// codedError attaches a DiagCode to an error, so diagnostics created from
+// it, including by the SDK itself, carry the code.
+type codedError struct {
+	code DiagCode
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return codedSummary(e.code, e.err.Error())
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+// codedErrorf is fmt.Errorf returning an error that carries code.
+func codedErrorf(code DiagCode, format string, args ...any) error {
+	return &codedError{code: code, err: fmt.Errorf(format, args...)}
+}
+
+// errorDiag is diag.Errorf with a coded summary.
+func errorDiag(code DiagCode, format string, args ...any) diag.Diagnostics {
+	return diag.Diagnostics{codedDiag(diag.Error, code, fmt.Sprintf(format, args...), "")}
+}
+
+// codedDiag builds a single diagnostic with a coded summary.
+func codedDiag(severity diag.Severity, code DiagCode, summary, detail string) diag.Diagnostic {
+	return diag.Diagnostic{
+		Severity: severity,
+		Summary:  codedSummary(code, summary),
+		Detail:   detail,
+	}
+}
+
+// errDiag converts err into diagnostics. Errors without a code of their own
+// are reported with fallback.
+func errDiag(fallback DiagCode, err error) diag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return diag.Diagnostics{{Severity: diag.Error, Summary: err.Error()}}
+	}
+	return diag.Diagnostics{codedDiag(diag.Error, fallback, err.Error(), "")}
+}
+
+// withDiagCode adds code to the diagnostics returned by a validation
+// function, such as the ones of the SDK validation package.
+func withDiagCode(code DiagCode, f schema.SchemaValidateDiagFunc) schema.SchemaValidateDiagFunc {
+	return func(i any, path cty.Path) diag.Diagnostics {
+		diags := f(i, path)
+		for i := range diags {
+			diags[i].Summary = codedSummary(code, diags[i].Summary)
+		}
+		return diags
+	}
+}
+
+// frameworkDiags converts diagnostics of the shared SDK v2 helpers, such as
+// apiErrorDiag, for resources implemented with the Plugin Framework.
+func frameworkDiags(diags diag.Diagnostics) fwdiag.Diagnostics {
+	var out fwdiag.Diagnostics
+	for _, d := range diags {
+		if d.Severity == diag.Warning {
+			out.AddWarning(d.Summary, d.Detail)
+		} else {
+			out.AddError(d.Summary, d.Detail)
+		}
+	}
+	return out
+}
+
+// sdkDiags converts diagnostics of the Plugin Framework for the shared SDK
+// v2 helpers, the inverse of frameworkDiags.
+func sdkDiags(diags fwdiag.Diagnostics) diag.Diagnostics {
+	var out diag.Diagnostics
+	for _, d := range diags {
+		severity := diag.Error
+		if d.Severity() == fwdiag.SeverityWarning {
+			severity = diag.Warning
+		}
+		out = append(out, diag.Diagnostic{Severity: severity, Summary: d.Summary(), Detail: d.Detail()})
+	}
+	return out
+}
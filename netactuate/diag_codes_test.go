@@ -0,0 +1,133 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagCodes(t *testing.T) {
+	codeFormat := regexp.MustCompile(`^NA[1-9]\d{3}$`)
+	names := map[string]DiagCode{}
+
+	for code, name := range diagCodeNames {
+		assert.Regexp(t, codeFormat, string(code))
+		assert.NotEmpty(t, name)
+		if other, ok := names[name]; ok {
+			t.Errorf("%s and %s are both named %s", code, other, name)
+		}
+		names[name] = code
+	}
+
+	assert.Equal(t, "NA1001 InvalidHostname", CodeInvalidHostname.String())
+}
+
+func TestCodedErrors(t *testing.T) {
+	err := codedErrorf(CodeInvalidResourceID, "invalid resource ID %q", "abc")
+	assert.EqualError(t, err, `[NA1002] invalid resource ID "abc"`)
+
+	wrapped := errDiag(CodeStateUpdateFailed, errors.Join(err))
+	require.Len(t, wrapped, 1)
+	assert.Equal(t, `[NA1002] invalid resource ID "abc"`, wrapped[0].Summary)
+
+	plain := errDiag(CodeStateUpdateFailed, errors.New("boom"))
+	require.Len(t, plain, 1)
+	assert.Equal(t, diag.Error, plain[0].Severity)
+	assert.Equal(t, "[NA3007] boom", plain[0].Summary)
+
+	assert.Nil(t, errDiag(CodeStateUpdateFailed, nil))
+}
+
+// TestDiagnosticsAreCoded walks the error paths of the resources, data
+// sources and validators not reporting API errors, whose summaries must
+// start with a code as well.
+func TestDiagnosticsAreCoded(t *testing.T) {
+	ctx := context.Background()
+	coded := regexp.MustCompile(`^\[NA[1-9]\d{3}\] `)
+
+	// A client of an API generation without any capability.
+	unsupported := &Client{ClientInterface: NewFakeClient(), apiVersion: "v0", clock: newFakeClock()}
+	sessions := func() *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, resourceBGPSessions().Schema, map[string]any{"mbpkgid": 1234, "group_id": 12})
+	}
+	invalidID := sessions()
+	invalidID.SetId("web01")
+
+	_, s := newTestServerResource(t, NewFakeClient())
+	m := testServerModel("C-1001")
+	m.Location = locationValue{StringValue: types.StringValue("SJC")}
+	config := tfsdk.Config{Schema: s.Schema, Raw: testPlan(t, s, &serverResourceModel{serverBaseModel: m}).Raw}
+	conflicting := &resource.ValidateConfigResponse{}
+	exactlyOneOf(locationKeys...).ValidateResource(ctx, resource.ValidateConfigRequest{Config: config}, conflicting)
+
+	duration := &validator.StringResponse{}
+	durationValidator{}.ValidateString(ctx, validator.StringRequest{Path: path.Root("timeout"), ConfigValue: types.StringValue("soon")}, duration)
+	atLeast := &validator.Int64Response{}
+	int64AtLeastValidator{min: 1}.ValidateInt64(ctx, validator.Int64Request{Path: path.Root("max_retries"), ConfigValue: types.Int64Value(0)}, atLeast)
+
+	tests := map[string]diag.Diagnostics{
+		"bgp_sessions create":   resourceBGPSessionCreate(ctx, sessions(), unsupported),
+		"bgp_sessions read":     resourceBGPSessionRead(ctx, sessions(), unsupported),
+		"bgp_sessions update":   resourceBGPSessionUpdate(ctx, sessions(), unsupported),
+		"bgp_sessions ID":       resourceBGPSessionRead(ctx, invalidID, newFakeAPIClient(NewFakeClient())),
+		"bgpsessions":           dataSourceBGPSessionsRead(ctx, schema.TestResourceDataRaw(t, dataSourceBGPSessions().Schema, map[string]any{"mbpkgid": 1234}), unsupported),
+		"bgp_session_state":     dataSourceBGPSessionStateRead(ctx, schema.TestResourceDataRaw(t, dataSourceBGPSessionState().Schema, map[string]any{"mbpkgid": 1234}), unsupported),
+		"servers":               dataSourceServersRead(ctx, schema.TestResourceDataRaw(t, dataSourceServers().Schema, map[string]any{"cloud_pool": "Default"}), unsupported),
+		"cloud_pool_servers":    dataSourceCloudPoolServersRead(ctx, schema.TestResourceDataRaw(t, dataSourceCloudPoolServers().Schema, map[string]any{"cloud_pool": "Default"}), unsupported),
+		"conflicting arguments": sdkDiags(conflicting.Diagnostics),
+		"duration":              sdkDiags(duration.Diagnostics),
+		"at least":              sdkDiags(atLeast.Diagnostics),
+	}
+	for name, diags := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.True(t, diags.HasError())
+			for _, d := range diags {
+				assert.Regexp(t, coded, d.Summary)
+			}
+		})
+	}
+}
+
+func TestWithDiagCode(t *testing.T) {
+	validate := withDiagCode(CodeInvalidPowerState,
+		validation.ToDiagFunc(validation.StringInSlice(powerStates, false)))
+
+	assert.Empty(t, validate("on", cty.Path{}))
+
+	diags := validate("sideways", cty.Path{})
+	require.Len(t, diags, 1)
+	assert.Regexp(t, `^\[NA1009\] expected .* to be one of`, diags[0].Summary)
+}
+
+func TestSetDiagnosticTranslator(t *testing.T) {
+	t.Cleanup(func() { SetDiagnosticTranslator(nil) })
+
+	SetDiagnosticTranslator(func(code DiagCode, summary string) string {
+		if code == CodeInvalidHostname {
+			return "ungültiger Hostname"
+		}
+		return summary
+	})
+
+	assert.Equal(t, "[NA1001] ungültiger Hostname",
+		errorDiag(CodeInvalidHostname, "%q is not a valid hostname", "x")[0].Summary)
+	assert.Equal(t, "[NA1006] Please provide a location or location_id",
+		errorDiag(CodeLocationRequired, "Please provide a location or location_id")[0].Summary)
+
+	SetDiagnosticTranslator(nil)
+	assert.Equal(t, `[NA1001] "x" is not a valid hostname`,
+		errorDiag(CodeInvalidHostname, "%q is not a valid hostname", "x")[0].Summary)
+}
@@ -0,0 +1,200 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// ErrorKind classifies errors returned by the NetActuate API.
+type ErrorKind int
+
+const (
+	ErrorKindUnknown ErrorKind = iota
+	ErrorKindNotFound
+	ErrorKindRateLimited
+	ErrorKindConflict
+	ErrorKindAuthFailure
+	ErrorKindTransient
+	ErrorKindValidation
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindNotFound:
+		return "NotFound"
+	case ErrorKindRateLimited:
+		return "RateLimited"
+	case ErrorKindConflict:
+		return "Conflict"
+	case ErrorKindAuthFailure:
+		return "AuthFailure"
+	case ErrorKindTransient:
+		return "Transient"
+	case ErrorKindValidation:
+		return "Validation"
+	default:
+		return "Unknown"
+	}
+}
+
+// APIError is a classified error returned by the gona client.
+type APIError struct {
+	Kind       ErrorKind
+	StatusCode int
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// gona reports failed calls as "... code <HTTP status> / <API code>, response: ...".
+	apiErrorCodeRegex = regexp.MustCompile(`code (\d+) / (\d+)`)
+	apiKeyParamRegex  = regexp.MustCompile(`([?&]key=)[^&\s"]+`)
+)
+
+// ClassifyError wraps err into an APIError describing what kind of failure
+// it represents. It returns nil for a nil error.
+func ClassifyError(err error) *APIError {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	apiErr = &APIError{Kind: ErrorKindUnknown, Err: err}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return apiErr
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		apiErr.Kind = ErrorKindTransient
+		return apiErr
+	}
+
+	if match := apiErrorCodeRegex.FindStringSubmatch(err.Error()); match != nil {
+		httpStatus, _ := strconv.Atoi(match[1])
+		apiCode, _ := strconv.Atoi(match[2])
+
+		apiErr.StatusCode = httpStatus
+		if httpStatus == http.StatusOK {
+			apiErr.StatusCode = apiCode
+		}
+		apiErr.Kind = kindFromStatus(apiErr.StatusCode)
+	}
+
+	return apiErr
+}
+
+func kindFromStatus(status int) ErrorKind {
+	switch status {
+	case http.StatusNotFound, http.StatusGone:
+		return ErrorKindNotFound
+	case http.StatusTooManyRequests:
+		return ErrorKindRateLimited
+	case http.StatusConflict:
+		return ErrorKindConflict
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrorKindValidation
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrorKindAuthFailure
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrorKindTransient
+	default:
+		return ErrorKindUnknown
+	}
+}
+
+func errorKind(err error) ErrorKind {
+	if err == nil {
+		return ErrorKindUnknown
+	}
+	return ClassifyError(err).Kind
+}
+
+// IsNotFound reports whether err means the requested object doesn't exist.
+func IsNotFound(err error) bool {
+	return errorKind(err) == ErrorKindNotFound
+}
+
+// removedOutOfBand reports whether err means the object read by a resource
+// no longer exists, e.g. because it was deleted in the portal. The read then
+// removes the resource from state, so the next plan recreates it instead of
+// failing the refresh. gona doesn't return an error for unknown servers,
+// their reads check serverRemoved as well.
+func removedOutOfBand(ctx context.Context, err error, object string) bool {
+	if !IsNotFound(err) {
+		return false
+	}
+	tflog.Warn(ctx, "NetActuate object not found, removing it from state", map[string]any{
+		"object": object,
+		"error":  redactAPIKey(err.Error()),
+	})
+	return true
+}
+
+// IsRetryable reports whether the failed call may succeed if repeated.
+func IsRetryable(err error) bool {
+	kind := errorKind(err)
+	return kind == ErrorKindTransient || kind == ErrorKindRateLimited
+}
+
+// apiErrorDiag renders err as diagnostics with a summary describing its
+// kind. The API key is redacted from the request URL gona includes in its
+// error messages.
+func apiErrorDiag(err error) diag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+
+	apiErr := ClassifyError(err)
+	detail := redactAPIKey(err.Error())
+
+	var (
+		code    DiagCode
+		summary string
+	)
+	switch apiErr.Kind {
+	case ErrorKindNotFound:
+		code, summary = CodeAPINotFound, "NetActuate API object not found"
+	case ErrorKindRateLimited:
+		code, summary = CodeAPIRateLimited, "NetActuate API rate limit exceeded"
+		detail += "\n\nThe request was retried up to max_retries times. Raise max_retries or retry_wait_max, or run Terraform with a lower -parallelism."
+	case ErrorKindConflict:
+		code, summary = CodeAPIConflict, "NetActuate API request conflicts with the current state"
+	case ErrorKindAuthFailure:
+		code, summary = CodeAPIAuthFailed, "NetActuate API authentication failed"
+		detail += "\n\nCheck that the api_key provider setting or NETACTUATE_API_KEY environment variable holds a valid key."
+	case ErrorKindTransient:
+		code, summary = CodeAPIUnavailable, "NetActuate API is temporarily unavailable"
+		detail += "\n\nRetry the operation later."
+	case ErrorKindValidation:
+		code, summary = CodeAPIValidationFailed, "NetActuate API rejected the request"
+		detail += "\n\nCheck the arguments named in the response."
+	default:
+		code, summary = CodeAPIRequestFailed, "NetActuate API request failed"
+	}
+
+	return diag.Diagnostics{codedDiag(diag.Error, code, summary, detail)}
+}
+
+func redactAPIKey(s string) string {
+	return apiKeyParamRegex.ReplaceAllString(s, "${1}REDACTED")
+}
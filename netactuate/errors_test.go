@@ -0,0 +1,79 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/stretchr/testify/assert"
+)
+
+func gonaError(httpStatus, apiCode int) error {
+	return fmt.Errorf("got an error response on GET https://vapi2.netactuate.com/api/cloud/server?mbpkgid=1&key=s3cr3t: code %d / %d, response: failed / <nil>", httpStatus, apiCode)
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		desc   string
+		err    error
+		kind   ErrorKind
+		status int
+	}{
+		{"not found", gonaError(404, 404), ErrorKindNotFound, 404},
+		{"not found in API code", gonaError(200, 404), ErrorKindNotFound, 404},
+		{"rate limited", gonaError(429, 0), ErrorKindRateLimited, 429},
+		{"conflict", gonaError(409, 409), ErrorKindConflict, 409},
+		{"unauthorized", gonaError(401, 401), ErrorKindAuthFailure, 401},
+		{"forbidden", gonaError(403, 0), ErrorKindAuthFailure, 403},
+		{"bad gateway", gonaError(502, 0), ErrorKindTransient, 502},
+		{"unavailable", gonaError(503, 503), ErrorKindTransient, 503},
+		{"validation", fmt.Errorf("got an ERROR response on POST https://vapi2.netactuate.com/api/cloud/server/buy_build?key=s3cr3t: code 422 / 422, response: invalid / plan: required, "), ErrorKindValidation, 422},
+		{"bad request", gonaError(400, 400), ErrorKindValidation, 400},
+		{"wrapped", fmt.Errorf("posting data: %w", gonaError(429, 429)), ErrorKindRateLimited, 429},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, ErrorKindTransient, 0},
+		{"cancelled", context.Canceled, ErrorKindUnknown, 0},
+		{"unparseable", errors.New("could not unmarshal response"), ErrorKindUnknown, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			apiErr := ClassifyError(tt.err)
+			assert.Equal(t, tt.kind, apiErr.Kind, "kind should be %s", tt.kind)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.ErrorIs(t, apiErr, tt.err)
+		})
+	}
+
+	assert.Nil(t, ClassifyError(nil))
+}
+
+func TestErrorPredicates(t *testing.T) {
+	assert.True(t, IsNotFound(gonaError(404, 404)))
+	assert.False(t, IsNotFound(gonaError(500, 500)))
+	assert.False(t, IsNotFound(nil))
+
+	assert.True(t, IsRetryable(gonaError(429, 429)))
+	assert.True(t, IsRetryable(gonaError(504, 0)))
+	assert.False(t, IsRetryable(gonaError(401, 401)))
+	assert.False(t, IsRetryable(nil))
+}
+
+func TestAPIErrorDiag(t *testing.T) {
+	diags := apiErrorDiag(gonaError(401, 401))
+
+	assert.Len(t, diags, 1)
+	assert.Equal(t, diag.Error, diags[0].Severity)
+	assert.Equal(t, "[NA2005] NetActuate API authentication failed", diags[0].Summary)
+	assert.NotContains(t, diags[0].Detail, "s3cr3t", "API key must be redacted")
+	assert.Contains(t, diags[0].Detail, "key=REDACTED")
+
+	validation := apiErrorDiag(gonaError(422, 422))
+	assert.Equal(t, "[NA2007] NetActuate API rejected the request", validation[0].Summary)
+	assert.Contains(t, validation[0].Detail, "Check the arguments named in the response")
+
+	assert.Equal(t, "[NA2001] NetActuate API request failed", apiErrorDiag(errors.New("boom"))[0].Summary)
+	assert.Nil(t, apiErrorDiag(nil))
+}
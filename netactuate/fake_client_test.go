@@ -0,0 +1,765 @@
+package netactuate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ ClientInterface = (*FakeClient)(nil)
+
+// FakeClient is an in-memory ClientInterface modelling just enough of the
+// NetActuate API to exercise resource logic without HTTP. Servers build
+// instantly unless BuildDelaySteps is set, and billing contracts have to be
+// registered with AddContract before servers can be billed against them.
+type FakeClient struct {
+	mu sync.Mutex
+	fakeState
+
+	// BGPSessionState is the state of created BGP sessions, Established
+	// when empty.
+	BGPSessionState string
+
+	// BuildDelaySteps is the number of GetServer calls returning a server
+	// being built as BUILDING and not installed, before it is RUNNING.
+	BuildDelaySteps int
+
+	// SessionListDelaySteps is the number of GetBGPSessions calls listing
+	// no sessions of a server right after sessions were created for it.
+	SessionListDelaySteps int
+}
+
+// fakeState is the data of a FakeClient, which Snapshot copies.
+type fakeState struct {
+	locations []gona.Location
+	oses      []gona.OS
+	plans     []gona.Plan
+	contracts map[string]bool
+	// poolLocations are the IDs of the locations of a cloud pool, all
+	// locations when a pool has none.
+	poolLocations map[gona.CloudPool][]int
+
+	servers  map[int]gona.Server
+	ips      map[int]gona.IPs
+	sshKeys  map[int]gona.SSHKey
+	sessions map[int]*gona.BGPSession
+	// sessionServer maps BGP session IDs to the server they belong to.
+	sessionServer map[int]int
+
+	// building counts the GetServer calls left until a server is built.
+	building map[int]int
+	// unlisted counts the GetBGPSessions calls left until a server's
+	// created sessions are listed.
+	unlisted map[int]int
+
+	nextID       int
+	lastServerID int
+	calls        []Call
+}
+
+// NewFakeClient returns a FakeClient seeded with a few locations, images and
+// plans, and without any servers, keys, sessions or contracts.
+func NewFakeClient() *FakeClient {
+	return &FakeClient{fakeState: fakeState{
+		locations: []gona.Location{
+			{ID: 3, Name: "Amsterdam, NL", IATACode: "AMS", Continent: "Europe", Flag: "nl"},
+			{ID: 12, Name: "Frankfurt, DE", IATACode: "FRA", Continent: "Europe", Flag: "de"},
+			{ID: 1, Name: "Ashburn, VA", IATACode: "IAD", Continent: "North America", Flag: "us"},
+			{ID: 40, Name: "Sydney, AU", IATACode: "SYD", Continent: "Oceania", Flag: "au", Disabled: 1},
+		},
+		oses: []gona.OS{
+			{ID: 1000, Os: "Ubuntu 22.04 LTS x64", Type: "linux", Subtype: "ubuntu", Bits: "64", Tech: "kvm"},
+			{ID: 1200, Os: "Ubuntu 24.04 LTS x64", Type: "linux", Subtype: "ubuntu", Bits: "64", Tech: "kvm"},
+			{ID: 800, Os: "Debian 12 x64", Type: "linux", Subtype: "debian", Bits: "64", Tech: "kvm"},
+			{ID: 700, Os: "Windows Server 2022", Type: "windows", Subtype: "windows", Bits: "64", Tech: "kvm"},
+		},
+		plans: []gona.Plan{
+			{ID: 5, Name: "VR1x1x25", RAM: "1024", Disk: "25", Available: "1"},
+			{ID: 6, Name: "VR2x2x50", RAM: "2048", Disk: "50", Available: "1"},
+		},
+		contracts:     make(map[string]bool),
+		servers:       make(map[int]gona.Server),
+		ips:           make(map[int]gona.IPs),
+		sshKeys:       make(map[int]gona.SSHKey),
+		sessions:      make(map[int]*gona.BGPSession),
+		sessionServer: make(map[int]int),
+		building:      make(map[int]int),
+		unlisted:      make(map[int]int),
+		nextID:        100,
+	}}
+}
+
+// newFakeAPIClient wraps f into a provider client polling on a fake clock.
+func newFakeAPIClient(f *FakeClient) *Client {
+	return &Client{
+		ClientInterface: f,
+		apiVersion:      DefaultAPIVersion,
+		clock:           newFakeClock(),
+	}
+}
+
+// AddContract registers a billing contract servers can be built against.
+func (f *FakeClient) AddContract(id string) *FakeClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.contracts[id] = true
+	return f
+}
+
+// AddServer stores server with its primary addresses, assigning it an ID
+// when it has none, see LastServerID.
+func (f *FakeClient) AddServer(server gona.Server) *FakeClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if server.ID == 0 {
+		server.ID = f.newID()
+	}
+	f.servers[server.ID] = server
+
+	var ips gona.IPs
+	if server.PrimaryIPv4 != "" {
+		ips.IPv4 = []gona.IP{{ID: server.ID, Primary: 1, IP: server.PrimaryIPv4}}
+	}
+	if server.PrimaryIPv6 != "" {
+		ips.IPv6 = []gona.IP{{ID: server.ID, Primary: 1, IP: server.PrimaryIPv6}}
+	}
+	f.ips[server.ID] = ips
+	f.lastServerID = server.ID
+
+	return f
+}
+
+// LastServerID returns the ID of the server added last with AddServer.
+func (f *FakeClient) LastServerID() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.lastServerID
+}
+
+// SetIPs replaces the addresses assigned to a server, e.g. to add addresses
+// outside of Terraform.
+func (f *FakeClient) SetIPs(id int, ips gona.IPs) *FakeClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.ips[id] = ips
+	return f
+}
+
+// SetPoolLocations limits the locations of a cloud pool to the ones with
+// the IDs.
+func (f *FakeClient) SetPoolLocations(pool gona.CloudPool, ids ...int) *FakeClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.poolLocations == nil {
+		f.poolLocations = make(map[gona.CloudPool][]int)
+	}
+	f.poolLocations[pool] = ids
+	return f
+}
+
+// FakeSnapshot is the state of a FakeClient at the time of a Snapshot. It
+// can be restored any number of times, also concurrently into different
+// clients, e.g. by parallel subtests branching from a common setup.
+type FakeSnapshot struct {
+	state fakeState
+}
+
+// Snapshot returns the current servers, keys, sessions, contracts and
+// calls of f.
+func (f *FakeClient) Snapshot() *FakeSnapshot {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return &FakeSnapshot{state: f.fakeState.clone()}
+}
+
+// Restore replaces the state of f with the snapshot, which may be of
+// another client.
+func (f *FakeClient) Restore(snapshot *FakeSnapshot) *FakeClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.fakeState = snapshot.state.clone()
+	return f
+}
+
+// clone deep copies the state.
+func (s fakeState) clone() fakeState {
+	c := s
+	c.locations = slices.Clone(s.locations)
+	c.oses = slices.Clone(s.oses)
+	c.plans = slices.Clone(s.plans)
+	c.contracts = maps.Clone(s.contracts)
+	c.poolLocations = make(map[gona.CloudPool][]int, len(s.poolLocations))
+	for pool, ids := range s.poolLocations {
+		c.poolLocations[pool] = slices.Clone(ids)
+	}
+	c.servers = maps.Clone(s.servers)
+	c.ips = make(map[int]gona.IPs, len(s.ips))
+	for id, ips := range s.ips {
+		c.ips[id] = gona.IPs{IPv4: slices.Clone(ips.IPv4), IPv6: slices.Clone(ips.IPv6)}
+	}
+	c.sshKeys = maps.Clone(s.sshKeys)
+	c.sessions = make(map[int]*gona.BGPSession, len(s.sessions))
+	for id, session := range s.sessions {
+		session := *session
+		session.Prefixes = slices.Clone(session.Prefixes)
+		c.sessions[id] = &session
+	}
+	c.sessionServer = maps.Clone(s.sessionServer)
+	c.building = maps.Clone(s.building)
+	c.unlisted = maps.Clone(s.unlisted)
+	c.calls = slices.Clone(s.calls)
+	return c
+}
+
+// Server returns the stored state of a server.
+func (f *FakeClient) Server(id int) (gona.Server, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	server, ok := f.servers[id]
+	return server, ok
+}
+
+// Calls returns the API calls made so far, with the arguments identifying
+// what they were made for, e.g. the ID and cancelBilling of DeleteServer.
+func (f *FakeClient) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.calls)
+}
+
+// CallsTo returns the calls made so far to the method.
+func (f *FakeClient) CallsTo(method string) []Call {
+	return slices.DeleteFunc(f.Calls(), func(call Call) bool {
+		return call.Method != method
+	})
+}
+
+// GetCalls returns the API calls made so far formatted as strings, e.g.
+// "GetServer(100)".
+func (f *FakeClient) GetCalls() []string {
+	calls := f.Calls()
+	strCalls := make([]string, len(calls))
+	for i, call := range calls {
+		strArgs := make([]string, len(call.Args))
+		for j, arg := range call.Args {
+			strArgs[j] = fmt.Sprint(arg)
+		}
+		strCalls[i] = call.Method + "(" + strings.Join(strArgs, ", ") + ")"
+	}
+	return strCalls
+}
+
+func (f *FakeClient) record(method string, args ...any) {
+	f.calls = append(f.calls, Call{Method: method, Args: args})
+}
+
+func (f *FakeClient) newID() int {
+	f.nextID++
+	return f.nextID
+}
+
+func fakeAPIError(status int, format string, args ...any) error {
+	return &APIError{
+		Kind:       kindFromStatus(status),
+		StatusCode: status,
+		Err:        fmt.Errorf(format, args...),
+	}
+}
+
+func (f *FakeClient) GetServers(_ context.Context) ([]gona.Server, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetServers")
+
+	servers := slices.Collect(maps.Values(f.servers))
+	slices.SortFunc(servers, func(a, b gona.Server) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return servers, nil
+}
+
+func (f *FakeClient) GetServer(_ context.Context, id int) (gona.Server, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetServer", id)
+
+	server, ok := f.servers[id]
+	if !ok {
+		return gona.Server{}, fakeAPIError(http.StatusNotFound, "server %d not found", id)
+	}
+	if steps, ok := f.building[id]; ok {
+		if steps > 0 {
+			f.building[id] = steps - 1
+			return server, nil
+		}
+		delete(f.building, id)
+		server.ServerStatus = "RUNNING"
+		server.PowerStatus = "RUNNING"
+		server.Installed = 1
+		f.servers[id] = server
+	}
+	return server, nil
+}
+
+func (f *FakeClient) CreateServer(_ context.Context, r *gona.CreateServerRequest) (gona.ServerBuild, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("CreateServer", r.FQDN)
+
+	server := gona.Server{ID: f.newID()}
+	if err := f.build(&server, r.Plan, r.Location, r.Image, r.FQDN, r.PackageBilling, r.PackageBillingContractId); err != nil {
+		return gona.ServerBuild{}, err
+	}
+	server.CloudPool = r.CloudPool.Name()
+
+	f.servers[server.ID] = server
+	f.ips[server.ID] = gona.IPs{
+		IPv4: []gona.IP{{ID: server.ID, Primary: 1, IP: server.PrimaryIPv4}},
+		IPv6: []gona.IP{{ID: server.ID, Primary: 1, IP: server.PrimaryIPv6}},
+	}
+
+	return gona.ServerBuild{ServerID: server.ID, Status: "ok", Build: f.newID()}, nil
+}
+
+func (f *FakeClient) BuildServer(_ context.Context, id int, r *gona.BuildServerRequest) (gona.ServerBuild, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("BuildServer", id, r.FQDN)
+
+	server, ok := f.servers[id]
+	if !ok {
+		return gona.ServerBuild{}, fakeAPIError(http.StatusNotFound, "server %d not found", id)
+	}
+	if err := f.build(&server, r.Plan, r.Location, r.Image, r.FQDN, r.PackageBilling, r.PackageBillingContractId); err != nil {
+		return gona.ServerBuild{}, err
+	}
+	f.servers[id] = server
+
+	return gona.ServerBuild{ServerID: id, Status: "ok", Build: f.newID()}, nil
+}
+
+// build validates the build parameters like the API does and applies them
+// to server, leaving it RUNNING, or BUILDING for BuildDelaySteps GetServer
+// calls.
+func (f *FakeClient) build(server *gona.Server, plan string, locationID, imageID int, fqdn, billing, contractID string) error {
+	i := slices.IndexFunc(f.plans, func(p gona.Plan) bool { return p.Name == plan })
+	if i < 0 {
+		return fakeAPIError(http.StatusUnprocessableEntity, "invalid plan %q", plan)
+	}
+	planID := f.plans[i].ID
+
+	i = slices.IndexFunc(f.locations, func(l gona.Location) bool { return l.ID == locationID })
+	if i < 0 {
+		return fakeAPIError(http.StatusUnprocessableEntity, "invalid location %d", locationID)
+	}
+	location := f.locations[i]
+
+	i = slices.IndexFunc(f.oses, func(os gona.OS) bool { return os.ID == imageID })
+	if i < 0 {
+		return fakeAPIError(http.StatusUnprocessableEntity, "invalid image %d", imageID)
+	}
+	image := f.oses[i]
+
+	if contractID != "" && !f.contracts[contractID] {
+		return fakeAPIError(http.StatusUnprocessableEntity, "invalid package_billing_contract_id %q", contractID)
+	}
+
+	server.Name = fqdn
+	server.Package = plan
+	server.PlanID = planID
+	server.Location = location.Name
+	server.LocationID = location.ID
+	server.OS = image.Os
+	server.OSID = image.ID
+	server.PackageBilling = billing
+	server.PackageBillingContractId = contractID
+	server.PrimaryIPv4 = fmt.Sprintf("192.0.2.%d", server.ID%256)
+	server.PrimaryIPv6 = fmt.Sprintf("2001:db8::%x", server.ID)
+	server.ServerStatus = "RUNNING"
+	server.PowerStatus = "RUNNING"
+	server.Installed = 1
+
+	if f.BuildDelaySteps > 0 {
+		server.ServerStatus = "BUILDING"
+		server.PowerStatus = "STOPPED"
+		server.Installed = 0
+		f.building[server.ID] = f.BuildDelaySteps
+	}
+
+	return nil
+}
+
+func (f *FakeClient) DeleteServer(_ context.Context, id int, cancelBilling bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("DeleteServer", id, cancelBilling)
+
+	server, ok := f.servers[id]
+	if !ok {
+		return fakeAPIError(http.StatusNotFound, "server %d not found", id)
+	}
+	delete(f.building, id)
+
+	if cancelBilling {
+		// The API keeps answering for cancelled packages with a blank
+		// status, see wait4Status.
+		f.servers[id] = gona.Server{ID: id}
+		delete(f.ips, id)
+		return nil
+	}
+
+	server.ServerStatus = "TERMINATED"
+	server.PowerStatus = "STOPPED"
+	server.Installed = 0
+	f.servers[id] = server
+	return nil
+}
+
+func (f *FakeClient) UnlinkServer(_ context.Context, id int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("UnlinkServer", id)
+
+	if _, ok := f.servers[id]; !ok {
+		return fakeAPIError(http.StatusNotFound, "server %d not found", id)
+	}
+	return nil
+}
+
+func (f *FakeClient) StartServer(_ context.Context, id int) error {
+	return f.setPowerStatus("StartServer", id, "RUNNING")
+}
+
+func (f *FakeClient) StopServer(_ context.Context, id int) error {
+	return f.setPowerStatus("StopServer", id, "STOPPED")
+}
+
+func (f *FakeClient) setPowerStatus(method string, id int, status string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record(method, id)
+
+	server, ok := f.servers[id]
+	if !ok {
+		return fakeAPIError(http.StatusNotFound, "server %d not found", id)
+	}
+	server.PowerStatus = status
+	f.servers[id] = server
+	return nil
+}
+
+func (f *FakeClient) GetIPs(_ context.Context, mbPkgID int) (gona.IPs, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetIPs", mbPkgID)
+
+	ips, ok := f.ips[mbPkgID]
+	if !ok {
+		return gona.IPs{}, fakeAPIError(http.StatusNotFound, "server %d not found", mbPkgID)
+	}
+	return ips, nil
+}
+
+func (f *FakeClient) GetLocations(_ context.Context) ([]gona.Location, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetLocations")
+
+	return slices.Clone(f.locations), nil
+}
+
+func (f *FakeClient) GetLocationForPool(_ context.Context, pool gona.CloudPool) ([]gona.Location, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetLocationForPool", pool.Name())
+
+	ids, ok := f.poolLocations[pool]
+	if !ok {
+		return slices.Clone(f.locations), nil
+	}
+	return slices.DeleteFunc(slices.Clone(f.locations), func(l gona.Location) bool {
+		return !slices.Contains(ids, l.ID)
+	}), nil
+}
+
+func (f *FakeClient) GetOSs(_ context.Context) ([]gona.OS, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetOSs")
+
+	return slices.Clone(f.oses), nil
+}
+
+func (f *FakeClient) GetPlans(_ context.Context) ([]gona.Plan, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetPlans")
+
+	return slices.Clone(f.plans), nil
+}
+
+func (f *FakeClient) GetPackages(_ context.Context) ([]gona.Package, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetPackages")
+
+	packages := make([]gona.Package, 0, len(f.servers))
+	for _, server := range f.servers {
+		packages = append(packages, fakePackage(server))
+	}
+	slices.SortFunc(packages, func(a, b gona.Package) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return packages, nil
+}
+
+func (f *FakeClient) GetPackage(_ context.Context, id int) (gona.Package, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetPackage", id)
+
+	server, ok := f.servers[id]
+	if !ok {
+		return gona.Package{}, fakeAPIError(http.StatusNotFound, "package %d not found", id)
+	}
+	return fakePackage(server), nil
+}
+
+func fakePackage(server gona.Server) gona.Package {
+	return gona.Package{
+		ID:        server.ID,
+		Status:    "Active",
+		Locked:    "0",
+		PlanName:  server.Package,
+		Installed: server.Installed,
+	}
+}
+
+func (f *FakeClient) GetSSHKeys(_ context.Context) ([]gona.SSHKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetSSHKeys")
+
+	keys := slices.Collect(maps.Values(f.sshKeys))
+	slices.SortFunc(keys, func(a, b gona.SSHKey) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return keys, nil
+}
+
+func (f *FakeClient) GetSSHKey(_ context.Context, id int) (gona.SSHKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetSSHKey", id)
+
+	key, ok := f.sshKeys[id]
+	if !ok {
+		return gona.SSHKey{}, fakeAPIError(http.StatusNotFound, "ssh key %d not found", id)
+	}
+	return key, nil
+}
+
+func (f *FakeClient) CreateSSHKey(_ context.Context, name, key string) (gona.SSHKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("CreateSSHKey", name)
+
+	if strings.TrimSpace(key) == "" {
+		return gona.SSHKey{}, fakeAPIError(http.StatusUnprocessableEntity, "ssh_key is required")
+	}
+
+	sshKey := gona.SSHKey{ID: f.newID(), Name: name, Key: key}
+	f.sshKeys[sshKey.ID] = sshKey
+	return sshKey, nil
+}
+
+func (f *FakeClient) DeleteSSHKey(_ context.Context, id int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("DeleteSSHKey", id)
+
+	if _, ok := f.sshKeys[id]; !ok {
+		return fakeAPIError(http.StatusNotFound, "ssh key %d not found", id)
+	}
+	delete(f.sshKeys, id)
+	return nil
+}
+
+func (f *FakeClient) GetBGPSession(_ context.Context, id int) (*gona.BGPSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetBGPSession", id)
+
+	session, ok := f.sessions[id]
+	if !ok {
+		return nil, fakeAPIError(http.StatusNotFound, "bgp session %d not found", id)
+	}
+	s := *session
+	return &s, nil
+}
+
+func (f *FakeClient) GetBGPSessions(_ context.Context, mbPkgID int) ([]*gona.BGPSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("GetBGPSessions", mbPkgID)
+
+	if _, ok := f.servers[mbPkgID]; !ok {
+		return nil, fakeAPIError(http.StatusNotFound, "server %d not found", mbPkgID)
+	}
+	if f.unlisted[mbPkgID] > 0 {
+		f.unlisted[mbPkgID]--
+		return nil, nil
+	}
+	return f.serverSessions(mbPkgID), nil
+}
+
+func (f *FakeClient) serverSessions(mbPkgID int) []*gona.BGPSession {
+	var sessions []*gona.BGPSession
+	for id, serverID := range f.sessionServer {
+		if serverID == mbPkgID {
+			s := *f.sessions[id]
+			sessions = append(sessions, &s)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b *gona.BGPSession) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return sessions
+}
+
+// CreateBGPSessions adds the sessions missing for the requested settings:
+// one IPv4 session, an IPv6 one when isIPV6 is set, and a second session per
+// family when redundant is set.
+func (f *FakeClient) CreateBGPSessions(_ context.Context, mbPkgID int, groupID int, isIPV6 bool, redundant bool) (*gona.BGPSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("CreateBGPSessions", mbPkgID, groupID, isIPV6, redundant)
+
+	server, ok := f.servers[mbPkgID]
+	if !ok {
+		return nil, fakeAPIError(http.StatusNotFound, "server %d not found", mbPkgID)
+	}
+	if groupID == 0 {
+		return nil, errors.New("group_id is required")
+	}
+
+	families := []gona.IPType{gona.IPv4}
+	if isIPV6 {
+		families = append(families, gona.IPv6)
+	}
+	perFamily := 1
+	if redundant {
+		perFamily = 2
+	}
+
+	existing := make(map[gona.IPType]int)
+	for _, s := range f.serverSessions(mbPkgID) {
+		if s.GroupID == groupID {
+			existing[gona.IPType(s.ProviderIPType)]++
+		}
+	}
+
+	var first *gona.BGPSession
+	for _, family := range families {
+		for n := existing[family]; n < perFamily; n++ {
+			session := &gona.BGPSession{
+				ID:             f.newID(),
+				GroupID:        groupID,
+				GroupName:      fmt.Sprintf("group-%d", groupID),
+				ProviderIPType: string(family),
+				Location:       server.Location,
+				CustomerAsn:    65000,
+				ProviderAsn:    36236,
+				State:          cmp.Or(f.BGPSessionState, "Established"),
+			}
+			if family == gona.IPv4 {
+				session.CustomerIP = server.PrimaryIPv4
+				session.ProviderPeerIP = fmt.Sprintf("192.0.2.%d", 250-n)
+			} else {
+				session.CustomerIP = server.PrimaryIPv6
+				session.ProviderPeerIP = fmt.Sprintf("2001:db8::%x", 0xfff0+n)
+			}
+
+			f.sessions[session.ID] = session
+			f.sessionServer[session.ID] = mbPkgID
+			if first == nil {
+				first = session
+			}
+		}
+	}
+
+	if first == nil {
+		return nil, fakeAPIError(http.StatusConflict, "sessions for group %d already exist", groupID)
+	}
+	if f.SessionListDelaySteps > 0 {
+		f.unlisted[mbPkgID] = f.SessionListDelaySteps
+	}
+	s := *first
+	return &s, nil
+}
+
+func TestFakeClientSnapshot(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeClient().AddContract("C-1001")
+	id := fake.AddServer(gona.Server{Name: "web01.example.com", ServerStatus: "RUNNING", PowerStatus: "RUNNING", Installed: 1, PrimaryIPv4: "192.0.2.10"}).LastServerID()
+	_, err := fake.CreateBGPSessions(ctx, id, 12, false, false)
+	require.NoError(t, err)
+	snapshot := fake.Snapshot()
+
+	tests := []struct {
+		name   string
+		change func(t *testing.T, c *FakeClient)
+	}{
+		{name: "delete", change: func(t *testing.T, c *FakeClient) {
+			require.NoError(t, c.DeleteServer(ctx, id, true))
+		}},
+		{name: "stop", change: func(t *testing.T, c *FakeClient) {
+			require.NoError(t, c.StopServer(ctx, id))
+		}},
+		{name: "add sessions", change: func(t *testing.T, c *FakeClient) {
+			_, err := c.CreateBGPSessions(ctx, id, 13, false, false)
+			require.NoError(t, err)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := NewFakeClient().Restore(snapshot)
+			tt.change(t, c)
+		})
+	}
+
+	// The subtests changed their own copies only.
+	t.Cleanup(func() {
+		for _, c := range []*FakeClient{fake, NewFakeClient().Restore(snapshot)} {
+			server, ok := c.Server(id)
+			require.True(t, ok)
+			assert.Equal(t, "RUNNING", server.ServerStatus)
+			assert.Equal(t, "RUNNING", server.PowerStatus)
+			sessions, err := c.GetBGPSessions(ctx, id)
+			require.NoError(t, err)
+			assert.Len(t, sessions, 1)
+			assert.Equal(t, []Call{{Method: "CreateBGPSessions", Args: []any{id, 12, false, false}}}, c.CallsTo("CreateBGPSessions"))
+		}
+	})
+}
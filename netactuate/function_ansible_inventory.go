@@ -0,0 +1,194 @@
+package netactuate
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v3"
+)
+
+// inventoryHostVars maps server attributes to the Ansible host variables
+// they are exported as.
+var inventoryHostVars = map[string]string{
+	"id":           "netactuate_id",
+	"location":     "netactuate_location",
+	"plan":         "netactuate_plan",
+	"primary_ipv4": "netactuate_primary_ipv4",
+	"primary_ipv6": "netactuate_primary_ipv6",
+}
+
+var ansibleGroupInvalidChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// AnsibleInventoryFunction implements provider::netactuate::ansible_inventory.
+type AnsibleInventoryFunction struct{}
+
+// NewAnsibleInventoryFunction returns the provider function rendering
+// servers as an Ansible inventory.
+func NewAnsibleInventoryFunction() function.Function {
+	return &AnsibleInventoryFunction{}
+}
+
+func (f *AnsibleInventoryFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "ansible_inventory"
+}
+
+func (f *AnsibleInventoryFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Render servers as an Ansible YAML inventory",
+		Description: "Takes a list or map of `netactuate_server` resources or data sources and returns a YAML " +
+			"inventory. Every server is a host named after its `hostname`, with its primary IPv4 (or IPv6) " +
+			"address as `ansible_host` and its ID, location, plan and addresses as `netactuate_*` variables. " +
+			"Servers are grouped by location.",
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:        "servers",
+				Description: "List or map of servers, e.g. `netactuate_server.web` with `count` or `for_each`",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *AnsibleInventoryFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var input types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &input)
+	if resp.Error != nil {
+		return
+	}
+
+	servers, err := inventoryServers(input.UnderlyingValue())
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	inventory, err := ansibleInventory(servers)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, inventory)
+}
+
+// inventoryServers returns the string attributes of every server in v, a
+// list, set, tuple or map of server objects, or a single server.
+func inventoryServers(v attr.Value) ([]map[string]string, error) {
+	var elements []attr.Value
+	switch v := v.(type) {
+	case types.List:
+		elements = v.Elements()
+	case types.Set:
+		elements = v.Elements()
+	case types.Tuple:
+		elements = v.Elements()
+	case types.Map:
+		for _, e := range v.Elements() {
+			elements = append(elements, e)
+		}
+	case types.Object:
+		if _, ok := v.Attributes()["hostname"]; ok {
+			elements = []attr.Value{v}
+		} else {
+			// A map literal whose values have different types, e.g.
+			// servers with different optional attributes, is an object.
+			for _, e := range v.Attributes() {
+				elements = append(elements, e)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("servers must be a list or map of servers")
+	}
+
+	servers := make([]map[string]string, 0, len(elements))
+	for _, e := range elements {
+		object, ok := e.(types.Object)
+		if !ok {
+			return nil, fmt.Errorf("servers must be a list or map of servers, got an element of type %s", e.Type(context.Background()))
+		}
+
+		server := make(map[string]string)
+		for name, value := range object.Attributes() {
+			if s, ok := value.(types.String); ok && !s.IsNull() {
+				server[name] = s.ValueString()
+			}
+		}
+		if server["hostname"] == "" {
+			return nil, fmt.Errorf("every server must have a hostname")
+		}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+type ansibleGroup struct {
+	Hosts map[string]map[string]string `yaml:"hosts,omitempty"`
+}
+
+type ansibleInventoryRoot struct {
+	Hosts    map[string]map[string]string `yaml:"hosts"`
+	Children map[string]ansibleGroup      `yaml:"children,omitempty"`
+}
+
+// ansibleInventory renders servers as an Ansible YAML inventory, with one
+// group per location.
+func ansibleInventory(servers []map[string]string) (string, error) {
+	root := ansibleInventoryRoot{
+		Hosts:    make(map[string]map[string]string, len(servers)),
+		Children: make(map[string]ansibleGroup),
+	}
+
+	for _, server := range servers {
+		hostname := server["hostname"]
+		if _, ok := root.Hosts[hostname]; ok {
+			return "", fmt.Errorf("hostname %q is used by more than one server", hostname)
+		}
+
+		vars := make(map[string]string)
+		for attribute, name := range inventoryHostVars {
+			if value := server[attribute]; value != "" {
+				vars[name] = value
+			}
+		}
+		if host := cmp.Or(server["primary_ipv4"], server["primary_ipv6"]); host != "" {
+			vars["ansible_host"] = host
+		}
+		root.Hosts[hostname] = vars
+
+		if group := ansibleGroupName(server["location"]); group != "" {
+			if root.Children[group].Hosts == nil {
+				root.Children[group] = ansibleGroup{Hosts: make(map[string]map[string]string)}
+			}
+			root.Children[group].Hosts[hostname] = map[string]string{}
+		}
+	}
+
+	var out strings.Builder
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(map[string]ansibleInventoryRoot{"all": root}); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// ansibleGroupName turns a location name such as "Amsterdam, NL" into a
+// valid Ansible group name, "amsterdam_nl".
+func ansibleGroupName(location string) string {
+	name := ansibleGroupInvalidChars.ReplaceAllString(strings.ToLower(location), "_")
+	name = strings.Trim(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "location_" + name
+	}
+	return name
+}
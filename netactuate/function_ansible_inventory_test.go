@@ -0,0 +1,113 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func inventoryTestServer(hostname, location, ipv4, ipv6 string) types.Object {
+	return types.ObjectValueMust(
+		map[string]attr.Type{
+			"id":           types.StringType,
+			"hostname":     types.StringType,
+			"location":     types.StringType,
+			"location_id":  types.Int64Type,
+			"primary_ipv4": types.StringType,
+			"primary_ipv6": types.StringType,
+		},
+		map[string]attr.Value{
+			"id":           types.StringValue("101"),
+			"hostname":     types.StringValue(hostname),
+			"location":     types.StringValue(location),
+			"location_id":  types.Int64Value(3),
+			"primary_ipv4": types.StringValue(ipv4),
+			"primary_ipv6": types.StringValue(ipv6),
+		},
+	)
+}
+
+func TestAnsibleInventory(t *testing.T) {
+	inventory, err := ansibleInventory([]map[string]string{
+		{"hostname": "web01.example.com", "id": "101", "location": "Amsterdam, NL", "primary_ipv4": "192.0.2.10"},
+		{"hostname": "web02.example.com", "location": "Frankfurt, DE", "primary_ipv6": "2001:db8::10"},
+		{"hostname": "db01.example.com", "location": "Amsterdam, NL"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `all:
+  hosts:
+    db01.example.com:
+      netactuate_location: Amsterdam, NL
+    web01.example.com:
+      ansible_host: 192.0.2.10
+      netactuate_id: "101"
+      netactuate_location: Amsterdam, NL
+      netactuate_primary_ipv4: 192.0.2.10
+    web02.example.com:
+      ansible_host: 2001:db8::10
+      netactuate_location: Frankfurt, DE
+      netactuate_primary_ipv6: 2001:db8::10
+  children:
+    amsterdam_nl:
+      hosts:
+        db01.example.com: {}
+        web01.example.com: {}
+    frankfurt_de:
+      hosts:
+        web02.example.com: {}
+`, inventory)
+
+	_, err = ansibleInventory([]map[string]string{{"hostname": "a"}, {"hostname": "a"}})
+	assert.EqualError(t, err, `hostname "a" is used by more than one server`)
+}
+
+func TestAnsibleGroupName(t *testing.T) {
+	assert.Equal(t, "amsterdam_nl", ansibleGroupName("Amsterdam, NL"))
+	assert.Equal(t, "location_3", ansibleGroupName("3"))
+	assert.Equal(t, "", ansibleGroupName(""))
+}
+
+func TestAnsibleInventoryFunction_Run(t *testing.T) {
+	server := inventoryTestServer("web01.example.com", "Amsterdam, NL", "192.0.2.10", "")
+	servers := []attr.Value{server}
+
+	tests := []struct {
+		desc    string
+		input   attr.Value
+		wantErr string
+	}{
+		{desc: "list", input: types.ListValueMust(server.Type(context.Background()), servers)},
+		{desc: "tuple", input: types.TupleValueMust([]attr.Type{server.Type(context.Background())}, servers)},
+		{desc: "map", input: types.MapValueMust(server.Type(context.Background()), map[string]attr.Value{"web": server})},
+		{desc: "single server", input: server},
+		{desc: "not a server", input: types.StringValue("web01"), wantErr: "servers must be a list or map of servers"},
+		{desc: "no hostname", input: types.ListValueMust(server.Type(context.Background()), []attr.Value{
+			inventoryTestServer("", "", "", ""),
+		}), wantErr: "every server must have a hostname"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			req := function.RunRequest{
+				Arguments: function.NewArgumentsData([]attr.Value{types.DynamicValue(tt.input)}),
+			}
+			resp := &function.RunResponse{
+				Result: function.NewResultData(types.StringUnknown()),
+			}
+
+			NewAnsibleInventoryFunction().Run(context.Background(), req, resp)
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, resp.Error, tt.wantErr)
+				return
+			}
+			require.Nil(t, resp.Error)
+			assert.Contains(t, resp.Result.Value().(types.String).ValueString(), "ansible_host: 192.0.2.10")
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package netactuate
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+const (
+	// asTrans is the placeholder ASN of RFC 6793, it can't be a real peer.
+	asTrans = 23456
+	// maxASN is the highest assignable 4-byte ASN, 4294967295 is reserved
+	// by RFC 7300.
+	maxASN = 4294967294
+)
+
+// ASNFunction implements provider::netactuate::asn.
+type ASNFunction struct{}
+
+// NewASNFunction returns the provider function normalizing ASNs.
+func NewASNFunction() function.Function {
+	return &ASNFunction{}
+}
+
+func (f *ASNFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "asn"
+}
+
+func (f *ASNFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Validate and normalize an autonomous system number",
+		Description: "Accepts an ASN as a plain number (`65000`), with an `AS` prefix (`AS65000`) or in asdot " +
+			"notation (`1.10`), and returns it as a number. Reserved ASNs are rejected.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "asn",
+				Description: "The ASN to normalize",
+			},
+		},
+		Return: function.Int64Return{},
+	}
+}
+
+func (f *ASNFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var input string
+
+	resp.Error = req.Arguments.Get(ctx, &input)
+	if resp.Error != nil {
+		return
+	}
+
+	asn, err := parseASN(input)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, asn)
+}
+
+// parseASN parses an ASN in asplain or asdot notation, optionally prefixed
+// with "AS", and checks that it may be used for a BGP peer.
+func parseASN(s string) (int64, error) {
+	v := strings.TrimSpace(s)
+	if len(v) >= 2 && strings.EqualFold(v[:2], "AS") {
+		v = v[2:]
+	}
+
+	var asn uint64
+	if high, low, ok := strings.Cut(v, "."); ok {
+		h, errHigh := strconv.ParseUint(high, 10, 16)
+		l, errLow := strconv.ParseUint(low, 10, 16)
+		if errHigh != nil || errLow != nil {
+			return 0, codedErrorf(CodeInvalidASN, "invalid ASN %q: asdot notation expects two numbers between 0 and 65535", s)
+		}
+		asn = h<<16 | l
+	} else {
+		var err error
+		if asn, err = strconv.ParseUint(v, 10, 32); err != nil {
+			return 0, codedErrorf(CodeInvalidASN, "invalid ASN %q: expected a number between 1 and %d", s, maxASN)
+		}
+	}
+
+	switch {
+	case asn == 0 || asn > maxASN:
+		return 0, codedErrorf(CodeInvalidASN, "invalid ASN %q: %d is reserved", s, asn)
+	case asn == asTrans:
+		return 0, codedErrorf(CodeInvalidASN, "invalid ASN %q: %d is reserved as AS_TRANS", s, asn)
+	}
+
+	return int64(asn), nil
+}
@@ -0,0 +1,80 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseASN(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "65000", want: 65000},
+		{input: "AS65000", want: 65000},
+		{input: "as36236", want: 36236},
+		{input: " AS 64512", wantErr: true},
+		{input: "4200000000", want: 4200000000},
+		{input: "1.10", want: 65546},
+		{input: "AS65535.65535", wantErr: true},
+		{input: "64086.59904", want: 4200000000},
+		{input: "0", wantErr: true},
+		{input: "23456", wantErr: true},
+		{input: "4294967295", wantErr: true},
+		{input: "4294967296", wantErr: true},
+		{input: "65536.1", wantErr: true},
+		{input: "1.", wantErr: true},
+		{input: "-1", wantErr: true},
+		{input: "AS", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseASN(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestASNFunction_Run(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    types.Int64
+		wantErr string
+	}{
+		{input: "AS65000", want: types.Int64Value(65000)},
+		{input: "0", want: types.Int64Unknown(), wantErr: "0 is reserved"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			req := function.RunRequest{
+				Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(tt.input)}),
+			}
+			resp := &function.RunResponse{
+				Result: function.NewResultData(types.Int64Unknown()),
+			}
+
+			NewASNFunction().Run(context.Background(), req, resp)
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, resp.Error, tt.wantErr)
+			} else {
+				assert.Nil(t, resp.Error)
+			}
+			assert.Equal(t, tt.want, resp.Result.Value())
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package netactuate
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -8,7 +11,13 @@ import (
 func setValue(key string, value any, d *schema.ResourceData, diags *diag.Diagnostics) {
 	err := d.Set(key, value)
 	if err != nil {
-		*diags = append(*diags, diag.Diagnostic{Severity: diag.Error, Summary: err.Error()})
+		*diags = append(*diags, errDiag(CodeStateUpdateFailed, err)...)
+	}
+}
+
+func setValues(values map[string]any, d *schema.ResourceData, diags *diag.Diagnostics) {
+	for key, value := range values {
+		setValue(key, value, d, diags)
 	}
 }
 
@@ -18,3 +27,13 @@ func updateValue(key string, value any, d *schema.ResourceData, diags *diag.Diag
 		setValue(key, value, d, diags)
 	}
 }
+
+// parseResourceID converts a Terraform resource ID (or import ID) into the
+// numeric identifier used by the NetActuate API.
+func parseResourceID(id string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return 0, codedErrorf(CodeInvalidResourceID, "invalid resource ID %q: expected a numeric ID", id)
+	}
+	return n, nil
+}
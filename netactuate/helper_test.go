@@ -0,0 +1,59 @@
+package netactuate
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResourceID(t *testing.T) {
+	tests := []struct {
+		id    string
+		want  int
+		valid bool
+	}{
+		{"12345", 12345, true},
+		{" 42\n", 42, true},
+		{"0", 0, true},
+		{"", 0, false},
+		{"abc", 0, false},
+		{"12/34", 0, false},
+		{"hostname=web01.example.com", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got, err := parseResourceID(tt.id)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func FuzzParseResourceID(f *testing.F) {
+	for _, seed := range []string{"1", "12345", " 7 ", "", "-1", "abc", "1/2", "9223372036854775808"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, id string) {
+		n, err := parseResourceID(id)
+		if err != nil {
+			return
+		}
+
+		// Whatever is accepted must survive a round trip through the
+		// canonical form stored in state.
+		again, err := parseResourceID(strconv.Itoa(n))
+		if err != nil {
+			t.Fatalf("canonical form of %q (%d) was rejected: %v", id, n, err)
+		}
+		if again != n {
+			t.Fatalf("round trip of %q changed value: %d != %d", id, n, again)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = locationType{}
+	_ basetypes.StringValuableWithSemanticEquals = locationValue{}
+)
+
+// locationType is a string naming a location by its code, e.g. "AMS", or
+// its full name starting with the code, e.g. "AMS - Amsterdam, NL". Values
+// naming the same code, in any case, are semantically equal.
+type locationType struct {
+	basetypes.StringType
+}
+
+func (t locationType) String() string {
+	return "locationType"
+}
+
+func (t locationType) Equal(o attr.Type) bool {
+	other, ok := o.(locationType)
+	return ok && t.StringType.Equal(other.StringType)
+}
+
+func (t locationType) ValueType(_ context.Context) attr.Value {
+	return locationValue{}
+}
+
+func (t locationType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return locationValue{StringValue: in}, nil
+}
+
+func (t locationType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	v, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := v.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type %T", v)
+	}
+	return locationValue{StringValue: s}, nil
+}
+
+// locationValue is a value of locationType.
+type locationValue struct {
+	basetypes.StringValue
+}
+
+func newLocationValue(location string) locationValue {
+	return locationValue{StringValue: basetypes.NewStringValue(location)}
+}
+
+func (v locationValue) Type(_ context.Context) attr.Type {
+	return locationType{}
+}
+
+func (v locationValue) Equal(o attr.Value) bool {
+	other, ok := o.(locationValue)
+	return ok && v.StringValue.Equal(other.StringValue)
+}
+
+func (v locationValue) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(locationValue)
+	if !ok {
+		diags.AddError("Semantic Equality Check Error", fmt.Sprintf("Expected locationValue, got %T", newValuable))
+		return false, diags
+	}
+
+	return sameLocation(v.ValueString(), newValue.ValueString()), diags
+}
+
+// locationCode returns the code a location name starts with, e.g. "AMS" for
+// "AMS - Amsterdam, NL".
+func locationCode(name string) string {
+	code, _, _ := strings.Cut(strings.TrimSpace(name), " ")
+	return code
+}
+
+// sameLocation reports whether both location names start with the same
+// code, ignoring case.
+func sameLocation(a, b string) bool {
+	return strings.EqualFold(locationCode(a), locationCode(b))
+}
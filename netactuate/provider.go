@@ -2,71 +2,180 @@ package netactuate
 
 import (
 	"context"
+	"slices"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netactuate/gona/gona"
 )
 
 const (
 	ProviderVersion = "0.4.0-dev"
 )
 
+// Provider attribute descriptions are shared by the SDK v2 and Framework
+// providers, since the muxed provider schemas must be identical.
+const (
+	apiKeyDescription         = "NetActuate API key. Can also be set with NETACTUATE_API_KEY environment variable."
+	apiUrlDescription         = "NetActuate API URL. Optional, defaults to the endpoint of the selected api_version."
+	apiVersionDescription     = "NetActuate API generation to use. Can also be set with NETACTUATE_API_VERSION environment variable. Defaults to \"v2\"."
+	maxRetriesDescription     = "Number of times an API call failing with a rate limit, server or network error is retried. Defaults to 4, 0 disables retries."
+	retryWaitMaxDescription   = "Maximum number of seconds to wait between retries of an API call, the wait grows exponentially up to it. Defaults to 30."
+	requestTimeoutDescription = "Number of seconds an API request may take before it fails and is retried like a network error, for slow links or proxies that hang. Defaults to 0, no timeout."
+)
+
 // Provider returns the SDK v2 provider (legacy)
 // This is kept for backward compatibility during migration
 func Provider() *schema.Provider {
 	return NewSDKProvider(ProviderVersion)
 }
 
+// ProviderOption customizes the providers created by NewSDKProvider,
+// NewFrameworkProvider and NewProviderServer when embedding the provider.
+type ProviderOption func(*providerOptions)
+
+type providerOptions struct {
+	middleware []Middleware
+	api        ClientInterface
+}
+
+// WithMiddleware registers middleware on the API client of every configured
+// provider instance.
+func WithMiddleware(middleware ...Middleware) ProviderOption {
+	return func(o *providerOptions) {
+		o.middleware = append(o.middleware, middleware...)
+	}
+}
+
+// WithClientInterface makes every configured provider instance call api
+// instead of the NetActuate API, e.g. an in-memory backend to run
+// acceptance tests without an account. No api_key is required then.
+func WithClientInterface(api ClientInterface) ProviderOption {
+	return func(o *providerOptions) {
+		o.api = api
+	}
+}
+
+// newClient creates the API client of a configured provider instance.
+func (o providerOptions) newClient(apiKey, apiURL string, version APIVersion) (*Client, error) {
+	client, err := NewClient(apiKey, apiURL, version)
+	if err != nil {
+		return nil, err
+	}
+	if o.api != nil {
+		client.ClientInterface = o.api
+	}
+	return client, nil
+}
+
+func newProviderOptions(opts []ProviderOption) providerOptions {
+	var o providerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // NewSDKProvider creates a new SDK v2 provider instance
 // Used during mux-based migration
-func NewSDKProvider(version string) *schema.Provider {
+func NewSDKProvider(version string, opts ...ProviderOption) *schema.Provider {
+	options := newProviderOptions(opts)
+
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"api_key": {
 				Type:        schema.TypeString,
 				Optional:    true,
+				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("NETACTUATE_API_KEY", nil),
+				Description: apiKeyDescription,
 			},
 			"api_url": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: apiUrlDescription,
+			},
+			"api_version": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("NETACTUATE_API_VERSION", string(DefaultAPIVersion)),
+				Description: apiVersionDescription,
+			},
+			"max_retries": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     DefaultMaxRetries,
+				Description: maxRetriesDescription,
+			},
+			"retry_wait_max": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     int(DefaultRetryWaitMax / time.Second),
+				Description: retryWaitMaxDescription,
+			},
+			"request_timeout": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: requestTimeoutDescription,
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"netactuate_server":       resourceServer(),
-			"netactuate_sshkey":       resourceSshKey(),
 			"netactuate_bgp_sessions": resourceBGPSessions(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"netactuate_server":       dataSourceServer(),
-			"netactuate_sshkey":       dataSourceSshKey(),
-			"netactuate_bgp_sessions": dataSourceBGPSessions(),
+			"netactuate_server":             dataSourceServer(),
+			"netactuate_sshkey":             dataSourceSshKey(),
+			"netactuate_bgp_sessions":       dataSourceBGPSessions(),
+			"netactuate_bgp_session_state":  dataSourceBGPSessionState(),
+			"netactuate_cloud_pool_servers": dataSourceCloudPoolServers(),
+			"netactuate_locations":          dataSourceLocations(),
+			"netactuate_oses":               dataSourceOSes(),
+			"netactuate_servers":            dataSourceServers(),
+			"netactuate_server_status":      dataSourceServerStatus(),
+		},
+		ConfigureContextFunc: func(ctx context.Context, d *schema.ResourceData) (any, diag.Diagnostics) {
+			return providerConfigure(ctx, d, options)
 		},
-		ConfigureContextFunc: providerConfigure,
 	}
 }
 
-func providerConfigure(ctx context.Context, d *schema.ResourceData) (any, diag.Diagnostics) {
+func providerConfigure(ctx context.Context, d *schema.ResourceData, options providerOptions) (any, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
 	apiKey := d.Get("api_key").(string)
 	apiUrl := d.Get("api_url").(string)
+	apiVersion := d.Get("api_version").(string)
 
-	if apiKey == "" {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Unable to create NetActuate API client",
-			Detail: `Unable to find NetActuate API key. It can be set with either NETACTUATE_API_KEY environment
+	if apiKey == "" && options.api == nil {
+		diags = append(diags, codedDiag(diag.Error, CodeMissingAPIKey,
+			"Unable to create NetActuate API client",
+			`Unable to find NetActuate API key. It can be set with either NETACTUATE_API_KEY environment
 variable or 'api_key' property`,
-		})
+		))
 		return nil, diags
 	}
 
-	if apiUrl == "" {
-		return gona.NewClient(apiKey), nil
-	} else {
-		return gona.NewClientCustom(apiKey, apiUrl), nil
+	client, err := options.newClient(apiKey, apiUrl, APIVersion(apiVersion))
+	if err != nil {
+		return nil, diag.Diagnostics{codedDiag(diag.Error, CodeClientSetupFailed,
+			"Unable to create NetActuate API client",
+			err.Error(),
+		)}
+	}
+	retry, err := newRetryMiddleware(d.Get("max_retries").(int), d.Get("retry_wait_max").(int))
+	if err != nil {
+		return nil, errDiag(CodeClientSetupFailed, err)
+	}
+	timeout, err := newTimeoutMiddleware(d.Get("request_timeout").(int))
+	if err != nil {
+		return nil, errDiag(CodeClientSetupFailed, err)
 	}
+	// Retries run innermost, so middleware sees every call once, while
+	// every attempt is logged and limited by the timeout. Cached metadata
+	// is served before retries and logging. Racing calls are serialized
+	// across their retries.
+	client.Use(append(append(slices.Clone(options.middleware), SerializeMiddleware(), CacheMiddleware(DefaultMetadataCacheTTL), retry, LoggingMiddleware()), timeout...)...)
 
+	return client, nil
 }
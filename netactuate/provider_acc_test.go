@@ -0,0 +1,77 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// testAccProtoV6ProviderFactories serves the muxed provider to acceptance
+// tests, the same way main serves it to Terraform.
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"netactuate": func() (tfprotov6.ProviderServer, error) {
+		providerServer, err := NewProviderServer(context.Background(), "test")
+		if err != nil {
+			return nil, err
+		}
+		return providerServer(), nil
+	},
+}
+
+func testAccPreCheck(t *testing.T) {
+	if os.Getenv("NETACTUATE_API_KEY") == "" {
+		t.Fatal("NETACTUATE_API_KEY must be set for acceptance tests")
+	}
+}
+
+// TestAccProvider_Aliases plans and applies a configuration using two
+// aliased provider blocks. NETACTUATE_API_KEY_ALT selects the credentials of
+// the second alias, defaulting to NETACTUATE_API_KEY.
+func TestAccProvider_Aliases(t *testing.T) {
+	altKey := os.Getenv("NETACTUATE_API_KEY_ALT")
+	if altKey == "" {
+		altKey = os.Getenv("NETACTUATE_API_KEY")
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderAliasesConfig(os.Getenv("NETACTUATE_API_KEY"), altKey),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netactuate_cloud_pool_servers.primary", "id", "Default"),
+					resource.TestCheckResourceAttr("data.netactuate_cloud_pool_servers.secondary", "id", "Default"),
+				),
+			},
+		},
+	})
+}
+
+func testAccProviderAliasesConfig(primaryKey, secondaryKey string) string {
+	return fmt.Sprintf(`
+provider "netactuate" {
+  alias   = "primary"
+  api_key = %[1]q
+}
+
+provider "netactuate" {
+  alias   = "secondary"
+  api_key = %[2]q
+}
+
+data "netactuate_cloud_pool_servers" "primary" {
+  provider   = netactuate.primary
+  cloud_pool = "Default"
+}
+
+data "netactuate_cloud_pool_servers" "secondary" {
+  provider   = netactuate.secondary
+  cloud_pool = "Default"
+}
+`, primaryKey, secondaryKey)
+}
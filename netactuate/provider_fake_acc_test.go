@@ -0,0 +1,301 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// testAccFakeContractID is the billing contract the fake backend of the
+// acceptance tests accepts.
+const testAccFakeContractID = "C-1001"
+
+// testAccFakeBackend returns an in-memory backend for acceptance tests,
+// which run the full Terraform lifecycle of the resources without a
+// NetActuate account.
+func testAccFakeBackend() *FakeClient {
+	return NewFakeClient().AddContract(testAccFakeContractID)
+}
+
+// testAccFakeProviderFactories serves the muxed provider talking to the
+// fake backend, the same way testAccProtoV6ProviderFactories serves it
+// talking to the API.
+func testAccFakeProviderFactories(fake *FakeClient) map[string]func() (tfprotov6.ProviderServer, error) {
+	return map[string]func() (tfprotov6.ProviderServer, error){
+		"netactuate": func() (tfprotov6.ProviderServer, error) {
+			providerServer, err := NewProviderServer(context.Background(), "test", WithClientInterface(fake))
+			if err != nil {
+				return nil, err
+			}
+			return providerServer(), nil
+		},
+	}
+}
+
+// testAccCheckFakeServersDestroyed verifies that the servers of the
+// resources of the type were cancelled or terminated.
+func testAccCheckFakeServersDestroyed(fake *FakeClient, resourceType string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != resourceType {
+				continue
+			}
+			id, err := strconv.Atoi(rs.Primary.ID)
+			if err != nil {
+				return err
+			}
+			server, err := fake.GetServer(context.Background(), id)
+			if err != nil {
+				return err
+			}
+			if server.ServerStatus != "" && server.ServerStatus != "TERMINATED" {
+				return fmt.Errorf("server %d is still %s", id, server.ServerStatus)
+			}
+		}
+		return nil
+	}
+}
+
+func TestAccFakeSSHKey_Lifecycle(t *testing.T) {
+	fake := testAccFakeBackend()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(fake),
+		CheckDestroy: func(s *terraform.State) error {
+			keys, err := fake.GetSSHKeys(context.Background())
+			if err != nil {
+				return err
+			}
+			if len(keys) != 0 {
+				return fmt.Errorf("%d ssh keys left", len(keys))
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFakeSSHKeyConfig("deploy"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netactuate_sshkey.test", "name", "deploy"),
+					resource.TestCheckResourceAttrSet("netactuate_sshkey.test", "fingerprint"),
+				),
+			},
+			{
+				Config: testAccFakeSSHKeyConfig("deploy-renamed"),
+				Check:  resource.TestCheckResourceAttr("netactuate_sshkey.test", "name", "deploy-renamed"),
+			},
+			{
+				ResourceName:      "netactuate_sshkey.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccFakeSSHKeyConfig(name string) string {
+	return fmt.Sprintf(`
+resource "netactuate_sshkey" "test" {
+  name = %q
+  key  = %q
+}
+`, name, testSSHPublicKey)
+}
+
+func TestAccFakeServer_Lifecycle(t *testing.T) {
+	fake := testAccFakeBackend()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(fake),
+		CheckDestroy:             testAccCheckFakeServersDestroyed(fake, "netactuate_server"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFakeServerConfig("web01.example.com", "on"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netactuate_server.test", "image_id", "1000"),
+					resource.TestCheckResourceAttr("netactuate_server.test", "location_id", "3"),
+					resource.TestCheckResourceAttr("netactuate_server.test", "os_type", "linux"),
+					resource.TestCheckResourceAttr("netactuate_server.test", "power_state", "on"),
+					resource.TestCheckResourceAttr("netactuate_server.test", "server_status", "RUNNING"),
+					resource.TestCheckResourceAttr("netactuate_server.test", "installed", "true"),
+					resource.TestCheckResourceAttrSet("netactuate_server.test", "primary_ipv4"),
+				),
+			},
+			{
+				Config: testAccFakeServerConfig("web01.example.com", "off"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netactuate_server.test", "power_state", "off"),
+					resource.TestCheckResourceAttr("netactuate_server.test", "power_status", "STOPPED"),
+				),
+			},
+			{
+				Config: testAccFakeServerConfig("web02.example.com", "on"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netactuate_server.test", "hostname", "web02.example.com"),
+					resource.TestCheckResourceAttr("netactuate_server.test", "power_state", "on"),
+				),
+			},
+			{
+				ResourceName: "netactuate_server.test",
+				ImportState:  true,
+				// The configured credentials and billing keys can't be
+				// read back, so only check what the server reports.
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("imported %d servers", len(states))
+					}
+					for key, want := range map[string]string{
+						"hostname":    "web02.example.com",
+						"image_id":    "1000",
+						"location_id": "3",
+						"plan":        "VR1x1x25",
+					} {
+						if got := states[0].Attributes[key]; got != want {
+							return fmt.Errorf("imported %s is %q, want %q", key, got, want)
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func testAccFakeServerConfig(hostname, powerState string) string {
+	return fmt.Sprintf(`
+resource "netactuate_server" "test" {
+  hostname                    = %q
+  plan                        = "VR1x1x25"
+  location                    = "Amsterdam, NL"
+  image                       = "ubuntu-22.04"
+  password                    = "correct-horse-battery-staple"
+  package_billing             = "usage"
+  package_billing_contract_id = %q
+  power_state                 = %q
+}
+`, hostname, testAccFakeContractID, powerState)
+}
+
+func TestAccFakeAnycastNode_Lifecycle(t *testing.T) {
+	fake := testAccFakeBackend()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(fake),
+		CheckDestroy:             testAccCheckFakeServersDestroyed(fake, "netactuate_anycast_node"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFakeAnycastNodeConfig(false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netactuate_anycast_node.test", "bgp_group_id", "4242"),
+					resource.TestCheckResourceAttr("netactuate_anycast_node.test", "bgp_sessions.#", "1"),
+				),
+			},
+			{
+				Config: testAccFakeAnycastNodeConfig(true),
+				Check:  resource.TestCheckResourceAttr("netactuate_anycast_node.test", "bgp_sessions.#", "2"),
+			},
+			{
+				// The BGP settings are derived from the sessions, the
+				// configured credentials, billing keys and provider
+				// settings can't be read back.
+				ResourceName:      "netactuate_anycast_node.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"password", "package_billing", "package_billing_contract_id",
+					"location", "location_id", "image", "image_family",
+					"rebuild_on_user_data_change", "allow_relocation", "cancel_billing_on_destroy", "unlink_only",
+					"build_id", "last_build", "refresh_ips", "refresh_bgp",
+				},
+			},
+		},
+	})
+}
+
+func testAccFakeAnycastNodeConfig(ipv6 bool) string {
+	return fmt.Sprintf(`
+resource "netactuate_anycast_node" "test" {
+  hostname                    = "anycast01.example.com"
+  plan                        = "VR1x1x25"
+  location_id                 = 12
+  image_family                = "debian"
+  password                    = "correct-horse-battery-staple"
+  package_billing             = "usage"
+  package_billing_contract_id = %q
+  bgp_group_id                = 4242
+  bgp_ipv6                    = %t
+}
+`, testAccFakeContractID, ipv6)
+}
+
+func TestAccFakeBGPSessions_Lifecycle(t *testing.T) {
+	fake := testAccFakeBackend()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(fake),
+		CheckDestroy:             testAccCheckFakeServersDestroyed(fake, "netactuate_server"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFakeBGPSessionsConfig(false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("netactuate_bgp_sessions.test", "mbpkgid", "netactuate_server.test", "id"),
+					resource.TestCheckResourceAttrSet("netactuate_bgp_sessions.test", "id"),
+					resource.TestCheckResourceAttr("netactuate_bgp_sessions.test", "sessions.#", "1"),
+				),
+			},
+			{
+				// Enabling ipv6 adds the IPv6 session in place.
+				Config: testAccFakeBGPSessionsConfig(true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netactuate_bgp_sessions.test", "sessions.#", "2"),
+					resource.TestCheckResourceAttr("netactuate_bgp_sessions.test", "sessions.1.provider_ip_type", "ipv6"),
+				),
+			},
+			{
+				ResourceName:            "netactuate_bgp_sessions.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"wait_for_established"},
+			},
+		},
+	})
+}
+
+func testAccFakeBGPSessionsConfig(ipv6 bool) string {
+	return testAccFakeServerConfig("bgp01.example.com", "on") + fmt.Sprintf(`
+resource "netactuate_bgp_sessions" "test" {
+  mbpkgid  = netactuate_server.test.id
+  group_id = 4242
+  ipv6     = %t
+}
+`, ipv6)
+}
+
+func TestAccFakeDataSources(t *testing.T) {
+	fake := testAccFakeBackend()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccFakeProviderFactories(fake),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "netactuate_oses" "ubuntu" {
+  subtype = "ubuntu"
+}
+
+data "netactuate_locations" "europe" {
+  continent = "Europe"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netactuate_oses.ubuntu", "oses.#", "2"),
+					resource.TestCheckResourceAttr("data.netactuate_locations.europe", "locations.#", "2"),
+				),
+			},
+		},
+	})
+}
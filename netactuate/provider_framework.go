@@ -2,8 +2,14 @@ package netactuate
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -15,19 +21,25 @@ import (
 type FrameworkProvider struct {
 	version     string
 	gonaVersion string
+	options     providerOptions
 }
 
 // FrameworkProviderModel describes the provider configuration
 type FrameworkProviderModel struct {
-	ApiKey types.String `tfsdk:"api_key"`
-	ApiUrl types.String `tfsdk:"api_url"`
+	ApiKey         types.String `tfsdk:"api_key"`
+	ApiUrl         types.String `tfsdk:"api_url"`
+	ApiVersion     types.String `tfsdk:"api_version"`
+	MaxRetries     types.Int64  `tfsdk:"max_retries"`
+	RetryWaitMax   types.Int64  `tfsdk:"retry_wait_max"`
+	RequestTimeout types.Int64  `tfsdk:"request_timeout"`
 }
 
 // NewFrameworkProvider creates a new instance of the Framework provider
-func NewFrameworkProvider(version string) provider.Provider {
+func NewFrameworkProvider(version string, opts ...ProviderOption) provider.Provider {
 	return &FrameworkProvider{
 		version:     version,
 		gonaVersion: gona.Version,
+		options:     newProviderOptions(opts),
 	}
 }
 
@@ -44,11 +56,27 @@ func (p *FrameworkProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 			"api_key": schema.StringAttribute{
 				Optional:    true,
 				Sensitive:   true,
-				Description: "NetActuate API key. Can also be set with NETACTUATE_API_KEY environment variable.",
+				Description: apiKeyDescription,
 			},
 			"api_url": schema.StringAttribute{
 				Optional:    true,
-				Description: "NetActuate API URL. Optional, defaults to production API.",
+				Description: apiUrlDescription,
+			},
+			"api_version": schema.StringAttribute{
+				Optional:    true,
+				Description: apiVersionDescription,
+			},
+			"max_retries": schema.Int64Attribute{
+				Optional:    true,
+				Description: maxRetriesDescription,
+			},
+			"retry_wait_max": schema.Int64Attribute{
+				Optional:    true,
+				Description: retryWaitMaxDescription,
+			},
+			"request_timeout": schema.Int64Attribute{
+				Optional:    true,
+				Description: requestTimeoutDescription,
 			},
 		},
 	}
@@ -65,32 +93,79 @@ func (p *FrameworkProvider) Configure(ctx context.Context, req provider.Configur
 	// Get API key from config or environment
 	apiKey := config.ApiKey.ValueString()
 	if apiKey == "" {
-		// Framework will automatically check environment variable if we use proper schema
+		apiKey = os.Getenv("NETACTUATE_API_KEY")
+	}
+	if apiKey == "" && p.options.api == nil {
 		resp.Diagnostics.AddError(
-			"Unable to create NetActuate API client",
+			codedSummary(CodeMissingAPIKey, "Unable to create NetActuate API client"),
 			"Unable to find NetActuate API key. It can be set with either NETACTUATE_API_KEY environment variable or 'api_key' property",
 		)
 		return
 	}
 
+	apiVersion := config.ApiVersion.ValueString()
+	if apiVersion == "" {
+		apiVersion = os.Getenv("NETACTUATE_API_VERSION")
+	}
+	if apiVersion == "" {
+		apiVersion = string(DefaultAPIVersion)
+	}
+
 	// Create client
-	var client *gona.Client
-	apiUrl := config.ApiUrl.ValueString()
-	if apiUrl == "" {
-		client = gona.NewClient(apiKey)
-	} else {
-		client = gona.NewClientCustom(apiKey, apiUrl)
+	client, err := p.options.newClient(apiKey, config.ApiUrl.ValueString(), APIVersion(apiVersion))
+	if err != nil {
+		resp.Diagnostics.AddError(codedSummary(CodeClientSetupFailed, "Unable to create NetActuate API client"), err.Error())
+		return
 	}
 
+	maxRetries := int64(DefaultMaxRetries)
+	if !config.MaxRetries.IsNull() {
+		maxRetries = config.MaxRetries.ValueInt64()
+	}
+	retryWaitMax := int64(DefaultRetryWaitMax / time.Second)
+	if !config.RetryWaitMax.IsNull() {
+		retryWaitMax = config.RetryWaitMax.ValueInt64()
+	}
+	retry, err := newRetryMiddleware(int(maxRetries), int(retryWaitMax))
+	if err != nil {
+		resp.Diagnostics.AddError(codedSummary(CodeClientSetupFailed, "Unable to create NetActuate API client"), err.Error())
+		return
+	}
+	timeout, err := newTimeoutMiddleware(int(config.RequestTimeout.ValueInt64()))
+	if err != nil {
+		resp.Diagnostics.AddError(codedSummary(CodeClientSetupFailed, "Unable to create NetActuate API client"), err.Error())
+		return
+	}
+	client.Use(append(append(slices.Clone(p.options.middleware), SerializeMiddleware(), CacheMiddleware(DefaultMetadataCacheTTL), retry, LoggingMiddleware()), timeout...)...)
+
 	// Make client available to resources and data sources
 	resp.DataSourceData = client
 	resp.ResourceData = client
 }
 
+// configuredClient returns the client the provider configured for its
+// resources and data sources. It is nil until the provider is configured.
+func configuredClient(providerData any, diags *diag.Diagnostics) *Client {
+	if providerData == nil {
+		return nil
+	}
+
+	client, ok := providerData.(*Client)
+	if !ok {
+		diags.AddError(
+			codedSummary(CodeClientSetupFailed, "Unexpected provider data type"),
+			fmt.Sprintf("Expected *Client, got %T.", providerData),
+		)
+	}
+	return client
+}
+
 // Resources returns the list of resources for this provider
 func (p *FrameworkProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
-		// Start empty - resources will be added here as migrated from SDK v2
+		NewServerResource,
+		NewAnycastNodeResource,
+		NewSSHKeyResource,
 	}
 }
 
@@ -100,3 +175,11 @@ func (p *FrameworkProvider) DataSources(_ context.Context) []func() datasource.D
 		// Start empty - data sources will be added here as migrated from SDK v2
 	}
 }
+
+// Functions returns the provider functions of this provider
+func (p *FrameworkProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewASNFunction,
+		NewAnsibleInventoryFunction,
+	}
+}
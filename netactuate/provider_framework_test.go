@@ -4,15 +4,19 @@ import (
 	"context"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-go/tftypes"
-	"github.com/netactuate/gona/gona"
 	"github.com/stretchr/testify/assert"
 )
 
-var _ provider.Provider = (*FrameworkProvider)(nil)
+var (
+	_ provider.Provider              = (*FrameworkProvider)(nil)
+	_ provider.ProviderWithFunctions = (*FrameworkProvider)(nil)
+)
 
 func TestNewFrameworkProvider(t *testing.T) {
 	const version = "1.2.3"
@@ -67,13 +71,21 @@ func TestFrameworkProvider_Configure_Success(t *testing.T) {
 	configValue := tftypes.NewValue(
 		tftypes.Object{
 			AttributeTypes: map[string]tftypes.Type{
-				"api_key": tftypes.String,
-				"api_url": tftypes.String,
+				"api_key":         tftypes.String,
+				"api_url":         tftypes.String,
+				"api_version":     tftypes.String,
+				"max_retries":     tftypes.Number,
+				"retry_wait_max":  tftypes.Number,
+				"request_timeout": tftypes.Number,
 			},
 		},
 		map[string]tftypes.Value{
-			"api_key": tftypes.NewValue(tftypes.String, "test-api-key"),
-			"api_url": tftypes.NewValue(tftypes.String, nil),
+			"api_key":         tftypes.NewValue(tftypes.String, "test-api-key"),
+			"api_url":         tftypes.NewValue(tftypes.String, nil),
+			"api_version":     tftypes.NewValue(tftypes.String, nil),
+			"max_retries":     tftypes.NewValue(tftypes.Number, nil),
+			"retry_wait_max":  tftypes.NewValue(tftypes.Number, nil),
+			"request_timeout": tftypes.NewValue(tftypes.Number, nil),
 		},
 	)
 
@@ -98,12 +110,13 @@ func TestFrameworkProvider_Configure_Success(t *testing.T) {
 	assert.NotNil(t, resp.ResourceData, "ResourceData should be set")
 	assert.NotNil(t, resp.DataSourceData, "DataSourceData should be set")
 
-	// Verify it's a gona client
-	_, ok := resp.ResourceData.(*gona.Client)
-	assert.True(t, ok, "ResourceData should be *gona.Client")
+	// Verify it's a NetActuate client
+	client, ok := resp.ResourceData.(*Client)
+	assert.True(t, ok, "ResourceData should be *Client")
+	assert.Equal(t, DefaultAPIVersion, client.APIVersion(), "api_version should default to DefaultAPIVersion")
 
-	_, ok = resp.DataSourceData.(*gona.Client)
-	assert.True(t, ok, "DataSourceData should be *gona.Client")
+	_, ok = resp.DataSourceData.(*Client)
+	assert.True(t, ok, "DataSourceData should be *Client")
 }
 
 func TestFrameworkProvider_Configure_WithCustomURL(t *testing.T) {
@@ -113,13 +126,21 @@ func TestFrameworkProvider_Configure_WithCustomURL(t *testing.T) {
 	configValue := tftypes.NewValue(
 		tftypes.Object{
 			AttributeTypes: map[string]tftypes.Type{
-				"api_key": tftypes.String,
-				"api_url": tftypes.String,
+				"api_key":         tftypes.String,
+				"api_url":         tftypes.String,
+				"api_version":     tftypes.String,
+				"max_retries":     tftypes.Number,
+				"retry_wait_max":  tftypes.Number,
+				"request_timeout": tftypes.Number,
 			},
 		},
 		map[string]tftypes.Value{
-			"api_key": tftypes.NewValue(tftypes.String, "test-api-key"),
-			"api_url": tftypes.NewValue(tftypes.String, "https://custom.api.example.com"),
+			"api_key":         tftypes.NewValue(tftypes.String, "test-api-key"),
+			"api_url":         tftypes.NewValue(tftypes.String, "https://custom.api.example.com"),
+			"api_version":     tftypes.NewValue(tftypes.String, nil),
+			"max_retries":     tftypes.NewValue(tftypes.Number, nil),
+			"retry_wait_max":  tftypes.NewValue(tftypes.Number, nil),
+			"request_timeout": tftypes.NewValue(tftypes.Number, nil),
 		},
 	)
 
@@ -145,20 +166,77 @@ func TestFrameworkProvider_Configure_WithCustomURL(t *testing.T) {
 	assert.NotNil(t, resp.DataSourceData, "DataSourceData should be set")
 }
 
+func TestFrameworkProvider_Configure_APIKeyFromEnv(t *testing.T) {
+	t.Setenv("NETACTUATE_API_KEY", "env-api-key")
+	p := &FrameworkProvider{version: "test"}
+
+	// Create a config without api_key, which is taken from the environment
+	configValue := tftypes.NewValue(
+		tftypes.Object{
+			AttributeTypes: map[string]tftypes.Type{
+				"api_key":         tftypes.String,
+				"api_url":         tftypes.String,
+				"api_version":     tftypes.String,
+				"max_retries":     tftypes.Number,
+				"retry_wait_max":  tftypes.Number,
+				"request_timeout": tftypes.Number,
+			},
+		},
+		map[string]tftypes.Value{
+			"api_key":         tftypes.NewValue(tftypes.String, nil),
+			"api_url":         tftypes.NewValue(tftypes.String, nil),
+			"api_version":     tftypes.NewValue(tftypes.String, nil),
+			"max_retries":     tftypes.NewValue(tftypes.Number, nil),
+			"retry_wait_max":  tftypes.NewValue(tftypes.Number, nil),
+			"request_timeout": tftypes.NewValue(tftypes.Number, nil),
+		},
+	)
+
+	// Get the provider schema to create config
+	schemaReq := provider.SchemaRequest{}
+	schemaResp := &provider.SchemaResponse{}
+	p.Schema(context.Background(), schemaReq, schemaResp)
+
+	config := tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw:    configValue,
+	}
+
+	req := provider.ConfigureRequest{
+		Config: config,
+	}
+	resp := &provider.ConfigureResponse{}
+
+	p.Configure(context.Background(), req, resp)
+
+	assert.False(t, resp.Diagnostics.HasError(), "should not have errors when NETACTUATE_API_KEY is set")
+	_, ok := resp.ResourceData.(*Client)
+	assert.True(t, ok, "ResourceData should be *Client")
+}
+
 func TestFrameworkProvider_Configure_MissingAPIKey(t *testing.T) {
+	t.Setenv("NETACTUATE_API_KEY", "")
 	p := &FrameworkProvider{version: "test"}
 
 	// Create a config with empty api_key
 	configValue := tftypes.NewValue(
 		tftypes.Object{
 			AttributeTypes: map[string]tftypes.Type{
-				"api_key": tftypes.String,
-				"api_url": tftypes.String,
+				"api_key":         tftypes.String,
+				"api_url":         tftypes.String,
+				"api_version":     tftypes.String,
+				"max_retries":     tftypes.Number,
+				"retry_wait_max":  tftypes.Number,
+				"request_timeout": tftypes.Number,
 			},
 		},
 		map[string]tftypes.Value{
-			"api_key": tftypes.NewValue(tftypes.String, ""),
-			"api_url": tftypes.NewValue(tftypes.String, nil),
+			"api_key":         tftypes.NewValue(tftypes.String, ""),
+			"api_url":         tftypes.NewValue(tftypes.String, nil),
+			"api_version":     tftypes.NewValue(tftypes.String, nil),
+			"max_retries":     tftypes.NewValue(tftypes.Number, nil),
+			"retry_wait_max":  tftypes.NewValue(tftypes.Number, nil),
+			"request_timeout": tftypes.NewValue(tftypes.Number, nil),
 		},
 	)
 
@@ -183,17 +261,67 @@ func TestFrameworkProvider_Configure_MissingAPIKey(t *testing.T) {
 	assert.NotEmpty(t, resp.Diagnostics.Errors(), "should have at least one error diagnostic")
 
 	errorSummary := resp.Diagnostics.Errors()[0].Summary()
-	assert.Equal(t, "Unable to create NetActuate API client", errorSummary, "error summary should match")
+	assert.Equal(t, "[NA1101] Unable to create NetActuate API client", errorSummary, "error summary should match")
+}
+
+func TestFrameworkProvider_Configure_UnsupportedAPIVersion(t *testing.T) {
+	p := &FrameworkProvider{version: "test"}
+
+	// Create a config selecting an API generation the provider doesn't know
+	configValue := tftypes.NewValue(
+		tftypes.Object{
+			AttributeTypes: map[string]tftypes.Type{
+				"api_key":         tftypes.String,
+				"api_url":         tftypes.String,
+				"api_version":     tftypes.String,
+				"max_retries":     tftypes.Number,
+				"retry_wait_max":  tftypes.Number,
+				"request_timeout": tftypes.Number,
+			},
+		},
+		map[string]tftypes.Value{
+			"api_key":         tftypes.NewValue(tftypes.String, "test-api-key"),
+			"api_url":         tftypes.NewValue(tftypes.String, nil),
+			"api_version":     tftypes.NewValue(tftypes.String, "v0"),
+			"max_retries":     tftypes.NewValue(tftypes.Number, nil),
+			"retry_wait_max":  tftypes.NewValue(tftypes.Number, nil),
+			"request_timeout": tftypes.NewValue(tftypes.Number, nil),
+		},
+	)
+
+	// Get the provider schema to create config
+	schemaReq := provider.SchemaRequest{}
+	schemaResp := &provider.SchemaResponse{}
+	p.Schema(context.Background(), schemaReq, schemaResp)
+
+	config := tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw:    configValue,
+	}
+
+	req := provider.ConfigureRequest{
+		Config: config,
+	}
+	resp := &provider.ConfigureResponse{}
+
+	p.Configure(context.Background(), req, resp)
+
+	assert.True(t, resp.Diagnostics.HasError(), "should have error when api_version is unsupported")
+	assert.Contains(t, resp.Diagnostics.Errors()[0].Detail(), `unsupported api_version "v0"`)
+	assert.Nil(t, resp.ResourceData, "ResourceData should not be set")
 }
 
 func TestFrameworkProvider_Resources(t *testing.T) {
 	p := &FrameworkProvider{}
 
-	resources := p.Resources(context.Background())
+	var names []string
+	for _, newResource := range p.Resources(context.Background()) {
+		resp := &resource.MetadataResponse{}
+		newResource().Metadata(context.Background(), resource.MetadataRequest{ProviderTypeName: "netactuate"}, resp)
+		names = append(names, resp.TypeName)
+	}
 
-	assert.NotNil(t, resources, "Resources should not return nil")
-	// Currently should be empty during mux phase
-	assert.Empty(t, resources, "should have 0 resources during mux phase")
+	assert.Equal(t, []string{"netactuate_server", "netactuate_anycast_node", "netactuate_sshkey"}, names)
 }
 
 func TestFrameworkProvider_DataSources(t *testing.T) {
@@ -206,6 +334,19 @@ func TestFrameworkProvider_DataSources(t *testing.T) {
 	assert.Empty(t, dataSources, "should have 0 data sources during mux phase")
 }
 
+func TestFrameworkProvider_Functions(t *testing.T) {
+	p := &FrameworkProvider{}
+
+	var names []string
+	for _, newFunc := range p.Functions(context.Background()) {
+		resp := &function.MetadataResponse{}
+		newFunc().Metadata(context.Background(), function.MetadataRequest{}, resp)
+		names = append(names, resp.Name)
+	}
+
+	assert.Equal(t, []string{"asn", "ansible_inventory"}, names)
+}
+
 // TestFrameworkProvider_ProviderServer verifies the provider can be served
 func TestFrameworkProvider_ProviderServer(t *testing.T) {
 	p := NewFrameworkProvider("test")
@@ -0,0 +1,43 @@
+package netactuate
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-mux/tf5to6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf6muxserver"
+)
+
+// NewProviderServer combines the SDK v2 provider, upgraded to protocol 6,
+// with the Plugin Framework provider into a single protocol 6 provider server.
+func NewProviderServer(ctx context.Context, version string, opts ...ProviderOption) (func() tfprotov6.ProviderServer, error) {
+	// Wrap the SDK v2 provider with tf5to6server to upgrade it to protocol 6
+	upgradedSdkProvider, err := tf5to6server.UpgradeServer(
+		ctx,
+		NewSDKProvider(version, opts...).GRPCProvider,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the new Plugin Framework provider
+	frameworkProvider := providerserver.NewProtocol6(
+		NewFrameworkProvider(version, opts...),
+	)
+
+	// Mux the providers together
+	providers := []func() tfprotov6.ProviderServer{
+		func() tfprotov6.ProviderServer {
+			return upgradedSdkProvider
+		},
+		frameworkProvider,
+	}
+
+	muxServer, err := tf6muxserver.NewMuxServer(ctx, providers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return muxServer.ProviderServer, nil
+}
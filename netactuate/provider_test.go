@@ -0,0 +1,111 @@
+package netactuate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider(t *testing.T) {
+	assert.NoError(t, Provider().InternalValidate())
+}
+
+// TestMuxedProviderSchema verifies that the SDK v2 and Framework providers
+// declare identical provider schemas, which the mux server requires.
+func TestMuxedProviderSchema(t *testing.T) {
+	ctx := context.Background()
+
+	providerServer, err := NewProviderServer(ctx, "test")
+	require.NoError(t, err)
+
+	resp, err := providerServer().GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+
+	for _, d := range resp.Diagnostics {
+		assert.NotEqual(t, tfprotov6.DiagnosticSeverityError, d.Severity, "%s: %s", d.Summary, d.Detail)
+	}
+	assert.Contains(t, resp.Functions, "asn", "framework functions should be served through the mux")
+	assert.Contains(t, resp.ResourceSchemas, "netactuate_sshkey", "framework resources should be served through the mux")
+}
+
+// TestProviderConfigure_PerAliasClients verifies that every configured
+// provider instance, as Terraform creates one per provider alias, talks to
+// the API with its own credentials.
+func TestProviderConfigure_PerAliasClients(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.URL.Query().Get("key"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"result": "success", "code": 200, "data": []}`))
+	}))
+	defer server.Close()
+
+	configure := func(apiKey string) *Client {
+		p := Provider()
+		diags := p.Configure(ctx, terraform.NewResourceConfigRaw(map[string]any{
+			"api_key": apiKey,
+			"api_url": server.URL + "/api/",
+		}))
+		require.False(t, diags.HasError(), "configure should succeed: %v", diags)
+		return p.Meta().(*Client)
+	}
+
+	primary := configure("primary-key")
+	secondary := configure("secondary-key")
+	assert.NotSame(t, primary, secondary, "each provider instance should get its own client")
+
+	_, err := primary.GetServers(ctx)
+	require.NoError(t, err)
+	_, err = secondary.GetServers(ctx)
+	require.NoError(t, err)
+	_, err = primary.GetServers(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"primary-key", "secondary-key", "primary-key"}, keys)
+}
+
+// TestProviderConfigure_ClientInterface verifies that both providers call
+// an injected backend, without requiring an api_key.
+func TestProviderConfigure_ClientInterface(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeClient()
+
+	p := NewSDKProvider("test", WithClientInterface(fake))
+	diags := p.Configure(ctx, terraform.NewResourceConfigRaw(map[string]any{}))
+	require.False(t, diags.HasError(), "configure should succeed: %v", diags)
+	_, err := p.Meta().(*Client).GetServers(ctx)
+	require.NoError(t, err)
+
+	fp := NewFrameworkProvider("test", WithClientInterface(fake))
+	var schemaResp provider.SchemaResponse
+	fp.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	configType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	attributes := make(map[string]tftypes.Value)
+	for name, typ := range configType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(typ, nil)
+	}
+	resp := &provider.ConfigureResponse{}
+	fp.Configure(ctx, provider.ConfigureRequest{Config: tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(configType, attributes),
+	}}, resp)
+	require.False(t, resp.Diagnostics.HasError(), "configure should succeed: %v", resp.Diagnostics)
+	_, err = resp.ResourceData.(*Client).GetSSHKeys(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"GetServers()", "GetSSHKeys()"}, fake.GetCalls())
+}
@@ -0,0 +1,64 @@
+package netactuate
+
+import (
+	"os"
+	"strconv"
+
+	fwschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// forceRefreshEnv names the environment variable that overrides every
+// refresh_* flag set to false, to refresh everything on demand, e.g.
+// NETACTUATE_FORCE_REFRESH=1 terraform plan.
+const forceRefreshEnv = "NETACTUATE_FORCE_REFRESH"
+
+// refreshEnabled reports whether the attributes guarded by the refresh flag
+// key are read from the API. A flag missing from state, e.g. right after an
+// import, means refresh.
+func refreshEnabled(d *schema.ResourceData, key string) bool {
+	if forceRefresh() {
+		return true
+	}
+	refresh, ok := d.GetOkExists(key)
+	return !ok || refresh.(bool)
+}
+
+// refreshFlagEnabled is refreshEnabled for the refresh_* flags of Plugin
+// Framework resources.
+func refreshFlagEnabled(flag types.Bool) bool {
+	return forceRefresh() || flag.IsNull() || flag.IsUnknown() || flag.ValueBool()
+}
+
+func forceRefresh() bool {
+	force, _ := strconv.ParseBool(os.Getenv(forceRefreshEnv))
+	return force
+}
+
+// refreshFlag returns the schema of a refresh_* flag.
+func refreshFlag(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: refreshFlagDescription(description),
+	}
+}
+
+// refreshFlagAttribute returns the schema of a refresh_* flag of a Plugin
+// Framework resource.
+func refreshFlagAttribute(description string) fwschema.BoolAttribute {
+	return fwschema.BoolAttribute{
+		Optional:    true,
+		Computed:    true,
+		Default:     booldefault.StaticBool(true),
+		Description: refreshFlagDescription(description),
+	}
+}
+
+func refreshFlagDescription(description string) string {
+	return description + ". Set to false to skip these API calls in large workspaces; " +
+		"they are still made when " + forceRefreshEnv + " is set to true"
+}
@@ -0,0 +1,37 @@
+package netactuate
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshEnabled(t *testing.T) {
+	s := map[string]*schema.Schema{"refresh_ips": refreshFlag("Read the IPs")}
+
+	t.Setenv(forceRefreshEnv, "")
+	assert.True(t, refreshEnabled(schema.TestResourceDataRaw(t, s, nil), "refresh_ips"))
+	assert.True(t, refreshEnabled(schema.TestResourceDataRaw(t, s, map[string]any{"refresh_ips": true}), "refresh_ips"))
+	assert.False(t, refreshEnabled(schema.TestResourceDataRaw(t, s, map[string]any{"refresh_ips": false}), "refresh_ips"))
+
+	t.Setenv(forceRefreshEnv, "1")
+	assert.True(t, refreshEnabled(schema.TestResourceDataRaw(t, s, map[string]any{"refresh_ips": false}), "refresh_ips"))
+
+	// State written before the flag existed, e.g. by an import.
+	t.Setenv(forceRefreshEnv, "")
+	imported := (&schema.Resource{Schema: s}).Data(&terraform.InstanceState{ID: "42", Attributes: map[string]string{}})
+	assert.True(t, refreshEnabled(imported, "refresh_ips"))
+}
+
+func TestRefreshFlagEnabled(t *testing.T) {
+	t.Setenv(forceRefreshEnv, "")
+	assert.True(t, refreshFlagEnabled(types.BoolNull()), "imported state")
+	assert.True(t, refreshFlagEnabled(types.BoolValue(true)))
+	assert.False(t, refreshFlagEnabled(types.BoolValue(false)))
+
+	t.Setenv(forceRefreshEnv, "1")
+	assert.True(t, refreshFlagEnabled(types.BoolValue(false)))
+}
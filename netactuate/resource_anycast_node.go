@@ -0,0 +1,381 @@
+package netactuate
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/netactuate/gona/gona"
+)
+
+var (
+	_ resource.Resource                     = (*AnycastNodeResource)(nil)
+	_ resource.ResourceWithConfigure        = (*AnycastNodeResource)(nil)
+	_ resource.ResourceWithConfigValidators = (*AnycastNodeResource)(nil)
+	_ resource.ResourceWithImportState      = (*AnycastNodeResource)(nil)
+	_ resource.ResourceWithModifyPlan       = (*AnycastNodeResource)(nil)
+	_ resource.ResourceWithUpgradeState     = (*AnycastNodeResource)(nil)
+)
+
+// AnycastNodeResource builds a server and establishes its BGP sessions as a
+// single resource, so partial failures leave one tainted resource instead of
+// out-of-sync server and session resources.
+type AnycastNodeResource struct {
+	client *Client
+}
+
+// anycastNodeResourceModel describes the netactuate_anycast_node state
+type anycastNodeResourceModel struct {
+	serverBaseModel
+	BGPGroupID   types.Int64 `tfsdk:"bgp_group_id"`
+	BGPIPv6      types.Bool  `tfsdk:"bgp_ipv6"`
+	BGPRedundant types.Bool  `tfsdk:"bgp_redundant"`
+	BGPSessions  types.List  `tfsdk:"bgp_sessions"`
+	RefreshBGP   types.Bool  `tfsdk:"refresh_bgp"`
+}
+
+// anycastBGPSessionModel is an element of bgp_sessions, in the format of the
+// netactuate_bgp_sessions data source.
+type anycastBGPSessionModel struct {
+	BGPSessionModel
+	MbID types.Int64 `tfsdk:"mb_id"`
+}
+
+// anycastBGPSessionType is the type of the bgp_sessions elements
+var anycastBGPSessionType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"id":               types.Int64Type,
+	"mb_id":            types.Int64Type,
+	"description":      types.StringType,
+	"routes_received":  types.StringType,
+	"config_status":    types.StringType,
+	"last_update":      types.StringType,
+	"locked":           types.BoolType,
+	"group_id":         types.Int64Type,
+	"group_name":       types.StringType,
+	"location_name":    types.StringType,
+	"customer_peer_ip": types.StringType,
+	"provider_peer_ip": types.StringType,
+	"provider_ip_type": types.StringType,
+	"provider_asn":     types.Int64Type,
+	"customer_asn":     types.Int64Type,
+	"state":            types.StringType,
+}}
+
+// NewAnycastNodeResource creates a new netactuate_anycast_node resource
+func NewAnycastNodeResource() resource.Resource {
+	return &AnycastNodeResource{}
+}
+
+// Metadata returns the resource type name
+func (r *AnycastNodeResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_anycast_node"
+}
+
+// Schema returns the resource schema
+func (r *AnycastNodeResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	// Sessions can only be established once the server is built, so the
+	// node has no wait_for_running.
+	attributes := serverAttributes()
+	attributes["bgp_group_id"] = schema.Int64Attribute{
+		Required: true,
+		PlanModifiers: []planmodifier.Int64{
+			int64planmodifier.RequiresReplace(),
+		},
+	}
+	attributes["bgp_ipv6"] = schema.BoolAttribute{
+		Optional: true,
+		Computed: true,
+		Default:  booldefault.StaticBool(true),
+		PlanModifiers: []planmodifier.Bool{
+			boolplanmodifier.RequiresReplace(),
+		},
+	}
+	attributes["bgp_redundant"] = schema.BoolAttribute{
+		Optional: true,
+		Computed: true,
+		Default:  booldefault.StaticBool(false),
+		PlanModifiers: []planmodifier.Bool{
+			boolplanmodifier.RequiresReplace(),
+		},
+	}
+	attributes["bgp_sessions"] = schema.ListAttribute{
+		ElementType: anycastBGPSessionType,
+		Computed:    true,
+		PlanModifiers: []planmodifier.List{
+			listplanmodifier.UseStateForUnknown(),
+		},
+	}
+	attributes["refresh_bgp"] = refreshFlagAttribute("Refresh `bgp_sessions`, with an API call per session")
+
+	resp.Schema = schema.Schema{
+		Version: serverSchemaVersion,
+		Description: "A server together with its BGP sessions, managed with a single lifecycle. " +
+			"Prefix announcements are configured on the node's routing daemon over the created sessions. " +
+			"Import with `<mbpkgid>` or `hostname=<hostname>`.",
+		Attributes: attributes,
+		Blocks: map[string]schema.Block{
+			"timeouts": serverTimeoutsBlock(),
+		},
+	}
+}
+
+// UpgradeState returns the upgraders of states written with earlier schema
+// versions
+func (r *AnycastNodeResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	var resp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &resp)
+	return serverStateUpgraders(resp.Schema)
+}
+
+// ConfigValidators returns the validators of attribute combinations
+func (r *AnycastNodeResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return serverConfigValidators()
+}
+
+// Configure stores the API client configured by the provider
+func (r *AnycastNodeResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = configuredClient(req.ProviderData, &resp.Diagnostics)
+}
+
+// ModifyPlan plans the server like netactuate_server does and marks the
+// sessions established again after a rebuild or relocation as unknown.
+func (r *AnycastNodeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state anycastNodeResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var prior *serverBaseModel
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		prior = &state.serverBaseModel
+	}
+
+	if planServer(ctx, r.client, req, resp, &plan.serverBaseModel, prior) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("bgp_sessions"), types.ListUnknown(anycastBGPSessionType))...)
+	}
+}
+
+func (r *AnycastNodeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan anycastNodeResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(plan.readWriteOnly(ctx, req.Config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.require(CapabilityBGPSessions); err != nil {
+		resp.Diagnostics.Append(frameworkDiags(errDiag(CodeCapabilityUnavailable, err))...)
+		return
+	}
+
+	diags := createServer(ctx, r.client, &plan.serverBaseModel, true)
+	if plan.ID.IsUnknown() {
+		resp.Diagnostics.Append(frameworkDiags(diags)...)
+		return
+	}
+
+	// The server is recorded in state at this point, so a failure below
+	// leaves the node tainted and it is replaced as a whole on the next apply.
+	if !diags.HasError() {
+		diags = append(diags, createAnycastNodeSessions(ctx, r.client, &plan, plan.ID.ValueString())...)
+	}
+	if !diags.HasError() {
+		diags = append(diags, readAnycastNodeSessions(ctx, r.client, &plan)...)
+	}
+	resp.Diagnostics.Append(frameworkDiags(diags)...)
+
+	plan.resolveUnknowns()
+	if plan.BGPSessions.IsUnknown() {
+		plan.BGPSessions = types.ListNull(anycastBGPSessionType)
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *AnycastNodeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state anycastNodeResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags := readServer(ctx, r.client, &state.serverBaseModel)
+	if !diags.HasError() && state.ID.IsNull() {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if !diags.HasError() && state.BGPGroupID.IsNull() {
+		diags = append(diags, readImportedAnycastNode(ctx, r.client, &state)...)
+	} else if !diags.HasError() && refreshFlagEnabled(state.RefreshBGP) {
+		diags = append(diags, readAnycastNodeSessions(ctx, r.client, &state)...)
+	}
+	resp.Diagnostics.Append(frameworkDiags(diags)...)
+	if diags.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *AnycastNodeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state anycastNodeResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(plan.readWriteOnly(ctx, req.Config)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A relocated node announces from its new location before the old
+	// one is terminated.
+	diags := updateServer(ctx, r.client, &plan.serverBaseModel, &state.serverBaseModel, true, func(ctx context.Context, id int) diag.Diagnostics {
+		return createAnycastNodeSessions(ctx, r.client, &plan, strconv.Itoa(id))
+	})
+	if diags.HasError() {
+		resp.Diagnostics.Append(frameworkDiags(diags)...)
+		return
+	}
+
+	// Sessions are only planned unknown when the node was rebuilt, which
+	// may drop them, re-establish them.
+	if plan.BGPSessions.IsUnknown() {
+		diags = append(diags, readAnycastNodeSessions(ctx, r.client, &plan)...)
+		if !diags.HasError() && len(plan.BGPSessions.Elements()) == 0 {
+			diags = append(diags, createAnycastNodeSessions(ctx, r.client, &plan, plan.ID.ValueString())...)
+			if !diags.HasError() {
+				diags = append(diags, readAnycastNodeSessions(ctx, r.client, &plan)...)
+			}
+		}
+	}
+	resp.Diagnostics.Append(frameworkDiags(diags)...)
+	if diags.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *AnycastNodeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state anycastNodeResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(frameworkDiags(deleteServer(ctx, r.client, &state.serverBaseModel))...)
+}
+
+func (r *AnycastNodeResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importServer(ctx, r.client, req, resp)
+}
+
+// readAnycastNodeSessions reads the BGP sessions of the node in its BGP
+// group. Sessions of the server in other groups, e.g. managed by a
+// netactuate_bgp_sessions resource, are left out.
+func readAnycastNodeSessions(ctx context.Context, c *Client, m *anycastNodeResourceModel) diag.Diagnostics {
+	id, err := parseResourceID(m.ID.ValueString())
+	if err != nil {
+		return errDiag(CodeInvalidResourceID, err)
+	}
+
+	sessions, err := c.GetBGPSessions(ctx, id)
+	if err != nil {
+		return apiErrorDiag(err)
+	}
+
+	// The group is unknown until the first read of an import.
+	if groupID := int(m.BGPGroupID.ValueInt64()); groupID != 0 {
+		sessions = slices.DeleteFunc(sessions, func(s *gona.BGPSession) bool {
+			return s.GroupID != groupID
+		})
+	}
+
+	models := make([]anycastBGPSessionModel, 0, len(sessions))
+	for _, session := range sortBGPSessions(sessions) {
+		models = append(models, anycastBGPSessionModel{
+			BGPSessionModel: NewBGPSessionModel(session),
+			MbID:            types.Int64Null(),
+		})
+	}
+
+	var diags fwdiag.Diagnostics
+	m.BGPSessions, diags = types.ListValueFrom(ctx, anycastBGPSessionType, models)
+	return sdkDiags(diags)
+}
+
+// readImportedAnycastNode derives the BGP settings of a node that was just
+// imported, so only has an ID, from its sessions like the import of
+// netactuate_bgp_sessions does. They all force a new node, the plan after
+// the import would replace it otherwise.
+func readImportedAnycastNode(ctx context.Context, c *Client, m *anycastNodeResourceModel) diag.Diagnostics {
+	id, err := parseResourceID(m.ID.ValueString())
+	if err != nil {
+		return errDiag(CodeInvalidResourceID, err)
+	}
+
+	groupID, err := discoverBGPSessionsGroup(ctx, c, id)
+	if err != nil {
+		return errDiag(CodeBGPSessionsNotImported, err)
+	}
+	if groupID == 0 {
+		return errorDiag(CodeBGPSessionsNotImported, "server %d has no BGP sessions to import", id)
+	}
+	m.BGPGroupID = types.Int64Value(int64(groupID))
+
+	if diags := readAnycastNodeSessions(ctx, c, m); diags.HasError() {
+		return diags
+	}
+	var sessions []anycastBGPSessionModel
+	if diags := sdkDiags(m.BGPSessions.ElementsAs(ctx, &sessions, false)); diags.HasError() {
+		return diags
+	}
+	ipTypes := make([]string, len(sessions))
+	for i, session := range sessions {
+		ipTypes[i] = session.ProviderIPType.ValueString()
+	}
+	ipv6, redundant := bgpSessionsSettings(ipTypes)
+	m.BGPIPv6 = types.BoolValue(ipv6)
+	m.BGPRedundant = types.BoolValue(redundant)
+	return nil
+}
+
+// createAnycastNodeSessions establishes the configured BGP sessions on the
+// server with the given ID, the node's or the one it is relocated to.
+func createAnycastNodeSessions(ctx context.Context, c *Client, m *anycastNodeResourceModel, serverID string) diag.Diagnostics {
+	id, err := parseResourceID(serverID)
+	if err != nil {
+		return errDiag(CodeInvalidResourceID, err)
+	}
+
+	if _, err := c.CreateBGPSessions(
+		ctx,
+		id,
+		int(m.BGPGroupID.ValueInt64()),
+		m.BGPIPv6.ValueBool(),
+		m.BGPRedundant.ValueBool(),
+	); err != nil {
+		return apiErrorDiag(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAnycastNodeResource returns a netactuate_anycast_node resource
+// using fake and its schema.
+func newTestAnycastNodeResource(t *testing.T, fake *FakeClient) (*AnycastNodeResource, resource.SchemaResponse) {
+	t.Helper()
+
+	r := &AnycastNodeResource{}
+	resp := &resource.ConfigureResponse{}
+	r.Configure(context.Background(), resource.ConfigureRequest{ProviderData: newFakeAPIClient(fake)}, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(context.Background(), resource.SchemaRequest{}, &schemaResp)
+	require.False(t, schemaResp.Diagnostics.HasError(), "%v", schemaResp.Diagnostics)
+
+	return r, schemaResp
+}
+
+// createTestAnycastNode creates a node in group 12 and returns its state.
+func createTestAnycastNode(t *testing.T, fake *FakeClient, refreshBGP bool) (*AnycastNodeResource, tfsdk.State) {
+	t.Helper()
+
+	fake.AddContract("C-1001")
+	r, s := newTestAnycastNodeResource(t, fake)
+
+	plan := testPlan(t, s, &anycastNodeResourceModel{
+		serverBaseModel: testServerModel("C-1001"),
+		BGPGroupID:      types.Int64Value(12),
+		BGPIPv6:         types.BoolValue(true),
+		BGPRedundant:    types.BoolValue(false),
+		BGPSessions:     types.ListUnknown(anycastBGPSessionType),
+		RefreshBGP:      types.BoolValue(refreshBGP),
+	})
+	resp := &resource.CreateResponse{State: tfsdk.State{Schema: s.Schema}}
+	r.Create(context.Background(), resource.CreateRequest{Config: tfsdk.Config{Schema: s.Schema, Raw: plan.Raw}, Plan: plan}, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+	require.True(t, resp.State.Raw.IsFullyKnown(), "state must not hold unknown values")
+
+	return r, resp.State
+}
+
+func anycastNodeSessions(t *testing.T, state tfsdk.State) (anycastNodeResourceModel, []anycastBGPSessionModel) {
+	t.Helper()
+
+	var m anycastNodeResourceModel
+	require.False(t, state.Get(context.Background(), &m).HasError())
+	var sessions []anycastBGPSessionModel
+	require.False(t, m.BGPSessions.ElementsAs(context.Background(), &sessions, false).HasError())
+	return m, sessions
+}
+
+func TestResourceAnycastNodeCreate(t *testing.T) {
+	_, state := createTestAnycastNode(t, NewFakeClient(), true)
+
+	m, sessions := anycastNodeSessions(t, state)
+	assert.NotEmpty(t, m.ID.ValueString())
+	require.Len(t, sessions, 2, "an IPv4 and an IPv6 session")
+	assert.Equal(t, "ipv4", sessions[0].ProviderIPType.ValueString())
+	assert.Equal(t, m.PrimaryIPv4, sessions[0].CustomerPeerIP)
+}
+
+func TestResourceAnycastNodeRead_RefreshBGP(t *testing.T) {
+	tests := []struct {
+		desc         string
+		refresh      bool
+		forceRefresh string
+		wantCall     bool
+	}{
+		{desc: "refresh", refresh: true, wantCall: true},
+		{desc: "skip", refresh: false, wantCall: false},
+		{desc: "forced", refresh: false, forceRefresh: "true", wantCall: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Setenv(forceRefreshEnv, tt.forceRefresh)
+
+			fake := NewFakeClient()
+			r, state := createTestAnycastNode(t, fake, tt.refresh)
+
+			before := len(fake.GetCalls())
+			resp := &resource.ReadResponse{State: state}
+			r.Read(context.Background(), resource.ReadRequest{State: state}, resp)
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+			m, sessions := anycastNodeSessions(t, resp.State)
+			call := "GetBGPSessions(" + m.ID.ValueString() + ")"
+			assert.Equal(t, tt.wantCall, slices.Contains(fake.GetCalls()[before:], call))
+			assert.Len(t, sessions, 2, "skipped sessions keep their state")
+		})
+	}
+}
+
+func TestResourceAnycastNodeRead_OtherGroups(t *testing.T) {
+	fake := NewFakeClient()
+	r, state := createTestAnycastNode(t, fake, true)
+
+	m, _ := anycastNodeSessions(t, state)
+	id, err := strconv.Atoi(m.ID.ValueString())
+	require.NoError(t, err)
+	// A session of the server managed elsewhere, e.g. by netactuate_bgp_sessions.
+	_, err = fake.CreateBGPSessions(context.Background(), id, 99, false, false)
+	require.NoError(t, err)
+
+	resp := &resource.ReadResponse{State: state}
+	r.Read(context.Background(), resource.ReadRequest{State: state}, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	_, sessions := anycastNodeSessions(t, resp.State)
+	require.Len(t, sessions, 2, "only the sessions of group 12 belong to the node")
+	for _, s := range sessions {
+		assert.Equal(t, int64(12), s.GroupID.ValueInt64())
+	}
+}
+
+func TestResourceAnycastNodeRead_Imported(t *testing.T) {
+	fake := NewFakeClient()
+	r, created := createTestAnycastNode(t, fake, true)
+	m, _ := anycastNodeSessions(t, created)
+
+	s := resource.SchemaResponse{}
+	r.Schema(context.Background(), resource.SchemaRequest{}, &s)
+	state := tfsdk.State{Schema: s.Schema, Raw: tftypes.NewValue(s.Schema.Type().TerraformType(context.Background()), nil)}
+	importResp := &resource.ImportStateResponse{State: state}
+	r.ImportState(context.Background(), resource.ImportStateRequest{ID: m.ID.ValueString()}, importResp)
+	require.False(t, importResp.Diagnostics.HasError(), "%v", importResp.Diagnostics)
+
+	resp := &resource.ReadResponse{State: importResp.State}
+	r.Read(context.Background(), resource.ReadRequest{State: importResp.State}, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	imported, sessions := anycastNodeSessions(t, resp.State)
+	assert.Equal(t, m.ID, imported.ID)
+	assert.Equal(t, int64(12), imported.BGPGroupID.ValueInt64())
+	assert.True(t, imported.BGPIPv6.ValueBool(), "the node has an IPv6 session")
+	assert.False(t, imported.BGPRedundant.ValueBool())
+	assert.Len(t, sessions, 2)
+}
+
+func TestResourceAnycastNodeRead_ImportedWithoutSessions(t *testing.T) {
+	fake := NewFakeClient()
+	id := fake.AddServer(gona.Server{Name: "anycast01.example.com", ServerStatus: "RUNNING", Installed: 1}).LastServerID()
+	r, s := newTestAnycastNodeResource(t, fake)
+
+	state := tfsdk.State{Schema: s.Schema, Raw: tftypes.NewValue(s.Schema.Type().TerraformType(context.Background()), nil)}
+	require.False(t, state.SetAttribute(context.Background(), path.Root("id"), strconv.Itoa(id)).HasError())
+
+	resp := &resource.ReadResponse{State: state}
+	r.Read(context.Background(), resource.ReadRequest{State: state}, resp)
+	require.True(t, resp.Diagnostics.HasError())
+	assert.Equal(t, fmt.Sprintf("[NA3006] server %d has no BGP sessions to import", id), resp.Diagnostics[0].Summary())
+}
@@ -2,9 +2,15 @@ package netactuate
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/netactuate/gona/gona"
 )
@@ -13,10 +19,19 @@ func resourceBGPSessions() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceBGPSessionCreate,
 		ReadContext:   resourceBGPSessionRead,
+		UpdateContext: resourceBGPSessionUpdate,
 		DeleteContext: resourceBGPSessionDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceBGPSessionImport,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultServerTimeout),
+			Update: schema.DefaultTimeout(defaultServerTimeout),
+		},
+		Description: "The BGP sessions of a server with a BGP group. Enabling `ipv6` or `redundant` adds the " +
+			"missing sessions in place. The NetActuate API can't delete sessions, so destroying this resource " +
+			"only removes it from Terraform state. Import with `<mbpkgid>`, or `<mbpkgid>/<group_id>` for a server with " +
+			"sessions of several groups.",
 		Schema: map[string]*schema.Schema{
 			"mbpkgid": {
 				Type:     schema.TypeInt,
@@ -30,22 +45,91 @@ func resourceBGPSessions() *schema.Resource {
 			},
 			"ipv6": {
 				Type:     schema.TypeBool,
-				ForceNew: true,
 				Default:  true,
 				Optional: true,
 			},
 			"redundant": {
 				Type:     schema.TypeBool,
-				ForceNew: true,
 				Default:  false,
 				Optional: true,
 			},
+			"password_encryption_key": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				WriteOnly:        true,
+				ValidateDiagFunc: withDiagCode(CodeInvalidEncryptionKey, validateStateKey),
+				Description: "Base64 encoded 256-bit key, e.g. from `openssl rand -base64 32` or a KMS data key, to store " +
+					"the session passwords encrypted in `sessions.*.password_encrypted`. Write-only: it is never stored in state",
+			},
+			"password_encryption_key_version": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Change to re-encrypt the session passwords after changing or removing `password_encryption_key`",
+			},
+			"wait_for_established": {
+				Type:     schema.TypeBool,
+				Default:  false,
+				Optional: true,
+				Description: "Wait for every session of the group to reach the `established` state after a create or update, " +
+					"so resources depending on working routing are only created afterwards. The wait is bounded by the " +
+					"`create` and `update` timeouts",
+			},
+			"sessions": resourceBGPSessionsSessionsSchema(),
 		},
+		// Sessions can be added to a server in place, but the API has no way
+		// to remove them, so dropping IPv6 or redundant sessions replaces
+		// the resource. Adding them changes the sessions, which are unknown
+		// until the apply.
+		CustomizeDiff: customdiff.Sequence(
+			customdiff.ForceNewIfChange("ipv6", bgpSessionsRemoved),
+			customdiff.ForceNewIfChange("redundant", bgpSessionsRemoved),
+			customdiff.ComputedIf("sessions", bgpSessionsAdded),
+		),
 	}
 }
 
+// resourceBGPSessionsSessionsSchema extends the sessions of the
+// netactuate_bgp_sessions data source with their encrypted password.
+func resourceBGPSessionsSessionsSchema() *schema.Schema {
+	sessions := dataSourceBGPSessions().Schema["sessions"]
+	sessions.Description = "The sessions of the group, ordered by ID. Configure the BGP daemon of the server, e.g. bird " +
+		"or FRR, with `customer_peer_ip` and `customer_asn` as the local address and AS and `provider_peer_ip` and " +
+		"`provider_asn` as the neighbor and its remote AS, one neighbor per session. `state` and `config_status` " +
+		"are the session state reported by NetActuate"
+	sessions.Elem.(*schema.Resource).Schema["password_encrypted"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+		Description: "Session password encrypted with `password_encryption_key` (AES-256-GCM), empty without a key. " +
+			"The plaintext password is never stored in state",
+	}
+	return sessions
+}
+
+// bgpSessionsRemoved reports whether a flag change drops sessions that were
+// previously requested.
+func bgpSessionsRemoved(_ context.Context, old, new, _ any) bool {
+	return old.(bool) && !new.(bool)
+}
+
+// bgpSessionsAdded reports whether an update requests additional sessions.
+func bgpSessionsAdded(_ context.Context, d *schema.ResourceDiff, _ any) bool {
+	return d.Id() != "" && d.HasChanges("ipv6", "redundant")
+}
+
 func resourceBGPSessionCreate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	c := m.(*gona.Client)
+	c := m.(*Client)
+
+	if err := c.require(CapabilityBGPSessions); err != nil {
+		return errDiag(CodeCapabilityUnavailable, err)
+	}
+
+	// Sessions are only accepted once the server is built, wait for it so
+	// configurations don't need to sleep between the server and its
+	// sessions.
+	if _, diags := wait4Status(ctx, d.Get("mbpkgid").(int), "RUNNING", c, d.Timeout(schema.TimeoutCreate)); diags.HasError() {
+		return diags
+	}
 
 	if _, err := c.CreateBGPSessions(
 		ctx,
@@ -54,20 +138,354 @@ func resourceBGPSessionCreate(ctx context.Context, d *schema.ResourceData, m any
 		d.Get("ipv6").(bool),
 		d.Get("redundant").(bool),
 	); err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiag(err)
 	}
 
 	d.SetId(strconv.Itoa(d.Get("mbpkgid").(int)))
 
+	if d.Get("wait_for_established").(bool) {
+		if diags := wait4Established(ctx, c, d.Get("mbpkgid").(int), d.Get("group_id").(int), d.Timeout(schema.TimeoutCreate)); diags.HasError() {
+			return append(diags, readBGPSessions(ctx, d, c, true)...)
+		}
+	} else if diags := wait4Listed(ctx, c, d.Get("mbpkgid").(int), d.Get("group_id").(int), d.Timeout(schema.TimeoutCreate)); diags.HasError() {
+		return diags
+	}
+
+	return readBGPSessions(ctx, d, c, true)
+}
+
+func resourceBGPSessionRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	return readBGPSessions(ctx, d, m.(*Client), false)
+}
+
+// readBGPSessions refreshes the sessions of the resource. The encryption key
+// is only part of the configuration during apply, so encryptPasswords is set
+// by create and update; a refresh keeps the encrypted passwords in state.
+// Only a refresh removes the resource when its sessions are gone.
+func readBGPSessions(ctx context.Context, d *schema.ResourceData, c *Client, encryptPasswords bool) diag.Diagnostics {
+	if err := c.require(CapabilityBGPSessions); err != nil {
+		return errDiag(CodeCapabilityUnavailable, err)
+	}
+
+	id, err := parseResourceID(d.Id())
+	if err != nil {
+		return errDiag(CodeInvalidResourceID, err)
+	}
+
+	sessions, err := c.GetBGPSessions(ctx, id)
+	if err != nil {
+		if removedOutOfBand(ctx, err, fmt.Sprintf("BGP sessions of server %d", id)) {
+			d.SetId("")
+			return nil
+		}
+		return apiErrorDiag(err)
+	}
+
+	// Only the sessions with the configured group belong to this resource,
+	// the group is unknown until the first read of an import.
+	if groupID := d.Get("group_id").(int); groupID != 0 {
+		sessions = slices.DeleteFunc(sessions, func(s *gona.BGPSession) bool {
+			return s.GroupID != groupID
+		})
+	}
+
+	if len(sessions) == 0 {
+		if encryptPasswords {
+			return errorDiag(CodeBGPSessionsNotListed, "The BGP sessions of server %d with group %d are not listed", id, d.Get("group_id").(int))
+		}
+		// The sessions were removed out-of-band, recreate them.
+		d.SetId("")
+		return nil
+	}
+
+	flattened := FlattenBGPSessions(sessions)
+	if encryptPasswords {
+		var key []byte
+		if encoded := writeOnlyString(d, "password_encryption_key"); encoded != "" {
+			if key, err = parseStateKey(encoded); err != nil {
+				return errDiag(CodeInvalidEncryptionKey, err)
+			}
+		}
+		if diags := encryptBGPSessionPasswords(key, sortBGPSessions(sessions), flattened); diags.HasError() {
+			return diags
+		}
+	} else {
+		keepBGPSessionPasswords(d.Get("sessions").([]any), flattened)
+	}
+
+	var diags diag.Diagnostics
+	setValues(map[string]any{
+		"mbpkgid":  id,
+		"group_id": sessions[0].GroupID,
+		"sessions": flattened,
+	}, d, &diags)
+
+	return diags
+}
+
+// encryptBGPSessionPasswords sets the password_encrypted of the flattened
+// sessions, in the same order as sessions. Without a key it is cleared.
+func encryptBGPSessionPasswords(key []byte, sessions []*gona.BGPSession, flattened []map[string]any) diag.Diagnostics {
+	for i, session := range sessions {
+		flattened[i]["password_encrypted"] = ""
+		if key == nil {
+			continue
+		}
+
+		encrypted, err := encryptStateValue(key, anyToString(session.Password))
+		if err != nil {
+			return errorDiag(CodeStateEncryptionFailed, "Unable to encrypt the password of BGP session %d: %s", session.ID, err)
+		}
+		flattened[i]["password_encrypted"] = encrypted
+	}
 	return nil
 }
 
-func resourceBGPSessionRead(_ context.Context, _ *schema.ResourceData, _ any) diag.Diagnostics {
-	// Do nothing
+// keepBGPSessionPasswords copies the encrypted passwords of the sessions in
+// state to the refreshed sessions with the same ID.
+func keepBGPSessionPasswords(old []any, flattened []map[string]any) {
+	encrypted := make(map[int]string, len(old))
+	for _, s := range old {
+		session := s.(map[string]any)
+		if password, ok := session["password_encrypted"].(string); ok {
+			encrypted[session["id"].(int)] = password
+		}
+	}
+
+	for _, session := range flattened {
+		session["password_encrypted"] = encrypted[session["id"].(int)]
+	}
+}
+
+func resourceBGPSessionImport(ctx context.Context, d *schema.ResourceData, m any) ([]*schema.ResourceData, error) {
+	id, groupID, err := parseBGPSessionsImportID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a group the server's sessions must all belong to one, as the
+	// resource manages the sessions of a single group.
+	if groupID == 0 {
+		if groupID, err = discoverBGPSessionsGroup(ctx, m.(*Client), id); err != nil {
+			return nil, err
+		}
+	}
+
+	d.SetId(strconv.Itoa(id))
+	if err := d.Set("group_id", groupID); err != nil {
+		return nil, err
+	}
+
+	if diags := resourceBGPSessionRead(ctx, d, m); diags.HasError() {
+		return nil, codedErrorf(CodeBGPSessionsNotImported, "reading BGP sessions of server %d: %s", id, diags[0].Detail)
+	}
+	if d.Id() == "" {
+		return nil, codedErrorf(CodeBGPSessionsNotImported, "server %d has no BGP sessions to import", id)
+	}
+
+	// Derive the settings the sessions were most likely created with, so
+	// the plan after the import is clean.
+	var ipTypes []string
+	for _, s := range d.Get("sessions").([]any) {
+		ipTypes = append(ipTypes, s.(map[string]any)["provider_ip_type"].(string))
+	}
+	ipv6, redundant := bgpSessionsSettings(ipTypes)
+	if err := d.Set("ipv6", ipv6); err != nil {
+		return nil, err
+	}
+	if err := d.Set("redundant", redundant); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// discoverBGPSessionsGroup returns the group of the BGP sessions of a
+// server, which is 0 when it has none.
+func discoverBGPSessionsGroup(ctx context.Context, c *Client, id int) (int, error) {
+	if err := c.require(CapabilityBGPSessions); err != nil {
+		return 0, err
+	}
+	sessions, err := c.GetBGPSessions(ctx, id)
+	if err != nil {
+		if IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, codedErrorf(CodeBGPSessionsNotImported, "reading BGP sessions of server %d: %s", id, redactAPIKey(err.Error()))
+	}
+
+	var groups []int
+	for _, session := range sessions {
+		if !slices.Contains(groups, session.GroupID) {
+			groups = append(groups, session.GroupID)
+		}
+	}
+	if len(groups) > 1 {
+		slices.Sort(groups)
+		return 0, codedErrorf(CodeBGPSessionsNotImported,
+			"server %d has BGP sessions with groups %s: import the sessions of one group with <mbpkgid>/<group_id>",
+			id, strings.Trim(fmt.Sprint(groups), "[]"))
+	}
+	if len(groups) == 0 {
+		return 0, nil
+	}
+	return groups[0], nil
+}
+
+// parseBGPSessionsImportID parses an import ID of the form "<mbpkgid>" or
+// "<mbpkgid>/<group_id>". The group ID is 0 when omitted.
+func parseBGPSessionsImportID(importID string) (int, int, error) {
+	idPart, groupPart, hasGroup := strings.Cut(importID, "/")
+
+	id, err := parseResourceID(idPart)
+	if err != nil {
+		return 0, 0, codedErrorf(CodeInvalidImportID, "invalid import ID %q: expected <mbpkgid> or <mbpkgid>/<group_id>", importID)
+	}
+	if !hasGroup {
+		return id, 0, nil
+	}
+
+	groupID, err := parseResourceID(groupPart)
+	if err != nil {
+		return 0, 0, codedErrorf(CodeInvalidImportID, "invalid import ID %q: expected <mbpkgid> or <mbpkgid>/<group_id>", importID)
+	}
+	return id, groupID, nil
+}
+
+// bgpSessionsSettings infers the ipv6 and redundant settings from the
+// provider_ip_type of sessions: IPv6 sessions exist with ipv6 enabled, and
+// redundant sessions mean more than one session per address family.
+func bgpSessionsSettings(ipTypes []string) (ipv6 bool, redundant bool) {
+	perFamily := make(map[string]int)
+	for _, ipType := range ipTypes {
+		perFamily[ipType]++
+
+		if ipType == string(gona.IPv6) {
+			ipv6 = true
+		}
+		if perFamily[ipType] > 1 {
+			redundant = true
+		}
+	}
+	return ipv6, redundant
+}
+
+func resourceBGPSessionUpdate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
+	c := m.(*Client)
+
+	if err := c.require(CapabilityBGPSessions); err != nil {
+		return errDiag(CodeCapabilityUnavailable, err)
+	}
+
+	// Enabling ipv6 or redundant requests the sessions that are missing
+	// for the new settings; disabling them forces a new resource instead.
+	if d.HasChanges("ipv6", "redundant") {
+		if _, err := c.CreateBGPSessions(
+			ctx,
+			d.Get("mbpkgid").(int),
+			d.Get("group_id").(int),
+			d.Get("ipv6").(bool),
+			d.Get("redundant").(bool),
+		); err != nil {
+			return apiErrorDiag(err)
+		}
+	}
+
+	if d.Get("wait_for_established").(bool) {
+		if diags := wait4Established(ctx, c, d.Get("mbpkgid").(int), d.Get("group_id").(int), d.Timeout(schema.TimeoutUpdate)); diags.HasError() {
+			return append(diags, readBGPSessions(ctx, d, c, true)...)
+		}
+	} else if diags := wait4Listed(ctx, c, d.Get("mbpkgid").(int), d.Get("group_id").(int), d.Timeout(schema.TimeoutUpdate)); diags.HasError() {
+		return diags
+	}
+
+	return readBGPSessions(ctx, d, c, true)
+}
+
+// bgpSessionEstablished is the state of a session that exchanges routes.
+const bgpSessionEstablished = "established"
+
+// wait4Established polls the sessions of a server with the group until all
+// of them are established.
+func wait4Established(ctx context.Context, c *Client, mbPkgID, groupID int, timeout time.Duration) diag.Diagnostics {
+	var pending []string
+
+	err := waitFor(ctx, c.pollClock(), intervalSec*time.Second, timeout, func() (bool, error) {
+		sessions, err := c.GetBGPSessions(ctx, mbPkgID)
+		if err != nil {
+			if IsRetryable(err) {
+				return false, nil
+			}
+			return false, err
+		}
+
+		pending = pendingBGPSessions(sessions, groupID)
+		return len(pending) == 0, nil
+	})
+	if errors.Is(err, errWaitTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return errorDiag(CodeBGPSessionsNotEstablished, "Timeout of waiting the BGP sessions of server %d to be established: %s",
+			mbPkgID, strings.Join(pending, ", "))
+	}
+	if err != nil {
+		return apiErrorDiag(err)
+	}
+
 	return nil
 }
 
-func resourceBGPSessionDelete(_ context.Context, _ *schema.ResourceData, _ any) diag.Diagnostics {
-	// Do nothing
+// wait4Listed polls the sessions of a server until the ones with the group
+// are listed, which they may not be right after their creation.
+func wait4Listed(ctx context.Context, c *Client, mbPkgID, groupID int, timeout time.Duration) diag.Diagnostics {
+	err := waitFor(ctx, c.pollClock(), intervalSec*time.Second, timeout, func() (bool, error) {
+		sessions, err := c.GetBGPSessions(ctx, mbPkgID)
+		if err != nil {
+			if IsRetryable(err) {
+				return false, nil
+			}
+			return false, err
+		}
+
+		return slices.ContainsFunc(sessions, func(s *gona.BGPSession) bool {
+			return s.GroupID == groupID
+		}), nil
+	})
+	if errors.Is(err, errWaitTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return errorDiag(CodeBGPSessionsNotListed, "Timeout of waiting the BGP sessions of server %d with group %d to be listed", mbPkgID, groupID)
+	}
+	if err != nil {
+		return apiErrorDiag(err)
+	}
+
 	return nil
 }
+
+// pendingBGPSessions describes the sessions with the group that aren't
+// established yet, e.g. "7 (Active)". A group without sessions is pending
+// as well, as they may not be listed right after their creation.
+func pendingBGPSessions(sessions []*gona.BGPSession, groupID int) []string {
+	var pending []string
+	var found bool
+	for _, session := range sortBGPSessions(sessions) {
+		if session.GroupID != groupID {
+			continue
+		}
+		found = true
+
+		if state := anyToString(session.State); !strings.EqualFold(state, bgpSessionEstablished) {
+			pending = append(pending, fmt.Sprintf("%d (%s)", session.ID, state))
+		}
+	}
+	if !found {
+		return []string{fmt.Sprintf("no sessions with group %d", groupID)}
+	}
+	return pending
+}
+
+func resourceBGPSessionDelete(_ context.Context, d *schema.ResourceData, _ any) diag.Diagnostics {
+	return diag.Diagnostics{codedDiag(diag.Warning, CodeBGPSessionsNotDeleted,
+		"BGP sessions were not removed",
+		fmt.Sprintf("The NetActuate API does not support deleting BGP sessions. "+
+			"The sessions of server %s were removed from Terraform state only, "+
+			"remove them in the NetActuate portal or by terminating the server.", d.Id()),
+	)}
+}
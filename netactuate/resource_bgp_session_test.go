@@ -0,0 +1,398 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBGPSessionsRemoved(t *testing.T) {
+	tests := []struct {
+		old, new bool
+		want     bool
+	}{
+		{old: false, new: false, want: false},
+		{old: false, new: true, want: false},
+		{old: true, new: true, want: false},
+		{old: true, new: false, want: true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, bgpSessionsRemoved(context.Background(), tt.old, tt.new, nil), "%v -> %v", tt.old, tt.new)
+	}
+}
+
+func TestResourceBGPSessionsDiff_AddedSessionsUnknown(t *testing.T) {
+	state := &terraform.InstanceState{ID: "42", Attributes: map[string]string{
+		"id":                          "42",
+		"mbpkgid":                     "42",
+		"group_id":                    "12",
+		"ipv6":                        "false",
+		"redundant":                   "false",
+		"sessions.#":                  "1",
+		"sessions.0.id":               "7",
+		"sessions.0.provider_peer_ip": "192.0.2.1",
+	}}
+
+	tests := []struct {
+		name        string
+		ipv6        bool
+		wantUnknown bool
+	}{
+		{name: "unchanged", ipv6: false},
+		{name: "ipv6 enabled", ipv6: true, wantUnknown: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := terraform.NewResourceConfigRaw(map[string]any{
+				"mbpkgid":   42,
+				"group_id":  12,
+				"ipv6":      tt.ipv6,
+				"redundant": false,
+			})
+
+			diff, err := resourceBGPSessions().Diff(context.Background(), state, config, nil)
+			require.NoError(t, err)
+
+			var sessionsUnknown bool
+			if diff != nil {
+				if attr := diff.Attributes["sessions.#"]; attr != nil {
+					sessionsUnknown = attr.NewComputed
+				}
+				assert.False(t, diff.RequiresNew())
+			}
+			assert.Equal(t, tt.wantUnknown, sessionsUnknown)
+		})
+	}
+}
+
+func TestResourceBGPSessionRead(t *testing.T) {
+	api := newTestAPI(t, map[string]string{
+		"bgp/bgpsessions":     `[{"id": 7, "customer_peer_ip": "192.0.2.10"}, {"id": 8, "customer_peer_ip": "198.51.100.10"}]`,
+		"cloud/networkips/42": `{"IPv4": [{"id": 1, "ip": "192.0.2.10", "primary": 1}], "IPv6": []}`,
+		"bgp/bgpsession/7": `{"id": 7, "group_id": 12, "customer_peer_ip": "192.0.2.10", "provider_peer_ip": "192.0.2.1",
+			"customer_asn": "64512", "provider_asn": "36236", "state": "Established", "routes_received": 2}`,
+	})
+	c, err := NewClient("test-api-key", api.URL+"/api/", DefaultAPIVersion)
+	require.NoError(t, err)
+
+	d := resourceBGPSessions().TestResourceData()
+	d.SetId("42")
+
+	diags := resourceBGPSessionRead(context.Background(), d, c)
+	assert.Empty(t, diags)
+	assert.Equal(t, "42", d.Id())
+	assert.Equal(t, 42, d.Get("mbpkgid"))
+	assert.Equal(t, 12, d.Get("group_id"))
+	assert.Equal(t, 1, d.Get("sessions.#"))
+	assert.Equal(t, "192.0.2.10", d.Get("sessions.0.customer_peer_ip"))
+	assert.Equal(t, "192.0.2.1", d.Get("sessions.0.provider_peer_ip"))
+	assert.Equal(t, 64512, d.Get("sessions.0.customer_asn"))
+	assert.Equal(t, 36236, d.Get("sessions.0.provider_asn"))
+	assert.Equal(t, "Established", d.Get("sessions.0.state"))
+	assert.Equal(t, "2", d.Get("sessions.0.routes_received"))
+}
+
+func TestResourceBGPSessionRead_NoSessions(t *testing.T) {
+	api := newTestAPI(t, map[string]string{
+		"bgp/bgpsessions": `[]`,
+	})
+	c, err := NewClient("test-api-key", api.URL+"/api/", DefaultAPIVersion)
+	require.NoError(t, err)
+
+	d := resourceBGPSessions().TestResourceData()
+	d.SetId("42")
+
+	diags := resourceBGPSessionRead(context.Background(), d, c)
+	assert.Empty(t, diags)
+	assert.Empty(t, d.Id())
+}
+
+func TestResourceBGPSessionRead_NotFound(t *testing.T) {
+	api := newTestAPI(t, nil)
+	c, err := NewClient("test-api-key", api.URL+"/api/", DefaultAPIVersion)
+	require.NoError(t, err)
+
+	d := resourceBGPSessions().TestResourceData()
+	d.SetId("42")
+
+	diags := resourceBGPSessionRead(context.Background(), d, c)
+	assert.Empty(t, diags)
+	assert.Empty(t, d.Id())
+}
+
+func TestResourceBGPSessionDelete(t *testing.T) {
+	d := resourceBGPSessions().TestResourceData()
+	d.SetId("42")
+
+	diags := resourceBGPSessionDelete(context.Background(), d, nil)
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Contains(t, diags[0].Detail, "server 42")
+}
+
+func TestParseBGPSessionsImportID(t *testing.T) {
+	tests := []struct {
+		id      string
+		want    int
+		group   int
+		wantErr bool
+	}{
+		{id: "42", want: 42},
+		{id: "42/12", want: 42, group: 12},
+		{id: "42/", wantErr: true},
+		{id: "web01/12", wantErr: true},
+		{id: "42/anycast", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			id, group, err := parseBGPSessionsImportID(tt.id)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, id)
+			assert.Equal(t, tt.group, group)
+		})
+	}
+}
+
+func TestResourceBGPSessionImport(t *testing.T) {
+	api := newTestAPI(t, map[string]string{
+		"bgp/bgpsessions": `[{"id": 7, "customer_peer_ip": "192.0.2.10"}, {"id": 8, "customer_peer_ip": "192.0.2.10"},
+			{"id": 9, "customer_peer_ip": "192.0.2.10"}]`,
+		"cloud/networkips/42": `{"IPv4": [{"id": 1, "ip": "192.0.2.10", "primary": 1}], "IPv6": []}`,
+		"bgp/bgpsession/7":    `{"id": 7, "group_id": 12, "customer_peer_ip": "192.0.2.10", "provider_ip_type": "ipv4"}`,
+		"bgp/bgpsession/8":    `{"id": 8, "group_id": 12, "customer_peer_ip": "192.0.2.10", "provider_ip_type": "ipv4"}`,
+		"bgp/bgpsession/9":    `{"id": 9, "group_id": 13, "customer_peer_ip": "192.0.2.10", "provider_ip_type": "ipv6"}`,
+	})
+	c, err := NewClient("test-api-key", api.URL+"/api/", DefaultAPIVersion)
+	require.NoError(t, err)
+
+	d := resourceBGPSessions().TestResourceData()
+	d.SetId("42/12")
+
+	imported, err := resourceBGPSessionImport(context.Background(), d, c)
+	require.NoError(t, err)
+	require.Len(t, imported, 1)
+
+	assert.Equal(t, "42", d.Id())
+	assert.Equal(t, 12, d.Get("group_id"))
+	assert.Equal(t, 2, d.Get("sessions.#"))
+	assert.Equal(t, false, d.Get("ipv6"))
+	assert.Equal(t, true, d.Get("redundant"))
+}
+
+func TestResourceBGPSessionImport_Discovery(t *testing.T) {
+	fake := NewFakeClient()
+	id := fake.AddServer(gona.Server{ServerStatus: "RUNNING", Installed: 1, PrimaryIPv4: "192.0.2.10", PrimaryIPv6: "2001:db8::10"}).LastServerID()
+	c := newFakeAPIClient(fake)
+	_, err := c.CreateBGPSessions(context.Background(), id, 12, true, false)
+	require.NoError(t, err)
+
+	d := resourceBGPSessions().TestResourceData()
+	d.SetId(strconv.Itoa(id))
+
+	_, err = resourceBGPSessionImport(context.Background(), d, c)
+	require.NoError(t, err)
+	assert.Equal(t, 12, d.Get("group_id"))
+	assert.Equal(t, 2, d.Get("sessions.#"))
+	assert.Equal(t, true, d.Get("ipv6"))
+	assert.Equal(t, false, d.Get("redundant"))
+
+	// With a second group, the group to import has to be given.
+	_, err = c.CreateBGPSessions(context.Background(), id, 13, false, false)
+	require.NoError(t, err)
+
+	d = resourceBGPSessions().TestResourceData()
+	d.SetId(strconv.Itoa(id))
+	_, err = resourceBGPSessionImport(context.Background(), d, c)
+	assert.ErrorContains(t, err, "groups 12 13: import the sessions of one group with <mbpkgid>/<group_id>")
+}
+
+func TestResourceBGPSessionImport_NoSessions(t *testing.T) {
+	api := newTestAPI(t, map[string]string{
+		"bgp/bgpsessions": `[]`,
+	})
+	c, err := NewClient("test-api-key", api.URL+"/api/", DefaultAPIVersion)
+	require.NoError(t, err)
+
+	d := resourceBGPSessions().TestResourceData()
+	d.SetId("42")
+
+	_, err = resourceBGPSessionImport(context.Background(), d, c)
+	assert.ErrorContains(t, err, "server 42 has no BGP sessions")
+}
+
+func TestResourceBGPSessionCreate(t *testing.T) {
+	fake := NewFakeClient()
+	id := fake.AddServer(gona.Server{
+		ServerStatus: "RUNNING",
+		Installed:    1,
+		PrimaryIPv4:  "192.0.2.10",
+		PrimaryIPv6:  "2001:db8::10",
+	}).LastServerID()
+
+	d := schema.TestResourceDataRaw(t, resourceBGPSessions().Schema, map[string]any{
+		"mbpkgid":  id,
+		"group_id": 12,
+	})
+
+	diags := resourceBGPSessionCreate(context.Background(), d, newFakeAPIClient(fake))
+	require.Empty(t, diags)
+
+	assert.Equal(t, strconv.Itoa(id), d.Id())
+	assert.Equal(t, 2, d.Get("sessions.#"))
+	assert.Equal(t, "192.0.2.10", d.Get("sessions.0.customer_peer_ip"))
+	assert.Equal(t, "2001:db8::10", d.Get("sessions.1.customer_peer_ip"))
+}
+
+func TestResourceBGPSessionCreate_NotListedYet(t *testing.T) {
+	tests := []struct {
+		name       string
+		delaySteps int
+		wantError  string
+	}{
+		{name: "listed after a few polls", delaySteps: 3},
+		{name: "never listed", delaySteps: 1 << 20, wantError: "[NA3016] Timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFakeClient()
+			fake.SessionListDelaySteps = tt.delaySteps
+			id := fake.AddServer(gona.Server{ServerStatus: "RUNNING", Installed: 1, PrimaryIPv4: "192.0.2.10"}).LastServerID()
+
+			d := schema.TestResourceDataRaw(t, resourceBGPSessions().Schema, map[string]any{
+				"mbpkgid":  id,
+				"group_id": 12,
+				"ipv6":     false,
+			})
+
+			diags := resourceBGPSessionCreate(context.Background(), d, newFakeAPIClient(fake))
+			assert.Equal(t, strconv.Itoa(id), d.Id(), "the created sessions must not be dropped from state")
+			if tt.wantError == "" {
+				require.Empty(t, diags)
+				assert.Equal(t, 1, d.Get("sessions.#"))
+				return
+			}
+
+			require.True(t, diags.HasError())
+			assert.True(t, strings.HasPrefix(diags[0].Summary, tt.wantError), diags[0].Summary)
+		})
+	}
+}
+
+func TestResourceBGPSessionCreate_ServerNotRunning(t *testing.T) {
+	fake := NewFakeClient()
+	id := fake.AddServer(gona.Server{ServerStatus: "BUILDING"}).LastServerID()
+
+	d := schema.TestResourceDataRaw(t, resourceBGPSessions().Schema, map[string]any{
+		"mbpkgid":  id,
+		"group_id": 12,
+	})
+
+	diags := resourceBGPSessionCreate(context.Background(), d, newFakeAPIClient(fake))
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "Timeout")
+	assert.NotContains(t, fake.GetCalls(), fmt.Sprintf("CreateBGPSessions(%d, 12, true, false)", id))
+}
+
+func TestResourceBGPSessionCreate_WaitForEstablished(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     string
+		wantError string
+	}{
+		{name: "established", state: "Established"},
+		{name: "not established", state: "Active", wantError: "102 (Active)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFakeClient()
+			fake.BGPSessionState = tt.state
+			id := fake.AddServer(gona.Server{ServerStatus: "RUNNING", Installed: 1, PrimaryIPv4: "192.0.2.10"}).LastServerID()
+
+			d := schema.TestResourceDataRaw(t, resourceBGPSessions().Schema, map[string]any{
+				"mbpkgid":              id,
+				"group_id":             12,
+				"ipv6":                 false,
+				"wait_for_established": true,
+			})
+
+			diags := resourceBGPSessionCreate(context.Background(), d, newFakeAPIClient(fake))
+			assert.Equal(t, strconv.Itoa(id), d.Id(), "created sessions are kept in state")
+			assert.Equal(t, 1, d.Get("sessions.#"))
+			if tt.wantError == "" {
+				require.Empty(t, diags)
+				return
+			}
+
+			require.True(t, diags.HasError())
+			assert.Regexp(t, `^\[NA3012\] Timeout .* established: `+regexp.QuoteMeta(tt.wantError), diags[0].Summary)
+		})
+	}
+}
+
+func TestPendingBGPSessions(t *testing.T) {
+	sessions := []*gona.BGPSession{
+		{ID: 9, GroupID: 12, State: "Active"},
+		{ID: 7, GroupID: 12, State: "Established"},
+		{ID: 8, GroupID: 12, State: "established"},
+		{ID: 10, GroupID: 13, State: "Idle"},
+		{ID: 11, GroupID: 12},
+	}
+
+	assert.Equal(t, []string{"9 (Active)", "11 ()"}, pendingBGPSessions(sessions, 12))
+	assert.Empty(t, pendingBGPSessions(sessions[1:3], 12))
+	assert.Equal(t, []string{"no sessions with group 14"}, pendingBGPSessions(sessions, 14))
+}
+
+func TestResourceBGPSessionRead_KeepsEncryptedPasswords(t *testing.T) {
+	api := newTestAPI(t, map[string]string{
+		"bgp/bgpsessions":     `[{"id": 7, "customer_peer_ip": "192.0.2.10"}]`,
+		"cloud/networkips/42": `{"IPv4": [{"id": 1, "ip": "192.0.2.10", "primary": 1}], "IPv6": []}`,
+		"bgp/bgpsession/7":    `{"id": 7, "group_id": 12, "password": "s3cr3t"}`,
+	})
+	c, err := NewClient("test-api-key", api.URL+"/api/", DefaultAPIVersion)
+	require.NoError(t, err)
+
+	d := resourceBGPSessions().TestResourceData()
+	d.SetId("42")
+	require.NoError(t, d.Set("sessions", []map[string]any{{"id": 7, "password_encrypted": "aes256gcm:c2VhbGVk"}}))
+
+	diags := resourceBGPSessionRead(context.Background(), d, c)
+	assert.Empty(t, diags)
+	assert.Equal(t, "aes256gcm:c2VhbGVk", d.Get("sessions.0.password_encrypted"))
+}
+
+func TestEncryptBGPSessionPasswords(t *testing.T) {
+	sessions := []*gona.BGPSession{{ID: 7, Password: "s3cr3t"}, {ID: 8}}
+	flattened := FlattenBGPSessions(sessions)
+
+	key, err := parseStateKey(testStateKey('k'))
+	require.NoError(t, err)
+	require.Empty(t, encryptBGPSessionPasswords(key, sessions, flattened))
+
+	plaintext, err := DecryptStateValue(testStateKey('k'), flattened[0]["password_encrypted"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", plaintext)
+	assert.Empty(t, flattened[1]["password_encrypted"], "sessions without a password stay empty")
+
+	require.Empty(t, encryptBGPSessionPasswords(nil, sessions, flattened))
+	assert.Empty(t, flattened[0]["password_encrypted"], "removing the key clears the encrypted passwords")
+}
@@ -1,499 +1,1324 @@
 package netactuate
 
 import (
+	"cmp"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/netactuate/gona/gona"
 )
 
 const (
 	tries       = 200
 	intervalSec = 1
+	// waitProgressInterval is how often waiting for a server status logs
+	// its progress.
+	waitProgressInterval = 30 * time.Second
+
+	defaultServerTimeout = tries * intervalSec * time.Second
 )
 
 var (
-	credentialKeys = []string{"password", "ssh_key_id", "ssh_key"}
+	credentialKeys = []string{"password", "password_wo", "ssh_key_id", "ssh_key"}
 	locationKeys   = []string{"location", "location_id"}
-	imageKeys      = []string{"image", "image_id"}
+	imageKeys      = []string{"image", "image_family", "image_id"}
 	billingKeys    = []string{"package_billing_contract_id", "package_billing_opt_in"}
+	rebuildKeys    = []string{"location", "location_id", "image", "image_family", "image_id", "hostname", "params", "cloud_config", "rebuild_trigger"}
+	userDataKeys   = []string{"user_data", "user_data_base64", "user_data_wo_version"}
+	// ipKeys are the attributes whose changes give a rebuilt server new IP
+	// addresses.
+	ipKeys = []string{"location", "location_id", "image", "image_family", "image_id", "hostname"}
 
 	hostnameRegex = regexp.MustCompile(fmt.Sprintf("^(%[1]s\\.)*%[1]s$", fmt.Sprintf("(%[1]s|%[1]s%[2]s*%[1]s)", "[a-zA-Z0-9]", "[a-zA-Z0-9\\-]")))
 )
 
-func resourceServer() *schema.Resource {
-	recalc_ipaddr := func(_ context.Context, d *schema.ResourceDiff, _meta any) bool {
-		return d.HasChanges("location_id", "image", "image_id", "hostname")
-	}
-
-	return &schema.Resource{
-		CreateContext: resourceServerCreate,
-		ReadContext:   resourceServerRead,
-		UpdateContext: resourceServerUpdate,
-		DeleteContext: resourceServerDelete,
-		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
-		},
-		Schema: map[string]*schema.Schema{
-			"hostname": {
-				Type:     schema.TypeString,
-				ForceNew: false,
-				Required: true,
-				ValidateDiagFunc: func(i any, path cty.Path) diag.Diagnostics {
-					if !hostnameRegex.MatchString(i.(string)) {
-						return diag.Errorf("%q is not a valid hostname", i)
-					}
-					return nil
-				},
-			},
-			"plan": {
-				Type:     schema.TypeString,
-				ForceNew: true,
-				Required: true,
-			},
-			"package_billing": {
-				Type:     schema.TypeString,
-				ForceNew: false,
-				Optional: true,
-				Default:  "usage",
-			},
-			"package_billing_opt_in": {
-				Type:         schema.TypeString,
-				ExactlyOneOf: billingKeys,
-				ForceNew:     false,
-				Optional:     true,
-			},
-			"package_billing_contract_id": {
-				Type:         schema.TypeString,
-				ExactlyOneOf: billingKeys,
-				ForceNew:     false,
-				Optional:     true,
-			},
-			"location": {
-				Type:         schema.TypeString,
-				ForceNew:     false,
-				Optional:     true,
-				ExactlyOneOf: locationKeys,
-				StateFunc: func(val any) string {
-					return strings.ToUpper(val.(string))
-				},
-				DiffSuppressFunc: func(_, old, new string, _ *schema.ResourceData) bool {
-					return new == "" || strings.EqualFold(strings.ToUpper(old), strings.Fields(new)[0])
-				},
+var (
+	_ resource.Resource                     = (*ServerResource)(nil)
+	_ resource.ResourceWithConfigure        = (*ServerResource)(nil)
+	_ resource.ResourceWithConfigValidators = (*ServerResource)(nil)
+	_ resource.ResourceWithImportState      = (*ServerResource)(nil)
+	_ resource.ResourceWithModifyPlan       = (*ServerResource)(nil)
+	_ resource.ResourceWithUpgradeState     = (*ServerResource)(nil)
+)
+
+// ServerResource manages a server, netactuate_server.
+type ServerResource struct {
+	client *Client
+}
+
+// serverBaseModel describes the attributes netactuate_server shares with
+// netactuate_anycast_node.
+type serverBaseModel struct {
+	ID                       types.String         `tfsdk:"id"`
+	Hostname                 types.String         `tfsdk:"hostname"`
+	Plan                     types.String         `tfsdk:"plan"`
+	PackageBilling           types.String         `tfsdk:"package_billing"`
+	PackageBillingOptIn      types.String         `tfsdk:"package_billing_opt_in"`
+	PackageBillingContractID types.String         `tfsdk:"package_billing_contract_id"`
+	Location                 locationValue        `tfsdk:"location"`
+	LocationID               types.Int64          `tfsdk:"location_id"`
+	Image                    types.String         `tfsdk:"image"`
+	ImageFamily              types.String         `tfsdk:"image_family"`
+	ImageID                  types.Int64          `tfsdk:"image_id"`
+	Password                 types.String         `tfsdk:"password"`
+	PasswordWO               types.String         `tfsdk:"password_wo"`
+	SSHKeyID                 types.Int64          `tfsdk:"ssh_key_id"`
+	SSHKey                   types.String         `tfsdk:"ssh_key"`
+	CloudConfig              types.String         `tfsdk:"cloud_config"`
+	UserData                 types.String         `tfsdk:"user_data"`
+	UserDataBase64           types.String         `tfsdk:"user_data_base64"`
+	UserDataWO               types.String         `tfsdk:"user_data_wo"`
+	UserDataWOVersion        types.Int64          `tfsdk:"user_data_wo_version"`
+	RebuildOnUserDataChange  types.Bool           `tfsdk:"rebuild_on_user_data_change"`
+	AllowRelocation          types.Bool           `tfsdk:"allow_relocation"`
+	RebuildTrigger           types.String         `tfsdk:"rebuild_trigger"`
+	PowerState               types.String         `tfsdk:"power_state"`
+	InstallComplete          types.Bool           `tfsdk:"install_complete"`
+	ServerStatus             types.String         `tfsdk:"server_status"`
+	PowerStatus              types.String         `tfsdk:"power_status"`
+	Installed                types.Bool           `tfsdk:"installed"`
+	PrimaryIPv4              types.String         `tfsdk:"primary_ipv4"`
+	PrimaryIPv6              types.String         `tfsdk:"primary_ipv6"`
+	RefreshIPs               types.Bool           `tfsdk:"refresh_ips"`
+	IPv4Addresses            types.List           `tfsdk:"ipv4_addresses"`
+	IPv6Addresses            types.List           `tfsdk:"ipv6_addresses"`
+	Params                   types.String         `tfsdk:"params"`
+	CloudPool                types.String         `tfsdk:"cloud_pool"`
+	BuildID                  types.Int64          `tfsdk:"build_id"`
+	LastBuild                types.String         `tfsdk:"last_build"`
+	FinalStateFile           types.String         `tfsdk:"final_state_file"`
+	CancelBillingOnDestroy   types.Bool           `tfsdk:"cancel_billing_on_destroy"`
+	UnlinkOnly               types.Bool           `tfsdk:"unlink_only"`
+	TTLHours                 types.Int64          `tfsdk:"ttl_hours"`
+	ExpiresAt                types.String         `tfsdk:"expires_at"`
+	ExpiryAction             types.String         `tfsdk:"expiry_action"`
+	OSType                   types.String         `tfsdk:"os_type"`
+	Timeouts                 *serverTimeoutsModel `tfsdk:"timeouts"`
+}
+
+// serverResourceModel describes the netactuate_server state
+type serverResourceModel struct {
+	serverBaseModel
+	WaitForRunning types.Bool `tfsdk:"wait_for_running"`
+}
+
+// serverTimeoutsModel describes the timeouts block
+type serverTimeoutsModel struct {
+	Create types.String `tfsdk:"create"`
+	Update types.String `tfsdk:"update"`
+	Delete types.String `tfsdk:"delete"`
+}
+
+// NewServerResource creates a new netactuate_server resource
+func NewServerResource() resource.Resource {
+	return &ServerResource{}
+}
+
+// Metadata returns the resource type name
+func (r *ServerResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_server"
+}
+
+// Schema returns the resource schema
+func (r *ServerResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := serverAttributes()
+	attributes["wait_for_running"] = schema.BoolAttribute{
+		Optional:    true,
+		Computed:    true,
+		Default:     booldefault.StaticBool(true),
+		Description: "Wait for the server to reach the RUNNING status after a create or rebuild. When false, the apply finishes as soon as the build was requested and the IP addresses may only be known after the next refresh",
+	}
+
+	resp.Schema = schema.Schema{
+		Version: serverSchemaVersion,
+		Description: "Manages a NetActuate server. Changing the location, image, hostname, params, cloud_config " +
+			"or rebuild_trigger rebuilds the server in place: it keeps its ID, but its disk is reinstalled. " +
+			"Import with `<mbpkgid>` or `hostname=<hostname>`.",
+		Attributes: attributes,
+		Blocks: map[string]schema.Block{
+			"timeouts": serverTimeoutsBlock(),
+		},
+	}
+}
+
+// serverAttributes returns the schema attributes netactuate_server shares
+// with netactuate_anycast_node.
+func serverAttributes() map[string]schema.Attribute {
+	attributes := map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Computed:    true,
+			Description: "The ID of this resource.",
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
 			},
-			"location_id": {
-				Type:         schema.TypeInt,
-				ForceNew:     false,
-				Optional:     true,
-				ExactlyOneOf: locationKeys,
-				Computed:     true,
+		},
+		"hostname": schema.StringAttribute{
+			Required:   true,
+			Validators: []validator.String{hostnameValidator{}},
+		},
+		"plan": schema.StringAttribute{
+			Required: true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
 			},
-			"image": {
-				Type:         schema.TypeString,
-				ForceNew:     false,
-				Optional:     true,
-				ExactlyOneOf: imageKeys,
+		},
+		"package_billing": schema.StringAttribute{
+			Optional: true,
+			Computed: true,
+			Default:  stringdefault.StaticString("usage"),
+		},
+		"package_billing_opt_in": schema.StringAttribute{
+			Optional: true,
+		},
+		"package_billing_contract_id": schema.StringAttribute{
+			Optional: true,
+		},
+		"location": schema.StringAttribute{
+			CustomType: locationType{},
+			Optional:   true,
+		},
+		"location_id": schema.Int64Attribute{
+			Optional: true,
+			Computed: true,
+			PlanModifiers: []planmodifier.Int64{
+				int64planmodifier.UseStateForUnknown(),
 			},
-			"image_id": {
-				Type:         schema.TypeInt,
-				ForceNew:     false,
-				Optional:     true,
-				ExactlyOneOf: imageKeys,
+		},
+		"password": schema.StringAttribute{
+			Optional:  true,
+			Sensitive: true,
+		},
+		"password_wo": schema.StringAttribute{
+			Optional:  true,
+			Sensitive: true,
+			WriteOnly: true,
+			Description: "Root or Administrator password to build the server with, e.g. for Windows images that don't support " +
+				"SSH keys. Like `password`, but write-only: it is never stored in the plan or state. Requires Terraform 1.11 or later",
+		},
+		"ssh_key_id": schema.Int64Attribute{
+			Optional: true,
+		},
+		"ssh_key": schema.StringAttribute{
+			Optional: true,
+		},
+		"cloud_config": schema.StringAttribute{
+			Optional: true,
+		},
+		// XXX: ExactlyOneOf user_data and user_data_base64?
+		"user_data": schema.StringAttribute{
+			Optional: true,
+		},
+		"user_data_base64": schema.StringAttribute{
+			Optional: true,
+		},
+		"user_data_wo": schema.StringAttribute{
+			Optional:  true,
+			Sensitive: true,
+			WriteOnly: true,
+			Description: "Script to run when the server is built, for scripts embedding secrets. Like `user_data`, but write-only: " +
+				"it is never stored in the plan or state, so changes to it are only noticed through `user_data_wo_version`. " +
+				"Requires Terraform 1.11 or later",
+		},
+		"user_data_wo_version": schema.Int64Attribute{
+			Optional: true,
+			Description: "Version of `user_data_wo`, to be changed together with it. A change counts as a change of the user data, " +
+				"see `rebuild_on_user_data_change`",
+		},
+		"rebuild_on_user_data_change": schema.BoolAttribute{
+			Optional:    true,
+			Computed:    true,
+			Default:     booldefault.StaticBool(false),
+			Description: "Rebuild the server when user_data, user_data_base64 or user_data_wo_version changes. When false, changes are only applied on the next rebuild",
+		},
+		"allow_relocation": schema.BoolAttribute{
+			Optional: true,
+			Computed: true,
+			Default:  booldefault.StaticBool(false),
+			Description: "Move the server to a new location by building a new server there and terminating the old one " +
+				"once the new one is running, instead of terminating it first. The server gets a new ID and IP addresses",
+		},
+		"rebuild_trigger": schema.StringAttribute{
+			Optional:    true,
+			Description: "Arbitrary value whose changes rebuild the server in place with its current settings, e.g. a timestamp or the hash of a provisioning script",
+		},
+		"power_state": schema.StringAttribute{
+			Optional:    true,
+			Computed:    true,
+			Validators:  []validator.String{oneOfValidator{code: CodeInvalidPowerState, values: powerStates}},
+			Description: "Desired power state of the server, `on` or `off`. Changing it starts or stops the server without rebuilding it",
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
 			},
-			"password": {
-				Type:         schema.TypeString,
-				ForceNew:     false,
-				Sensitive:    true,
-				Optional:     true,
-				ExactlyOneOf: credentialKeys,
+		},
+		"install_complete": schema.BoolAttribute{
+			Computed:    true,
+			Description: "Whether the server is RUNNING with its image installed, so BGP sessions and other services can be set up on it",
+			PlanModifiers: []planmodifier.Bool{
+				boolplanmodifier.UseStateForUnknown(),
 			},
-			"ssh_key_id": {
-				Type:         schema.TypeInt,
-				ForceNew:     false,
-				Optional:     true,
-				ExactlyOneOf: credentialKeys,
+		},
+		"server_status": schema.StringAttribute{
+			Computed:    true,
+			Description: "Status of the server as reported by the API, e.g. `BUILDING`, `RUNNING` or `TERMINATED`. Refreshed on every read",
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
 			},
-			"ssh_key": {
-				Type:         schema.TypeString,
-				ForceNew:     false,
-				Optional:     true,
-				ExactlyOneOf: credentialKeys,
+		},
+		"power_status": schema.StringAttribute{
+			Computed:    true,
+			Description: "Power status of the server as reported by the API, e.g. `RUNNING` or `STOPPED`. Refreshed on every read",
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
 			},
-			"cloud_config": {
-				Type:     schema.TypeString,
-				ForceNew: false,
-				Optional: true,
+		},
+		"installed": schema.BoolAttribute{
+			Computed:    true,
+			Description: "Whether the image of the server is installed. False while the server builds and after a failed build",
+			PlanModifiers: []planmodifier.Bool{
+				boolplanmodifier.UseStateForUnknown(),
 			},
-			// XXX: ExactlyOneOf user_data and user_data_base64?
-			"user_data": {
-				Type:     schema.TypeString,
-				ForceNew: false,
-				Optional: true,
+		},
+		"primary_ipv4": schema.StringAttribute{
+			Computed: true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
 			},
-			"user_data_base64": {
-				Type:     schema.TypeString,
-				ForceNew: false,
-				Optional: true,
+		},
+		"primary_ipv6": schema.StringAttribute{
+			Computed: true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
 			},
-			"primary_ipv4": {
-				Type:     schema.TypeString,
-				Computed: true,
+		},
+		"params": schema.StringAttribute{
+			Optional:    true,
+			Description: "Additional JSON formatted parameters to be passed to the server creation and management API",
+		},
+		"cloud_pool": schema.StringAttribute{
+			Optional:   true,
+			Computed:   true,
+			Validators: []validator.String{oneOfValidator{code: CodeInvalidCloudPool, values: cloudPoolNames}},
+			Description: "Name of the cloud pool to build the server in, e.g. `AMD EPYC`. Defaults to the pool the API picks. " +
+				"Servers can't be moved between pools, changing it replaces the server",
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+				stringplanmodifier.RequiresReplaceIfConfigured(),
 			},
-			"primary_ipv6": {
-				Type:     schema.TypeString,
-				Computed: true,
+		},
+		"build_id": schema.Int64Attribute{
+			Computed:    true,
+			Description: "ID of the backend build job started by the most recent create or rebuild",
+			PlanModifiers: []planmodifier.Int64{
+				int64planmodifier.UseStateForUnknown(),
 			},
-			"params": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Additional JSON formatted parameters to be passed to the server creation and management API",
+		},
+		"last_build": schema.StringAttribute{
+			Computed:    true,
+			Description: "Status returned by the API for the most recent create or rebuild request",
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
 			},
 		},
-		CustomizeDiff: customdiff.Sequence(
-			customdiff.ComputedIf("primary_ipv4", recalc_ipaddr),
-			customdiff.ComputedIf("primary_ipv6", recalc_ipaddr),
-		),
+		"final_state_file": schema.StringAttribute{
+			Optional:    true,
+			Description: "Path of a file to append a JSON record of the server (hostname, plan, location, image and IPs) to before it is destroyed",
+		},
+		"cancel_billing_on_destroy": schema.BoolAttribute{
+			Optional:    true,
+			Computed:    true,
+			Default:     booldefault.StaticBool(true),
+			Description: "Cancel the billing package when the server is destroyed. When false, the server is terminated but the package is kept and billed, in its location, to build a server in again later",
+		},
+		"unlink_only": schema.BoolAttribute{
+			Optional: true,
+			Computed: true,
+			Default:  booldefault.StaticBool(false),
+			Description: "Only unlink the billing package from its location when the server is destroyed: the server is terminated " +
+				"and the package is kept and billed, free to be built in any location. Takes precedence over `cancel_billing_on_destroy`",
+		},
+	}
+	maps.Copy(attributes, imageAttributes())
+	maps.Copy(attributes, expiryAttributes())
+	maps.Copy(attributes, ipAttributes())
+	maps.Copy(attributes, osAttributes())
+	return attributes
+}
+
+// serverTimeoutsBlock returns the timeouts block, in the format of the SDK
+// v2 versions of the resources, so existing configurations keep working.
+func serverTimeoutsBlock() schema.Block {
+	attribute := schema.StringAttribute{
+		Optional:   true,
+		Validators: []validator.String{durationValidator{}},
+	}
+	return schema.SingleNestedBlock{
+		Attributes: map[string]schema.Attribute{
+			"create": attribute,
+			"update": attribute,
+			"delete": attribute,
+		},
 	}
 }
 
-func resourceServerCreate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	c := m.(*gona.Client)
+// serverConfigValidators returns the configuration validators of
+// netactuate_server and netactuate_anycast_node.
+func serverConfigValidators() []resource.ConfigValidator {
+	return append([]resource.ConfigValidator{
+		exactlyOneOf(billingKeys...),
+		billingValidator{},
+		exactlyOneOf(locationKeys...),
+		exactlyOneOf(imageKeys...),
+		exactlyOneOf(credentialKeys...),
+		atMostOneOf("user_data", "user_data_wo"),
+		atMostOneOf("user_data_base64", "user_data_wo"),
+	}, expiryConfigValidators()...)
+}
+
+// UpgradeState returns the upgraders of states written with earlier schema
+// versions
+func (r *ServerResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	var resp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &resp)
+	return serverStateUpgraders(resp.Schema)
+}
 
-	locationId, imageId, diags := getParams(ctx, d, c)
-	if diags != nil {
-		return diags
+// ConfigValidators returns the validators of attribute combinations
+func (r *ServerResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return serverConfigValidators()
+}
+
+// Configure stores the API client configured by the provider
+func (r *ServerResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = configuredClient(req.ProviderData, &resp.Diagnostics)
+}
+
+// ModifyPlan resolves the image, validates the location and the attributes
+// against the image's OS and marks the computed attributes a rebuild or
+// relocation changes as unknown.
+func (r *ServerResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
 	}
-	diags = diag.Diagnostics{}
 
-	req := &gona.CreateServerRequest{
-		Plan:                     d.Get("plan").(string),
-		Location:                 locationId,
-		Image:                    imageId,
-		FQDN:                     d.Get("hostname").(string),
-		SSHKey:                   d.Get("ssh_key").(string),
-		SSHKeyID:                 d.Get("ssh_key_id").(int),
-		Password:                 d.Get("password").(string),
-		PackageBilling:           d.Get("package_billing").(string),
-		PackageBillingContractId: d.Get("package_billing_contract_id").(string),
-		CloudConfig:              base64.StdEncoding.EncodeToString([]byte(d.Get("cloud_config").(string))),
-		ScriptContent:            base64.StdEncoding.EncodeToString([]byte(d.Get("user_data").(string))),
-		Params:                   d.Get("params").(string), // Handle the new params field
+	var plan, state serverResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var prior *serverBaseModel
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		prior = &state.serverBaseModel
+	}
+
+	planServer(ctx, r.client, req, resp, &plan.serverBaseModel, prior)
+}
 
+// planServer plans the attributes netactuate_server shares with
+// netactuate_anycast_node, state is nil for a new server. It reports
+// whether the server is rebuilt.
+func planServer(ctx context.Context, c *Client, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, plan, state *serverBaseModel) bool {
+	planServerLocation(ctx, c, resp, plan, state)
+	planServerImage(ctx, c, resp, plan)
+	planServerOS(ctx, c, resp, plan, state)
+	if state == nil || resp.Diagnostics.HasError() {
+		return false
 	}
 
-	if userData64, ok := d.GetOk("user_data_base64"); ok {
-		req.ScriptContent = userData64.(string)
+	planServerExpiry(ctx, c, req, resp, plan, state)
+	rebuild, diags := planServerChanges(ctx, req.Config, &resp.Plan, plan, state)
+	resp.Diagnostics.Append(diags...)
+	return rebuild && !resp.Diagnostics.HasError()
+}
+
+func (r *ServerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan serverResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(plan.readWriteOnly(ctx, req.Config)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	var packageValue = d.Get("package_billing")
-	if packageValue == "package" {
-		optIn, ok := d.GetOk("package_billing_opt_in")
-		if !ok {
-			return diag.Errorf("when package_billing is set to package, package_billing_opt_in must be set to yes")
+	diags := createServer(ctx, r.client, &plan.serverBaseModel, waitForRunning(plan.WaitForRunning))
+	resp.Diagnostics.Append(frameworkDiags(diags)...)
+
+	// A server failing after it was created is recorded, it is tainted and
+	// replaced on the next apply.
+	if plan.ID.IsUnknown() {
+		return
+	}
+	plan.resolveUnknowns()
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *ServerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state serverResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags := readServer(ctx, r.client, &state.serverBaseModel)
+	resp.Diagnostics.Append(frameworkDiags(diags)...)
+	if diags.HasError() {
+		return
+	}
+	if state.ID.IsNull() {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *ServerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state serverResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(plan.readWriteOnly(ctx, req.Config)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags := updateServer(ctx, r.client, &plan.serverBaseModel, &state.serverBaseModel, waitForRunning(plan.WaitForRunning), nil)
+	resp.Diagnostics.Append(frameworkDiags(diags)...)
+	if diags.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *ServerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state serverResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(frameworkDiags(deleteServer(ctx, r.client, &state.serverBaseModel))...)
+}
+
+func (r *ServerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importServer(ctx, r.client, req, resp)
+}
+
+// importServer imports a server by its ID, or with an import ID of the form
+// "hostname=<hostname>" by the hostname, like the netactuate_server data
+// source.
+func importServer(ctx context.Context, c *Client, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	hostname, ok := strings.CutPrefix(req.ID, "hostname=")
+	if !ok {
+		resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+		return
+	}
+
+	server, diags := findServerByHostname(ctx, c, hostname)
+	if diags.HasError() {
+		resp.Diagnostics.Append(frameworkDiags(diags)...)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), strconv.Itoa(server.ID))...)
+}
+
+// readWriteOnly reads the write-only attributes from the configuration,
+// they are always null in the plan.
+func (m *serverBaseModel) readWriteOnly(ctx context.Context, config tfsdk.Config) fwdiag.Diagnostics {
+	diags := config.GetAttribute(ctx, path.Root("password_wo"), &m.PasswordWO)
+	diags.Append(config.GetAttribute(ctx, path.Root("user_data_wo"), &m.UserDataWO)...)
+	return diags
+}
+
+// timeout returns the configured timeout of the operation, "create",
+// "update" or "delete".
+func (m *serverBaseModel) timeout(operation string) time.Duration {
+	if m.Timeouts == nil {
+		return defaultServerTimeout
+	}
+
+	var value types.String
+	switch operation {
+	case "create":
+		value = m.Timeouts.Create
+	case "update":
+		value = m.Timeouts.Update
+	case "delete":
+		value = m.Timeouts.Delete
+	}
+
+	// The value was validated with the configuration.
+	timeout, err := time.ParseDuration(value.ValueString())
+	if err != nil {
+		return defaultServerTimeout
+	}
+	return timeout
+}
+
+// resolveUnknowns sets the computed attributes the API reported nothing for
+// to null, as state can't hold unknown values.
+func (m *serverBaseModel) resolveUnknowns() {
+	if m.LocationID.IsUnknown() {
+		m.LocationID = types.Int64Null()
+	}
+	if m.PowerState.IsUnknown() {
+		m.PowerState = types.StringNull()
+	}
+	if m.InstallComplete.IsUnknown() {
+		m.InstallComplete = types.BoolNull()
+	}
+	if m.ServerStatus.IsUnknown() {
+		m.ServerStatus = types.StringNull()
+	}
+	if m.PowerStatus.IsUnknown() {
+		m.PowerStatus = types.StringNull()
+	}
+	if m.Installed.IsUnknown() {
+		m.Installed = types.BoolNull()
+	}
+	if m.PrimaryIPv4.IsUnknown() {
+		m.PrimaryIPv4 = types.StringNull()
+	}
+	if m.PrimaryIPv6.IsUnknown() {
+		m.PrimaryIPv6 = types.StringNull()
+	}
+	if m.IPv4Addresses.IsUnknown() {
+		m.IPv4Addresses = types.ListNull(types.StringType)
+	}
+	if m.IPv6Addresses.IsUnknown() {
+		m.IPv6Addresses = types.ListNull(types.StringType)
+	}
+	if m.BuildID.IsUnknown() {
+		m.BuildID = types.Int64Null()
+	}
+	if m.LastBuild.IsUnknown() {
+		m.LastBuild = types.StringNull()
+	}
+	if m.ExpiresAt.IsUnknown() {
+		m.ExpiresAt = types.StringNull()
+	}
+	if m.ImageID.IsUnknown() {
+		m.ImageID = types.Int64Null()
+	}
+	if m.CloudPool.IsUnknown() {
+		m.CloudPool = types.StringNull()
+	}
+	if m.OSType.IsUnknown() {
+		m.OSType = types.StringNull()
+	}
+}
+
+// planServerChanges marks the computed attributes the planned changes
+// affect as unknown. It reports whether the server is rebuilt.
+func planServerChanges(ctx context.Context, config tfsdk.Config, resp *tfsdk.Plan, plan, state *serverBaseModel) (bool, fwdiag.Diagnostics) {
+	changed := serverChanges(plan, state)
+	rebuild := needsRebuild(changed, plan.RebuildOnUserDataChange.ValueBool())
+	if !rebuild {
+		if hasChanges(changed, "power_state") {
+			return false, resp.SetAttribute(ctx, path.Root("power_status"), types.StringUnknown())
 		}
+		return false, nil
+	}
+
+	unknown := map[string]attr.Value{
+		"build_id":         types.Int64Unknown(),
+		"last_build":       types.StringUnknown(),
+		"install_complete": types.BoolUnknown(),
+		"server_status":    types.StringUnknown(),
+		"power_status":     types.StringUnknown(),
+		"installed":        types.BoolUnknown(),
+	}
+	if hasChanges(changed, ipKeys...) {
+		unknown["primary_ipv4"] = types.StringUnknown()
+		unknown["primary_ipv6"] = types.StringUnknown()
+		unknown["ipv4_addresses"] = types.ListUnknown(types.StringType)
+		unknown["ipv6_addresses"] = types.ListUnknown(types.StringType)
+	}
+	if relocates(changed, plan.AllowRelocation.ValueBool()) {
+		unknown["id"] = types.StringUnknown()
+	}
+
+	// Unless configured, the location ID follows a changed location and
+	// a rebuilt server comes back powered on.
+	var locationID types.Int64
+	var powerState types.String
+	diags := config.GetAttribute(ctx, path.Root("location_id"), &locationID)
+	diags.Append(config.GetAttribute(ctx, path.Root("power_state"), &powerState)...)
+	if diags.HasError() {
+		return rebuild, diags
+	}
+	if locationID.IsNull() && hasChanges(changed, "location") {
+		unknown["location_id"] = types.Int64Unknown()
+	}
+	if powerState.IsNull() {
+		unknown["power_state"] = types.StringUnknown()
+	}
+
+	for name, value := range unknown {
+		diags.Append(resp.SetAttribute(ctx, path.Root(name), value)...)
+	}
+	return rebuild, diags
+}
+
+// createServer creates the planned server and fills in its computed
+// attributes. The ID is set as soon as the server exists.
+func createServer(ctx context.Context, c *Client, m *serverBaseModel, wait bool) diag.Diagnostics {
+	timeout := m.timeout("create")
+
+	locationId, imageId, diags := getParams(ctx, c, m)
+	if diags != nil {
+		return diags
+	}
+	diags = diag.Diagnostics{}
 
-		if optIn.(string) != "yes" {
-			return diag.Errorf("when package_billing is set to package, package_billing_opt_in must be set to yes")
+	req := serverCreateRequest(m, locationId, imageId)
+	if req.CloudPool != gona.CloudPoolDefault {
+		if err := c.require(CapabilityCloudPools); err != nil {
+			return errDiag(CodeCapabilityUnavailable, err)
 		}
 	}
 
-	if packageValue == "usage" {
-		contractID, ok := d.GetOk("package_billing_contract_id")
-		if !ok {
-			return diag.Errorf("package_billing_contract_id must be set to your contract ID with NetActuate")
+	var packageValue = m.PackageBilling.ValueString()
+	if packageValue == "package" {
+		if m.PackageBillingOptIn.ValueString() != "yes" {
+			return errorDiag(CodeBillingOptInRequired, "when package_billing is set to package, package_billing_opt_in must be set to yes")
 		}
+	}
 
-		if len(contractID.(string)) == 0 {
-			return diag.Errorf("package_billing_contract_id must be set to your contract ID with NetActuate")
+	if packageValue == "usage" {
+		if m.PackageBillingContractID.ValueString() == "" {
+			return errorDiag(CodeBillingContractRequired, "package_billing_contract_id must be set to your contract ID with NetActuate")
 		}
 	}
 
 	s, err := c.CreateServer(ctx, req)
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiag(err)
 	}
 
-	d.SetId(strconv.Itoa(s.ServerID))
-	d.Set("params", req.Params) // Store params in the state file
+	m.ID = types.StringValue(strconv.Itoa(s.ServerID))
+	m.BuildID = types.Int64Value(int64(s.Build))
+	m.LastBuild = types.StringValue(s.Status)
+	setExpiry(m, c.pollClock().Now())
 
-	if _, err := wait4Status(ctx, s.ServerID, "RUNNING", c); err != nil {
-		return err
+	if wait {
+		if diags := waitForBuild(ctx, s.ServerID, s.Build, c, timeout); diags.HasError() {
+			return diags
+		}
+	}
+
+	if m.PowerState.ValueString() == powerStateOff {
+		if diags := setPowerState(ctx, c, s.ServerID, powerStateOff, timeout); diags.HasError() {
+			return diags
+		}
 	}
 
 	server, err := c.GetServer(ctx, s.ServerID)
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiag(err)
+	}
+	setServerComputed(m, server)
+	if diags := setServerOSType(ctx, c, m, server.OSID); diags.HasError() {
+		return diags
 	}
-	setValue("primary_ipv4", server.PrimaryIPv4, d, &diags)
-	setValue("primary_ipv6", server.PrimaryIPv6, d, &diags)
 
-	return nil
+	return setServerIPs(ctx, c, m, s.ServerID)
 }
 
-func resourceServerRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	c := m.(*gona.Client)
+// serverCreateRequest returns the request creating a server with the
+// configured settings.
+func serverCreateRequest(m *serverBaseModel, locationId int, imageId int) *gona.CreateServerRequest {
+	req := &gona.CreateServerRequest{
+		Plan:                     m.Plan.ValueString(),
+		Location:                 locationId,
+		Image:                    imageId,
+		FQDN:                     m.Hostname.ValueString(),
+		SSHKey:                   m.SSHKey.ValueString(),
+		SSHKeyID:                 int(m.SSHKeyID.ValueInt64()),
+		Password:                 cmp.Or(m.Password.ValueString(), m.PasswordWO.ValueString()),
+		PackageBilling:           m.PackageBilling.ValueString(),
+		PackageBillingContractId: m.PackageBillingContractID.ValueString(),
+		CloudConfig:              base64.StdEncoding.EncodeToString([]byte(m.CloudConfig.ValueString())),
+		ScriptContent:            base64.StdEncoding.EncodeToString([]byte(cmp.Or(m.UserData.ValueString(), m.UserDataWO.ValueString()))),
+		Params:                   m.Params.ValueString(), // Handle the new params field
+		CloudPool:                gona.CloudPoolFromName(m.CloudPool.ValueString()),
+	}
+
+	if userData64 := m.UserDataBase64.ValueString(); userData64 != "" {
+		req.ScriptContent = userData64
+	}
 
-	id, err := strconv.Atoi(d.Id())
+	return req
+}
+
+// readServer refreshes the state of a server from the API. The ID is nulled
+// when the server no longer exists, for the read to remove the resource.
+func readServer(ctx context.Context, c *Client, m *serverBaseModel) diag.Diagnostics {
+	id, err := parseResourceID(m.ID.ValueString())
 	if err != nil {
-		return diag.FromErr(err)
+		return errDiag(CodeInvalidResourceID, err)
 	}
 
 	server, err := c.GetServer(ctx, id)
+	if removedOutOfBand(ctx, err, fmt.Sprintf("server %d", id)) {
+		m.ID = types.StringNull()
+		return nil
+	}
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiag(err)
+	}
+	if serverRemoved(ctx, id, server) {
+		m.ID = types.StringNull()
+		return nil
 	}
 
-	var diags diag.Diagnostics
-
+	imported := !isSet(m.ImageID) && !isSet(m.Image) && !isSet(m.ImageFamily)
 	if server.Installed == 0 {
-		setValue("hostname", "", d, &diags)
-		updateValue("image_id", 0, d, &diags)
-		updateValue("image", "", d, &diags)
+		m.Hostname = types.StringValue("")
+		m.ImageID = types.Int64Value(0)
+		if isSet(m.Image) {
+			m.Image = types.StringValue("")
+		}
 	} else {
-		setValue("hostname", server.Name, d, &diags)
-		updateValue("image_id", server.OSID, d, &diags)
-		updateValue("image", server.OS, d, &diags)
+		m.Hostname = types.StringValue(server.Name)
+		m.ImageID = types.Int64Value(int64(server.OSID))
+		// A configured name is kept as long as it still names the image.
+		if isSet(m.Image) && !imageMatches(m.Image.ValueString(), server.OS) {
+			m.Image = types.StringValue(server.OS)
+		}
+	}
+	m.Plan = types.StringValue(server.Package)
+	if server.PackageBillingContractId != "" && isSet(m.PackageBillingContractID) {
+		m.PackageBillingContractID = types.StringValue(server.PackageBillingContractId)
+	}
+	if isSet(m.LocationID) {
+		m.LocationID = types.Int64Value(int64(server.LocationID))
+	}
+	if isSet(m.Location) {
+		m.Location = newLocationValue(locationCode(server.Location))
 	}
-	setValue("plan", server.Package, d, &diags)
-	updateValue("location_id", server.LocationID, d, &diags)
-	updateValue("location", strings.Fields(server.Location)[0], d, &diags)
 
-	_, exists_location_id := d.GetOk("location_id")
-	_, exists_location := d.GetOk("location")
-	if !exists_location_id && !exists_location {
-		setValue("location", strings.Fields(server.Location)[0], d, &diags)
+	if !isSet(m.LocationID) && !isSet(m.Location) {
+		m.Location = newLocationValue(locationCode(server.Location))
 	}
 
-	_, exists_image_id := d.GetOk("image_id")
-	_, exists_image := d.GetOk("image")
-	if !exists_image_id && !exists_image {
-		setValue("image", server.OS, d, &diags)
+	if imported {
+		m.Image = types.StringValue(server.OS)
+	}
+	// Servers imported or created by earlier versions have no os_type yet.
+	if m.OSType.IsNull() && server.Installed != 0 {
+		if os, diags := findOS(ctx, c, server.OSID); !diags.HasError() {
+			m.OSType = types.StringValue(os.Type)
+		}
+	}
+	m.PrimaryIPv4 = types.StringValue(server.PrimaryIPv4)
+	m.PrimaryIPv6 = types.StringValue(server.PrimaryIPv6)
+	m.CloudPool = types.StringValue(cloudPoolName(server))
+	m.InstallComplete = types.BoolValue(installComplete(server))
+	m.ServerStatus = types.StringValue(server.ServerStatus)
+	m.PowerStatus = types.StringValue(server.PowerStatus)
+	m.Installed = types.BoolValue(server.Installed == 1)
+	if ps := powerState(server.PowerStatus); ps != "" {
+		m.PowerState = types.StringValue(ps)
 	}
-	setValue("primary_ipv4", server.PrimaryIPv4, d, &diags)
-	setValue("primary_ipv6", server.PrimaryIPv6, d, &diags)
 
-	return diags
+	diags := readServerIPs(ctx, c, m, id)
+	if diags.HasError() {
+		return diags
+	}
+
+	return append(diags, expiryDiags(m, c.pollClock().Now())...)
+}
+
+// serverRemoved reports whether a server read without an error no longer
+// exists. gona answers the API's 422 for an unknown mbpkgid with a zero
+// server, whose status is blank, and terminated servers stay listed for a
+// while, see pollServerStatus.
+func serverRemoved(ctx context.Context, id int, server gona.Server) bool {
+	if server.ServerStatus != "" && server.ServerStatus != "TERMINATED" {
+		return false
+	}
+	tflog.Warn(ctx, "NetActuate server terminated, removing it from state", map[string]any{
+		"object": fmt.Sprintf("server %d", id),
+		"status": server.ServerStatus,
+	})
+	return true
 }
 
-func resourceServerUpdate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	c := m.(*gona.Client)
+// setServerComputed fills in the computed attributes a create or update left
+// unknown. Known ones were planned and must not change.
+func setServerComputed(m *serverBaseModel, server gona.Server) {
+	if m.LocationID.IsUnknown() {
+		m.LocationID = types.Int64Value(int64(server.LocationID))
+	}
+	if ps := powerState(server.PowerStatus); ps != "" && m.PowerState.IsUnknown() {
+		m.PowerState = types.StringValue(ps)
+	}
+	if m.PrimaryIPv4.IsUnknown() {
+		m.PrimaryIPv4 = types.StringValue(server.PrimaryIPv4)
+	}
+	if m.PrimaryIPv6.IsUnknown() {
+		m.PrimaryIPv6 = types.StringValue(server.PrimaryIPv6)
+	}
+	if m.InstallComplete.IsUnknown() {
+		m.InstallComplete = types.BoolValue(installComplete(server))
+	}
+	if m.ServerStatus.IsUnknown() {
+		m.ServerStatus = types.StringValue(server.ServerStatus)
+	}
+	if m.PowerStatus.IsUnknown() {
+		m.PowerStatus = types.StringValue(server.PowerStatus)
+	}
+	if m.Installed.IsUnknown() {
+		m.Installed = types.BoolValue(server.Installed == 1)
+	}
+	if m.CloudPool.IsUnknown() {
+		m.CloudPool = types.StringValue(cloudPoolName(server))
+	}
+}
+
+// installComplete reports whether the server is built far enough for the
+// API to accept BGP sessions for it.
+func installComplete(server gona.Server) bool {
+	return server.ServerStatus == "RUNNING" && server.Installed == 1
+}
+
+// updateServer applies the planned changes to a server. When the server is
+// relocated, relocated is called once the server in the new location is
+// running, before the old one is terminated.
+func updateServer(ctx context.Context, c *Client, plan, state *serverBaseModel, wait bool, relocated func(ctx context.Context, id int) diag.Diagnostics) diag.Diagnostics {
+	var diags diag.Diagnostics
+	timeout := plan.timeout("update")
+
+	id, err := parseResourceID(state.ID.ValueString())
+	if err != nil {
+		return errDiag(CodeInvalidResourceID, err)
+	}
+	plan.ID = state.ID
+
+	changed := serverChanges(plan, state)
+
 	// Rebuild on these property changes
-	if d.HasChanges("location", "location_id", "image", "image_id", "hostname", "params", "cloud_config") {
-		id, err := strconv.Atoi(d.Id())
-		if err != nil {
-			return diag.FromErr(err)
-		}
+	rebuild := needsRebuild(changed, plan.RebuildOnUserDataChange.ValueBool())
 
-		oldHost_r, _ := d.GetChange("hostname")
-		oldHost := oldHost_r.(string)
+	relocate := rebuild && relocates(changed, plan.AllowRelocation.ValueBool())
+	if relocate {
+		id, diags = relocateServer(ctx, c, plan, id, relocated)
+		if diags.HasError() {
+			return diags
+		}
+	}
 
-		if oldHost != "" {
+	if rebuild && !relocate {
+		if state.Hostname.ValueString() != "" {
 			// delete
 			err = c.DeleteServer(ctx, id, false)
 			if err != nil {
-				return diag.FromErr(err)
+				return apiErrorDiag(err)
 			}
 
 			// await termination
-			if _, err := wait4Status(ctx, id, "TERMINATED", c); err != nil {
+			if _, err := wait4Status(ctx, id, "TERMINATED", c, timeout); err != nil {
 				return err
 			}
 		}
 
-		// unlink if changing locationID
-		unlinkRequired := false
-
-		if d.HasChange("location") {
-			oldLoc_r, _ := d.GetChange("location")
-			oldLoc := oldLoc_r.(string)
-			setValue("location_id", 0, d, &diag.Diagnostics{})
-			if oldLoc != "" {
-				var diags diag.Diagnostics
-				unlinkRequired = true
-				if len(diags) > 0 {
-					return diags
-				}
-				if unlinkRequired {
-					err = c.UnlinkServer(ctx, id)
-					if err != nil {
-						return diag.FromErr(err)
-					}
-				}
-			}
-		}
-
-		if d.HasChange("location_id") {
-			oldLoc_r, _ := d.GetChange("location_id")
-			oldLoc := oldLoc_r.(int)
-			if oldLoc != 0 {
-				unlinkRequired = true
-			}
-
-			if unlinkRequired {
-				err = c.UnlinkServer(ctx, id)
-				if err != nil {
-					return diag.FromErr(err)
-				}
+		// unlink if changing location
+		if hasChanges(changed, "location") && state.Location.ValueString() != "" ||
+			hasChanges(changed, "location_id") && state.LocationID.ValueInt64() != 0 {
+			err = unlinkServer(ctx, c, id)
+			if err != nil {
+				return apiErrorDiag(err)
 			}
 		}
 
 		// Get correct build params
-		locationId, imageId, diags := getParams(ctx, d, c)
+		locationId, imageId, diags := getParams(ctx, c, plan)
 		if diags != nil {
 			return diags
 		}
+		create := serverCreateRequest(plan, locationId, imageId)
 		req := &gona.BuildServerRequest{
-			Plan:                     d.Get("plan").(string),
-			Location:                 locationId,
-			Image:                    imageId,
-			FQDN:                     d.Get("hostname").(string),
-			SSHKey:                   d.Get("ssh_key").(string),
-			SSHKeyID:                 d.Get("ssh_key_id").(int),
-			Password:                 d.Get("password").(string),
-			PackageBilling:           d.Get("package_billing").(string),
-			PackageBillingContractId: d.Get("package_billing_contract_id").(string),
-			CloudConfig:              base64.StdEncoding.EncodeToString([]byte(d.Get("cloud_config").(string))),
-			ScriptContent:            base64.StdEncoding.EncodeToString([]byte(d.Get("user_data").(string))),
-			Params:                   d.Get("params").(string), // Handle the new params field
-		}
-
-		if userData64, ok := d.GetOk("user_data_base64"); ok {
-			req.ScriptContent = userData64.(string)
+			Plan:                     create.Plan,
+			Location:                 create.Location,
+			Image:                    create.Image,
+			FQDN:                     create.FQDN,
+			SSHKey:                   create.SSHKey,
+			SSHKeyID:                 create.SSHKeyID,
+			Password:                 create.Password,
+			PackageBilling:           create.PackageBilling,
+			PackageBillingContractId: create.PackageBillingContractId,
+			CloudConfig:              create.CloudConfig,
+			ScriptContent:            create.ScriptContent,
+			Params:                   create.Params,
 		}
 
 		// Rebuild server with potentially updated params
-		_, err = c.BuildServer(ctx, id, req)
+		b, err := c.BuildServer(ctx, id, req)
 		if err != nil {
-			return diag.FromErr(err)
+			return apiErrorDiag(err)
 		}
+		plan.BuildID = types.Int64Value(int64(b.Build))
+		plan.LastBuild = types.StringValue(b.Status)
 
-		// Update the params in the state file if they were changed and server rebuilt
-		if d.HasChange("params") {
-			d.Set("params", req.Params)
+		if wait {
+			if diags := waitForBuild(ctx, id, b.Build, c, timeout); diags.HasError() {
+				return diags
+			}
 		}
+	}
 
-		if _, err := wait4Status(ctx, id, "RUNNING", c); err != nil {
-			return err
+	// A rebuilt server comes back powered on.
+	want := plan.PowerState.ValueString()
+	if want != "" && (hasChanges(changed, "power_state") || rebuild && want == powerStateOff) {
+		if diags := setPowerState(ctx, c, id, want, timeout); diags.HasError() {
+			return diags
 		}
 	}
 
-	return resourceServerRead(ctx, d, m)
-}
+	server, err := c.GetServer(ctx, id)
+	if err != nil {
+		return append(diags, apiErrorDiag(err)...)
+	}
+	setServerComputed(plan, server)
+	if diags := setServerOSType(ctx, c, plan, server.OSID); diags.HasError() {
+		return diags
+	}
+	if diags := setServerIPs(ctx, c, plan, id); diags.HasError() {
+		return diags
+	}
+	setExpiry(plan, c.pollClock().Now())
+	plan.resolveUnknowns()
 
-func resourceServerDelete(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	c := m.(*gona.Client)
+	return diags
+}
 
-	id, err := strconv.Atoi(d.Id())
+// deleteServer records the final state of a server, if configured, and
+// terminates it.
+func deleteServer(ctx context.Context, c *Client, m *serverBaseModel) diag.Diagnostics {
+	id, err := parseResourceID(m.ID.ValueString())
 	if err != nil {
-		return diag.FromErr(err)
+		return errDiag(CodeInvalidResourceID, err)
 	}
 
-	err = c.DeleteServer(ctx, id, true)
+	if path := m.FinalStateFile.ValueString(); path != "" {
+		if err := recordFinalState(ctx, c, id, path); err != nil {
+			return errorDiag(CodeFinalStateNotRecorded, "Unable to record the final state of server %d to %s: %s", id, path, redactAPIKey(err.Error()))
+		}
+	}
+
+	return terminateServer(ctx, c, m, id, m.timeout("delete"))
+}
+
+// terminateServer deletes the server id as configured in m: its billing
+// package is cancelled unless cancel_billing_on_destroy is false, or with
+// unlink_only unlinked from the server once it is terminated.
+func terminateServer(ctx context.Context, c *Client, m *serverBaseModel, id int, timeout time.Duration) diag.Diagnostics {
+	unlink := m.UnlinkOnly.ValueBool()
+	err := c.DeleteServer(ctx, id, !unlink && cancelBilling(m))
+	if IsNotFound(err) {
+		return nil
+	}
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiag(err)
 	}
 
 	// await termination
-	if _, err := wait4Status(ctx, id, "TERMINATED", c); err != nil {
+	if _, err := wait4Status(ctx, id, "TERMINATED", c, timeout); err != nil {
 		return err
 	}
+
+	if unlink {
+		if err := unlinkServer(ctx, c, id); err != nil {
+			return apiErrorDiag(err)
+		}
+	}
 	return nil
 }
 
-func wait4Status(ctx context.Context, serverId int, status string, client *gona.Client) (server gona.Server, d diag.Diagnostics) {
-	for i := range tries {
-		server, err := client.GetServer(ctx, serverId)
+// cancelBilling reports whether destroying the server cancels its billing
+// package. State written before cancel_billing_on_destroy existed has no
+// value, the package was always cancelled then.
+func cancelBilling(m *serverBaseModel) bool {
+	return m.CancelBillingOnDestroy.IsNull() || m.CancelBillingOnDestroy.ValueBool()
+}
 
-		// Special-case deletion: when waiting for TERMINATED, treat either a real
-		// TERMINATED or a blank status (due to the 422/invalid-mbpkgid) as success.
-		if status == "TERMINATED" && err == nil && (server.ServerStatus == status || server.ServerStatus == "") {
-			return server, nil
-		}
+// serverChanges returns the names of the attributes whose planned value
+// differs from the state in a way that matters to the API. Null and zero
+// values are the same, SDK v2 versions of the resource stored either, and a
+// location is only changed by naming another one.
+func serverChanges(plan, state *serverBaseModel) []string {
+	location := plan.Location.IsUnknown() ||
+		plan.Location.ValueString() != "" && !sameLocation(plan.Location.ValueString(), state.Location.ValueString())
 
-		if err != nil && i >= 5 {
-			// Retry errors on first few attempts, since sometimes calling GetServer
-			// immediately after creating a server returns an error
-			// ("mbpkgid must be a valid mbpkgid").
-			return server, diag.FromErr(err)
+	// A configured image name or family is resolved to image_id while
+	// planning, only a different image rebuilds the server. The names are
+	// compared before it is resolved, or if the state has no image_id yet.
+	image := stringChanged(plan.Image, state.Image)
+	family := stringChanged(plan.ImageFamily, state.ImageFamily)
+	imageID := int64Changed(plan.ImageID, state.ImageID)
+	if isSet(plan.Image) || isSet(plan.ImageFamily) {
+		if !plan.ImageID.IsUnknown() && isSet(state.ImageID) {
+			image, family = false, false
+		} else {
+			imageID = false
 		}
+	} else if isSet(plan.ImageID) && isSet(state.ImageID) {
+		// Configuring image_id instead of a name, e.g. of a state upgraded
+		// from SDK v2, only rebuilds the server for another image.
+		image, family = false, false
+	}
 
-		if err == nil && server.ServerStatus == status {
-			return server, nil
-		}
+	changes := []struct {
+		name    string
+		changed bool
+	}{
+		{"hostname", stringChanged(plan.Hostname, state.Hostname)},
+		{"location", location},
+		{"location_id", int64Changed(plan.LocationID, state.LocationID)},
+		{"image", image},
+		{"image_family", family},
+		{"image_id", imageID},
+		{"params", stringChanged(plan.Params, state.Params)},
+		{"cloud_config", stringChanged(plan.CloudConfig, state.CloudConfig)},
+		{"rebuild_trigger", stringChanged(plan.RebuildTrigger, state.RebuildTrigger)},
+		{"user_data", stringChanged(plan.UserData, state.UserData)},
+		{"user_data_base64", stringChanged(plan.UserDataBase64, state.UserDataBase64)},
+		{"user_data_wo_version", int64Changed(plan.UserDataWOVersion, state.UserDataWOVersion)},
+		{"power_state", stringChanged(plan.PowerState, state.PowerState)},
+	}
 
-		time.Sleep(intervalSec * time.Second)
+	var changed []string
+	for _, c := range changes {
+		if c.changed {
+			changed = append(changed, c.name)
+		}
 	}
+	return changed
+}
 
-	return server, diag.Errorf("Timeout of waiting the server to obtain %q status", status)
+func stringChanged(plan, state types.String) bool {
+	return plan.IsUnknown() || plan.ValueString() != state.ValueString()
 }
 
-func getParams(ctx context.Context, d *schema.ResourceData, client *gona.Client) (int, int, diag.Diagnostics) {
-	var diags diag.Diagnostics
-	locationId, ld := getLocation(ctx, d, client)
-	if ld != nil {
-		diags = append(diags, *ld)
+func int64Changed(plan, state types.Int64) bool {
+	return plan.IsUnknown() || plan.ValueInt64() != state.ValueInt64()
+}
+
+// isSet reports whether an attribute holds a value, unknown ones are about
+// to. Zero values count as unset, like with GetOk of SDK v2.
+func isSet(v attr.Value) bool {
+	if v.IsUnknown() {
+		return true
 	}
-	imageId, exists := d.GetOk("image_id")
-	if !exists {
-		image, d := getImageByName(ctx, d.Get("image").(string), client)
-		if d != nil {
-			diags = append(diags, *d)
-		} else {
-			imageId = image.ID
-		}
+	if v.IsNull() {
+		return false
+	}
+	switch v := v.(type) {
+	case types.String:
+		return v.ValueString() != ""
+	case locationValue:
+		return v.ValueString() != ""
+	case types.Int64:
+		return v.ValueInt64() != 0
+	default:
+		return true
 	}
+}
+
+// hasChanges reports whether any of keys is in changed.
+func hasChanges(changed []string, keys ...string) bool {
+	return slices.ContainsFunc(keys, func(key string) bool {
+		return slices.Contains(changed, key)
+	})
+}
 
-	return locationId, imageId.(int), diags
+// relocates reports whether a rebuild moves the server to another location
+// by building a new server there first, see relocateServer.
+func relocates(changed []string, allowRelocation bool) bool {
+	return allowRelocation && hasChanges(changed, locationKeys...)
 }
 
-func getLocation(ctx context.Context, d *schema.ResourceData, client *gona.Client) (int, *diag.Diagnostic) {
-	locationId, exists := d.GetOk("location_id")
-	if exists {
-		return locationId.(int), nil
+// needsRebuild reports whether the changed attributes require the server
+// to be rebuilt.
+func needsRebuild(changed []string, rebuildOnUserDataChange bool) bool {
+	if hasChanges(changed, rebuildKeys...) {
+		return true
 	}
+	return rebuildOnUserDataChange && hasChanges(changed, userDataKeys...)
+}
 
-	requestLocation := d.Get("location").(string)
-	if requestLocation == "" {
-		return 0, &diag.Errorf("Please provide a location or location_id")[0]
+func unlinkServer(ctx context.Context, c *Client, id int) error {
+	if err := c.require(CapabilityServerUnlink); err != nil {
+		return err
 	}
+	return c.UnlinkServer(ctx, id)
+}
 
-	locations, err := client.GetLocations(ctx)
-	if err != nil {
-		return 0, &diag.FromErr(err)[0]
+// serverPoller is the part of the client wait4Status depends on.
+type serverPoller interface {
+	GetServer(ctx context.Context, id int) (gona.Server, error)
+	pollClock() clock
+}
+
+// waitForRunning reports whether applies should block until the server is
+// RUNNING, wait_for_running defaults to true.
+func waitForRunning(wait types.Bool) bool {
+	return wait.IsNull() || wait.IsUnknown() || wait.ValueBool()
+}
+
+// wait4Status waits for the server to obtain status, see pollServerStatus.
+func wait4Status(ctx context.Context, serverId int, status string, client serverPoller, timeout time.Duration) (gona.Server, diag.Diagnostics) {
+	server, err := pollServerStatus(ctx, serverId, status, client, timeout)
+	return server, waitDiags(server, status, timeout, err)
+}
+
+// waitForBuild waits for a created or rebuilt server to be RUNNING. A
+// server still building once timeout has elapsed is reported with the ID
+// of its build, so it can be followed up with NetActuate.
+func waitForBuild(ctx context.Context, serverId, buildID int, client serverPoller, timeout time.Duration) diag.Diagnostics {
+	server, err := pollServerStatus(ctx, serverId, "RUNNING", client, timeout)
+	if errors.Is(err, errWaitTimeout) && server.ServerStatus != "" {
+		return diag.Diagnostics{codedDiag(diag.Error, CodeServerBuildStuck,
+			fmt.Sprintf("Server %d is stuck in %s status", serverId, server.ServerStatus),
+			fmt.Sprintf("Build %d didn't finish within %s. Contact NetActuate support with the build ID, "+
+				"or raise the timeout and taint the server to build it again.", buildID, timeout),
+		)}
+	}
+	return waitDiags(server, "RUNNING", timeout, err)
+}
+
+// waitDiags converts the error of pollServerStatus into diagnostics.
+func waitDiags(server gona.Server, status string, timeout time.Duration, err error) diag.Diagnostics {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errWaitTimeout), errors.Is(err, context.DeadlineExceeded):
+		detail := ""
+		if server.ServerStatus != "" {
+			detail = fmt.Sprintf("The server was still %s after %s.", server.ServerStatus, timeout)
+		}
+		return diag.Diagnostics{codedDiag(diag.Error, CodeServerStatusTimeout,
+			fmt.Sprintf("Timeout of waiting the server to obtain %q status", status), detail)}
+	case errors.Is(err, context.Canceled):
+		return diag.Diagnostics{codedDiag(diag.Error, CodeServerStatusTimeout,
+			fmt.Sprintf("Cancelled waiting for the server to obtain %q status", status),
+			"The operation was interrupted, the server keeps changing in the background. Refresh to see its current state.")}
+	default:
+		return apiErrorDiag(err)
 	}
+}
+
+// pollServerStatus polls the server until it has status, returning the
+// server as last seen. It gives up with errWaitTimeout once timeout has
+// elapsed, or with the context error once ctx is done, and logs its
+// progress every waitProgressInterval.
+func pollServerStatus(ctx context.Context, serverId int, status string, client serverPoller, timeout time.Duration) (server gona.Server, err error) {
+	clk := client.pollClock()
+	start := clk.Now()
+	lastProgress := start
+	attempt := 0
 
-	for _, location := range locations {
-		if location.Name == requestLocation {
-			return location.ID, nil
+	err = waitFor(ctx, clk, intervalSec*time.Second, timeout, func() (bool, error) {
+		s, err := client.GetServer(ctx, serverId)
+		attempt++
+
+		if now := clk.Now(); now.Sub(lastProgress) >= waitProgressInterval {
+			lastProgress = now
+			tflog.Info(ctx, "Waiting for server status", map[string]any{
+				"mbpkgid": serverId,
+				"status":  s.ServerStatus,
+				"want":    status,
+				"elapsed": now.Sub(start).Round(time.Second).String(),
+			})
 		}
-		if strings.EqualFold(strings.Fields(location.Name)[0], strings.Fields(requestLocation)[0]) {
-			return location.ID, nil
+
+		// Special-case deletion: when waiting for TERMINATED, treat either a real
+		// TERMINATED or a blank status (due to the 422/invalid-mbpkgid) as success.
+		if status == "TERMINATED" && err == nil && (s.ServerStatus == status || s.ServerStatus == "") {
+			server = s
+			return true, nil
 		}
-	}
 
-	return 0, &diag.Errorf("Provided location %q doesn't exist", locationId)[0]
+		if err != nil {
+			// Retry errors on first few attempts, since sometimes calling GetServer
+			// immediately after creating a server returns an error
+			// ("mbpkgid must be a valid mbpkgid"). Transient failures are retried
+			// for as long as we are willing to wait.
+			if attempt > 5 && !IsRetryable(err) {
+				return false, err
+			}
+			return false, nil
+		}
+
+		server = s
+		return s.ServerStatus == status, nil
+	})
+	return server, err
 }
 
-func getImageByName(ctx context.Context, name string, client *gona.Client) (*gona.OS, *diag.Diagnostic) {
-	oss, err := client.GetOSs(ctx)
-	if err != nil {
-		return nil, &diag.FromErr(err)[0]
+// getParams resolves the location and image of the server to their IDs.
+func getParams(ctx context.Context, client *Client, m *serverBaseModel) (int, int, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	locationId, ld := getLocation(ctx, client, m)
+	if ld != nil {
+		diags = append(diags, *ld)
 	}
 
-	for _, os := range oss {
-		if os.Os == name {
-			return &os, nil
+	imageId := int(m.ImageID.ValueInt64())
+	if imageId == 0 {
+		image, d := resolveImage(ctx, client, m.Image.ValueString(), m.ImageFamily.ValueString())
+		if d.HasError() {
+			diags = append(diags, d...)
+		} else {
+			imageId = image.ID
+			m.ImageID = types.Int64Value(int64(imageId))
 		}
 	}
 
-	return nil, &diag.Errorf("Provided image %q doesn't exist", name)[0]
+	return locationId, imageId, diags
+}
+
+func getLocation(ctx context.Context, client *Client, m *serverBaseModel) (int, *diag.Diagnostic) {
+	if locationId := int(m.LocationID.ValueInt64()); locationId != 0 {
+		return locationId, nil
+	}
+
+	requestLocation := m.Location.ValueString()
+	if requestLocation == "" {
+		return 0, &errorDiag(CodeLocationRequired, "Please provide a location or location_id")[0]
+	}
+
+	location, diags := findLocation(ctx, client, 0, requestLocation)
+	if diags.HasError() {
+		return 0, &diags[0]
+	}
+	return location.ID, nil
 }
@@ -1,11 +1,30 @@
 package netactuate
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/netactuate/gona/gona"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHostnameRegex(t *testing.T) {
@@ -121,3 +140,683 @@ func BenchmarkHostnameRegex(b *testing.B) {
 		}
 	})
 }
+
+func FuzzValidateHostname(f *testing.F) {
+	for _, seed := range []string{
+		"a", "example.com", "web-01.prod-us-east-1.example.com", "a--b",
+		"", "-server", "server-", ".example", "example..com", "example_com",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, hostname string) {
+		resp := &validator.StringResponse{}
+		hostnameValidator{}.ValidateString(context.Background(), validator.StringRequest{ConfigValue: types.StringValue(hostname)}, resp)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		// Anything accepted must be a dot separated list of non-empty labels
+		// made of alphanumerics and inner hyphens only.
+		for _, label := range strings.Split(hostname, ".") {
+			if label == "" {
+				t.Fatalf("accepted hostname %q with an empty label", hostname)
+			}
+			if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+				t.Fatalf("accepted hostname %q with label %q starting or ending with a hyphen", hostname, label)
+			}
+			for _, r := range label {
+				if !(r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+					t.Fatalf("accepted hostname %q containing invalid character %q", hostname, r)
+				}
+			}
+		}
+	})
+}
+
+func TestHostnameValidator(t *testing.T) {
+	resp := &validator.StringResponse{}
+	hostnameValidator{}.ValidateString(context.Background(), validator.StringRequest{
+		Path:        path.Root("hostname"),
+		ConfigValue: types.StringValue("web_01"),
+	}, resp)
+	require.True(t, resp.Diagnostics.HasError())
+	assert.Equal(t, `[NA1001] "web_01" is not a valid hostname`, resp.Diagnostics[0].Summary())
+}
+
+func TestNeedsRebuild(t *testing.T) {
+	tests := []struct {
+		desc    string
+		rebuild bool
+		changed []string
+		want    bool
+	}{
+		{"no changes", true, nil, false},
+		{"image change", false, []string{"image_id"}, true},
+		{"hostname change", false, []string{"hostname"}, true},
+		{"rebuild_trigger change", false, []string{"rebuild_trigger"}, true},
+		{"user_data change ignored", false, []string{"user_data"}, false},
+		{"user_data change rebuilds", true, []string{"user_data"}, true},
+		{"user_data_base64 change rebuilds", true, []string{"user_data_base64"}, true},
+		{"user_data_wo_version change rebuilds", true, []string{"user_data_wo_version"}, true},
+		{"unrelated change", true, []string{"package_billing"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, needsRebuild(tt.changed, tt.rebuild))
+		})
+	}
+}
+
+func TestServerChanges(t *testing.T) {
+	tests := []struct {
+		desc   string
+		modify func(plan, state *serverBaseModel)
+		want   []string
+	}{
+		{"no changes", func(_, _ *serverBaseModel) {}, nil},
+		{"hostname", func(plan, _ *serverBaseModel) {
+			plan.Hostname = types.StringValue("web02.example.com")
+		}, []string{"hostname"}},
+		{"empty params stored by SDK v2", func(_, state *serverBaseModel) {
+			state.Params = types.StringValue("")
+		}, nil},
+		{"location full name", func(plan, state *serverBaseModel) {
+			plan.Location = newLocationValue("AMS - Amsterdam, NL")
+			state.Location = newLocationValue("ams")
+		}, nil},
+		{"location", func(plan, state *serverBaseModel) {
+			plan.Location = newLocationValue("FRA")
+			state.Location = newLocationValue("AMS")
+		}, []string{"location"}},
+		{"unknown image", func(plan, _ *serverBaseModel) {
+			plan.ImageID = types.Int64Unknown()
+		}, []string{"image_id"}},
+		{"image renamed to the same image", func(plan, state *serverBaseModel) {
+			plan.Image = types.StringValue("ubuntu-22.04")
+			state.Image = types.StringValue("Ubuntu 22.04 LTS x64")
+		}, nil},
+		{"image family resolved to a newer image", func(plan, state *serverBaseModel) {
+			plan.ImageFamily, state.ImageFamily = types.StringValue("ubuntu-lts"), types.StringValue("ubuntu-lts")
+			plan.ImageID = types.Int64Value(1200)
+		}, []string{"image_id"}},
+		{"image not resolved yet", func(plan, state *serverBaseModel) {
+			plan.Image, state.Image = types.StringValue("debian"), types.StringValue("ubuntu")
+			plan.ImageID = types.Int64Unknown()
+		}, []string{"image"}},
+		{"image without image_id in state", func(plan, state *serverBaseModel) {
+			plan.Image, state.Image = types.StringValue("ubuntu"), types.StringValue("ubuntu")
+			state.ImageID = types.Int64Null()
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			plan, state := testServerModel("C-1001"), testServerModel("C-1001")
+			plan.PowerState, state.PowerState = types.StringValue(powerStateOn), types.StringValue(powerStateOn)
+			tt.modify(&plan, &state)
+			assert.Equal(t, tt.want, serverChanges(&plan, &state))
+		})
+	}
+}
+
+// testServerModel returns a planned server billed to contractID.
+func testServerModel(contractID string) serverBaseModel {
+	return serverBaseModel{
+		ID:                       types.StringUnknown(),
+		Hostname:                 types.StringValue("web01.example.com"),
+		Plan:                     types.StringValue("VR1x1x25"),
+		PackageBilling:           types.StringValue("usage"),
+		PackageBillingOptIn:      types.StringNull(),
+		PackageBillingContractID: types.StringValue(contractID),
+		Location:                 locationValue{StringValue: types.StringNull()},
+		LocationID:               types.Int64Value(3),
+		Image:                    types.StringNull(),
+		ImageID:                  types.Int64Value(1000),
+		Password:                 types.StringNull(),
+		SSHKeyID:                 types.Int64Value(7),
+		SSHKey:                   types.StringNull(),
+		CloudConfig:              types.StringNull(),
+		UserData:                 types.StringNull(),
+		UserDataBase64:           types.StringNull(),
+		RebuildOnUserDataChange:  types.BoolValue(false),
+		AllowRelocation:          types.BoolValue(false),
+		RebuildTrigger:           types.StringNull(),
+		PowerState:               types.StringUnknown(),
+		InstallComplete:          types.BoolUnknown(),
+		ServerStatus:             types.StringUnknown(),
+		PowerStatus:              types.StringUnknown(),
+		Installed:                types.BoolUnknown(),
+		PrimaryIPv4:              types.StringUnknown(),
+		PrimaryIPv6:              types.StringUnknown(),
+		RefreshIPs:               types.BoolValue(true),
+		IPv4Addresses:            types.ListUnknown(types.StringType),
+		IPv6Addresses:            types.ListUnknown(types.StringType),
+		Params:                   types.StringNull(),
+		BuildID:                  types.Int64Unknown(),
+		LastBuild:                types.StringUnknown(),
+		FinalStateFile:           types.StringNull(),
+	}
+}
+
+// newTestServerResource returns a netactuate_server resource using fake and
+// its schema.
+func newTestServerResource(t *testing.T, fake *FakeClient) (*ServerResource, resource.SchemaResponse) {
+	t.Helper()
+
+	r := &ServerResource{}
+	resp := &resource.ConfigureResponse{}
+	r.Configure(context.Background(), resource.ConfigureRequest{ProviderData: newFakeAPIClient(fake)}, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(context.Background(), resource.SchemaRequest{}, &schemaResp)
+	require.False(t, schemaResp.Diagnostics.HasError(), "%v", schemaResp.Diagnostics)
+
+	return r, schemaResp
+}
+
+// testPlan returns the plan holding model.
+func testPlan(t *testing.T, s resource.SchemaResponse, model any) tfsdk.Plan {
+	t.Helper()
+
+	plan := tfsdk.Plan{Schema: s.Schema, Raw: tftypes.NewValue(s.Schema.Type().TerraformType(context.Background()), nil)}
+	diags := plan.Set(context.Background(), model)
+	require.False(t, diags.HasError(), "%v", diags)
+	return plan
+}
+
+func TestServerResourceRead_NotFound(t *testing.T) {
+	r, s := newTestServerResource(t, NewFakeClient())
+
+	model := serverResourceModel{serverBaseModel: testServerModel("C-1001")}
+	model.ID = types.StringValue("999")
+	state := tfsdk.State{Schema: s.Schema, Raw: testPlan(t, s, &model).Raw}
+
+	resp := &resource.ReadResponse{State: state}
+	r.Read(context.Background(), resource.ReadRequest{State: state}, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+	assert.True(t, resp.State.Raw.IsNull(), "a deleted server should be removed from state")
+}
+
+func TestServerResourceRead_Removed(t *testing.T) {
+	// The API's answer for a deleted server, which gona turns into a
+	// server without a status.
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = io.WriteString(w, `{"result": "error", "code": 422, "message": "Validation failed", `+
+			`"fields": {"mbpkgid": "mbpkgid must be a valid mbpkgid"}}`)
+	}))
+	t.Cleanup(api.Close)
+	deleted, err := NewClient("test-api-key", api.URL+"/api/", DefaultAPIVersion)
+	require.NoError(t, err)
+
+	fake := NewFakeClient()
+	terminated := fake.AddServer(gona.Server{Name: "web01.example.com", ServerStatus: "TERMINATED", Installed: 1}).LastServerID()
+
+	tests := map[string]struct {
+		client *Client
+		id     int
+	}{
+		"invalid mbpkgid": {client: deleted, id: 999},
+		"terminated":      {client: newFakeAPIClient(fake), id: terminated},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &ServerResource{}
+			configured := &resource.ConfigureResponse{}
+			r.Configure(context.Background(), resource.ConfigureRequest{ProviderData: tt.client}, configured)
+			require.False(t, configured.Diagnostics.HasError(), "%v", configured.Diagnostics)
+			var s resource.SchemaResponse
+			r.Schema(context.Background(), resource.SchemaRequest{}, &s)
+
+			model := serverResourceModel{serverBaseModel: testServerModel("C-1001")}
+			model.ID = types.StringValue(strconv.Itoa(tt.id))
+			state := tfsdk.State{Schema: s.Schema, Raw: testPlan(t, s, &model).Raw}
+
+			resp := &resource.ReadResponse{State: state}
+			r.Read(context.Background(), resource.ReadRequest{State: state}, resp)
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+			assert.True(t, resp.State.Raw.IsNull(), "a deleted server should be removed from state")
+		})
+	}
+}
+
+func TestResourceServerCreate_BillingContract(t *testing.T) {
+	fake := NewFakeClient()
+	fake.AddContract("C-1001")
+
+	m := testServerModel("C-1001")
+	diags := createServer(context.Background(), newFakeAPIClient(fake), &m, true)
+	require.Empty(t, diags)
+
+	id, err := parseResourceID(m.ID.ValueString())
+	require.NoError(t, err)
+	server, ok := fake.Server(id)
+	require.True(t, ok)
+	assert.Equal(t, "usage", server.PackageBilling)
+	assert.Equal(t, "C-1001", server.PackageBillingContractId)
+	assert.Equal(t, server.PrimaryIPv4, m.PrimaryIPv4.ValueString())
+}
+
+func TestResourceServerCreate_WaitsForBuild(t *testing.T) {
+	fake := NewFakeClient()
+	fake.AddContract("C-1001")
+	fake.BuildDelaySteps = 3
+
+	m := testServerModel("C-1001")
+	require.Empty(t, createServer(context.Background(), newFakeAPIClient(fake), &m, true))
+
+	id, err := parseResourceID(m.ID.ValueString())
+	require.NoError(t, err)
+	polls := slices.DeleteFunc(fake.GetCalls(), func(call string) bool { return call != fmt.Sprintf("GetServer(%d)", id) })
+	assert.GreaterOrEqual(t, len(polls), 4, "the server should be polled until it is built")
+	assert.Equal(t, "RUNNING", m.ServerStatus.ValueString())
+	assert.True(t, m.Installed.ValueBool())
+}
+
+func TestResourceServerCreate_BuildStuck(t *testing.T) {
+	fake := NewFakeClient()
+	fake.AddContract("C-1001")
+	fake.BuildDelaySteps = math.MaxInt
+
+	m := testServerModel("C-1001")
+	diags := createServer(context.Background(), newFakeAPIClient(fake), &m, true)
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "is stuck in BUILDING status")
+}
+
+func TestResourceServerCreate_CloudPool(t *testing.T) {
+	fake := NewFakeClient()
+	fake.AddContract("C-1001")
+
+	m := testServerModel("C-1001")
+	m.CloudPool = types.StringUnknown()
+	require.Empty(t, createServer(context.Background(), newFakeAPIClient(fake), &m, true))
+	assert.Equal(t, "Default", m.CloudPool.ValueString(), "the pool the API picked is recorded")
+
+	m = testServerModel("C-1001")
+	m.CloudPool = types.StringValue("AMD EPYC")
+	require.Empty(t, createServer(context.Background(), newFakeAPIClient(fake), &m, true))
+
+	id, err := parseResourceID(m.ID.ValueString())
+	require.NoError(t, err)
+	server, ok := fake.Server(id)
+	require.True(t, ok)
+	assert.Equal(t, "AMD EPYC", server.CloudPool)
+}
+
+func TestResourceServerCreate_UnknownBillingContract(t *testing.T) {
+	fake := NewFakeClient()
+	fake.AddContract("C-1001")
+
+	m := testServerModel("C-9999")
+	diags := createServer(context.Background(), newFakeAPIClient(fake), &m, true)
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Detail, `invalid package_billing_contract_id "C-9999"`)
+	assert.True(t, m.ID.IsUnknown())
+
+	servers, err := fake.GetServers(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, servers)
+}
+
+func TestResourceServerCreate_BillingValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(m *serverBaseModel)
+		wantErr string
+	}{
+		{
+			name:    "usage without contract",
+			modify:  func(m *serverBaseModel) { m.PackageBillingContractID = types.StringNull() },
+			wantErr: "package_billing_contract_id must be set",
+		},
+		{
+			name:    "package without opt-in",
+			modify:  func(m *serverBaseModel) { m.PackageBilling = types.StringValue("package") },
+			wantErr: "package_billing_opt_in must be set to yes",
+		},
+		{
+			name: "package with opt-out",
+			modify: func(m *serverBaseModel) {
+				m.PackageBilling = types.StringValue("package")
+				m.PackageBillingContractID = types.StringNull()
+				m.PackageBillingOptIn = types.StringValue("no")
+			},
+			wantErr: "package_billing_opt_in must be set to yes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFakeClient()
+			fake.AddContract("C-1001")
+
+			m := testServerModel("C-1001")
+			tt.modify(&m)
+
+			diags := createServer(context.Background(), newFakeAPIClient(fake), &m, true)
+			require.True(t, diags.HasError())
+			assert.Contains(t, diags[0].Summary, tt.wantErr)
+			assert.NotContains(t, fake.GetCalls(), "CreateServer(web01.example.com)")
+		})
+	}
+}
+
+func TestResourceServerRead_BillingContractDrift(t *testing.T) {
+	fake := NewFakeClient()
+	fake.AddContract("C-1001")
+	c := newFakeAPIClient(fake)
+
+	m := testServerModel("C-1001")
+	require.Empty(t, createServer(context.Background(), c, &m, true))
+
+	// The package was moved to another contract outside of Terraform.
+	id, err := parseResourceID(m.ID.ValueString())
+	require.NoError(t, err)
+	server, _ := fake.Server(id)
+	server.PackageBillingContractId = "C-2002"
+	fake.AddServer(server)
+
+	require.Empty(t, readServer(context.Background(), c, &m))
+	assert.Equal(t, "C-2002", m.PackageBillingContractID.ValueString())
+}
+
+func TestServerResourceCreate_WaitForRunning(t *testing.T) {
+	tests := []struct {
+		name           string
+		waitForRunning bool
+		wantGetServer  int
+	}{
+		{name: "wait", waitForRunning: true, wantGetServer: 2},
+		{name: "no wait", waitForRunning: false, wantGetServer: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFakeClient()
+			fake.AddContract("C-1001")
+			r, s := newTestServerResource(t, fake)
+
+			plan := testPlan(t, s, &serverResourceModel{
+				serverBaseModel: testServerModel("C-1001"),
+				WaitForRunning:  types.BoolValue(tt.waitForRunning),
+			})
+			resp := &resource.CreateResponse{State: tfsdk.State{Schema: s.Schema}}
+			r.Create(context.Background(), resource.CreateRequest{Config: tfsdk.Config{Schema: s.Schema, Raw: plan.Raw}, Plan: plan}, resp)
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+			require.True(t, resp.State.Raw.IsFullyKnown(), "state must not hold unknown values")
+
+			var state serverResourceModel
+			require.False(t, resp.State.Get(context.Background(), &state).HasError())
+			getServer := fmt.Sprintf("GetServer(%s)", state.ID.ValueString())
+			calls := slices.DeleteFunc(fake.GetCalls(), func(call string) bool { return call != getServer })
+			assert.Len(t, calls, tt.wantGetServer)
+		})
+	}
+}
+
+func TestServerResourceModifyPlan_Rebuild(t *testing.T) {
+	r, s := newTestServerResource(t, NewFakeClient())
+
+	state := serverResourceModel{serverBaseModel: testServerModel("C-1001"), WaitForRunning: types.BoolValue(true)}
+	state.ID = types.StringValue("101")
+	state.PowerState = types.StringValue(powerStateOn)
+	state.InstallComplete = types.BoolValue(true)
+	state.ServerStatus = types.StringValue("RUNNING")
+	state.PowerStatus = types.StringValue("RUNNING")
+	state.Installed = types.BoolValue(true)
+	state.PrimaryIPv4 = types.StringValue("192.0.2.10")
+	state.PrimaryIPv6 = types.StringValue("2001:db8::10")
+	state.BuildID = types.Int64Value(1)
+	state.LastBuild = types.StringValue("ok")
+
+	// Prior state was copied into the plan by UseStateForUnknown.
+	planned := state
+	planned.ImageID = types.Int64Value(1200)
+	config := planned
+	config.ID, config.PowerState, config.InstallComplete = types.StringNull(), types.StringNull(), types.BoolNull()
+	config.ServerStatus, config.PowerStatus, config.Installed = types.StringNull(), types.StringNull(), types.BoolNull()
+
+	req := resource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: s.Schema, Raw: testPlan(t, s, &config).Raw},
+		State:  tfsdk.State{Schema: s.Schema, Raw: testPlan(t, s, &state).Raw},
+		Plan:   testPlan(t, s, &planned),
+	}
+	resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+	r.ModifyPlan(context.Background(), req, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	var plan serverResourceModel
+	require.False(t, resp.Plan.Get(context.Background(), &plan).HasError())
+	assert.Equal(t, "101", plan.ID.ValueString(), "a rebuild keeps the ID")
+	assert.True(t, plan.BuildID.IsUnknown())
+	assert.True(t, plan.LastBuild.IsUnknown())
+	assert.True(t, plan.InstallComplete.IsUnknown())
+	assert.True(t, plan.ServerStatus.IsUnknown())
+	assert.True(t, plan.PowerStatus.IsUnknown())
+	assert.True(t, plan.Installed.IsUnknown())
+	assert.True(t, plan.PrimaryIPv4.IsUnknown())
+	assert.True(t, plan.PowerState.IsUnknown(), "a rebuilt server comes back powered on")
+	assert.Equal(t, int64(3), plan.LocationID.ValueInt64())
+}
+
+func TestServerResourceModifyPlan_PowerState(t *testing.T) {
+	r, s := newTestServerResource(t, NewFakeClient())
+
+	state := serverResourceModel{serverBaseModel: testServerModel("C-1001"), WaitForRunning: types.BoolValue(true)}
+	state.ID = types.StringValue("101")
+	state.PowerState = types.StringValue(powerStateOn)
+	state.InstallComplete = types.BoolValue(true)
+	state.ServerStatus = types.StringValue("RUNNING")
+	state.PowerStatus = types.StringValue("RUNNING")
+	state.Installed = types.BoolValue(true)
+	state.PrimaryIPv4 = types.StringValue("192.0.2.10")
+	state.PrimaryIPv6 = types.StringValue("2001:db8::10")
+	state.BuildID = types.Int64Value(1)
+	state.LastBuild = types.StringValue("ok")
+
+	planned := state
+	planned.PowerState = types.StringValue(powerStateOff)
+
+	req := resource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: s.Schema, Raw: testPlan(t, s, &planned).Raw},
+		State:  tfsdk.State{Schema: s.Schema, Raw: testPlan(t, s, &state).Raw},
+		Plan:   testPlan(t, s, &planned),
+	}
+	resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+	r.ModifyPlan(context.Background(), req, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	var plan serverResourceModel
+	require.False(t, resp.Plan.Get(context.Background(), &plan).HasError())
+	assert.True(t, plan.PowerStatus.IsUnknown(), "a power change changes the reported power status")
+	assert.Equal(t, "RUNNING", plan.ServerStatus.ValueString())
+	assert.True(t, plan.Installed.ValueBool())
+	assert.Equal(t, int64(1), plan.BuildID.ValueInt64(), "a power change doesn't rebuild")
+}
+
+func TestResourceServer_Timeouts(t *testing.T) {
+	m := testServerModel("C-1001")
+	assert.Equal(t, defaultServerTimeout, m.timeout("create"))
+
+	m.Timeouts = &serverTimeoutsModel{Create: types.StringValue("45m")}
+	assert.Equal(t, 45*time.Minute, m.timeout("create"))
+	assert.Equal(t, defaultServerTimeout, m.timeout("update"))
+	assert.Equal(t, defaultServerTimeout, m.timeout("delete"))
+}
+
+func TestResourceServerRead_InstallComplete(t *testing.T) {
+	tests := []struct {
+		name          string
+		server        gona.Server
+		want          bool
+		wantInstalled bool
+	}{
+		{name: "installed", server: gona.Server{ServerStatus: "RUNNING", PowerStatus: "RUNNING", Installed: 1}, want: true, wantInstalled: true},
+		{name: "building", server: gona.Server{ServerStatus: "BUILDING", PowerStatus: "STOPPED", Installed: 0}, want: false},
+		{name: "not installed", server: gona.Server{ServerStatus: "RUNNING", PowerStatus: "RUNNING", Installed: 0}, want: false},
+		{name: "stopped", server: gona.Server{ServerStatus: "RUNNING", PowerStatus: "STOPPED", Installed: 1}, want: true, wantInstalled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFakeClient()
+			tt.server.Location = "Amsterdam, NL"
+			id := fake.AddServer(tt.server).LastServerID()
+
+			m := testServerModel("C-1001")
+			m.ID = types.StringValue(strconv.Itoa(id))
+
+			require.Empty(t, readServer(context.Background(), newFakeAPIClient(fake), &m))
+			assert.Equal(t, tt.want, m.InstallComplete.ValueBool())
+			assert.Equal(t, tt.wantInstalled, m.Installed.ValueBool())
+			assert.Equal(t, tt.server.ServerStatus, m.ServerStatus.ValueString())
+			assert.Equal(t, tt.server.PowerStatus, m.PowerStatus.ValueString())
+		})
+	}
+}
+
+func TestServerResourceImportState(t *testing.T) {
+	fake := NewFakeClient()
+	id := fake.AddServer(gona.Server{Name: "web01.example.com", ServerStatus: "RUNNING"}).LastServerID()
+	fake.AddServer(gona.Server{Name: "dup.example.com", ServerStatus: "RUNNING"}).
+		AddServer(gona.Server{Name: "dup.example.com", ServerStatus: "RUNNING"})
+	r, s := newTestServerResource(t, fake)
+
+	tests := []struct {
+		importID string
+		wantID   string
+		wantErr  string
+	}{
+		{importID: "42", wantID: "42"},
+		{importID: "hostname=web01.example.com", wantID: strconv.Itoa(id)},
+		{importID: "hostname=web02.example.com", wantErr: "[NA1016]"},
+		{importID: "hostname=dup.example.com", wantErr: "[NA1017]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.importID, func(t *testing.T) {
+			resp := &resource.ImportStateResponse{State: tfsdk.State{
+				Schema: s.Schema,
+				Raw:    tftypes.NewValue(s.Schema.Type().TerraformType(context.Background()), nil),
+			}}
+			r.ImportState(context.Background(), resource.ImportStateRequest{ID: tt.importID}, resp)
+			if tt.wantErr != "" {
+				require.True(t, resp.Diagnostics.HasError())
+				assert.Contains(t, resp.Diagnostics[0].Summary(), tt.wantErr)
+				return
+			}
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+			var got string
+			require.False(t, resp.State.GetAttribute(context.Background(), path.Root("id"), &got).HasError())
+			assert.Equal(t, tt.wantID, got)
+		})
+	}
+}
+
+func TestServerResourceCreate_PasswordWO(t *testing.T) {
+	fake := NewFakeClient()
+	fake.AddContract("C-1001")
+	r, s := newTestServerResource(t, fake)
+
+	var password string
+	r.client.Use(func(ctx context.Context, call Call, next Invoker) (any, error) {
+		if call.Method == "CreateServer" {
+			password = call.Args[0].(*gona.CreateServerRequest).Password
+		}
+		return next(ctx)
+	})
+
+	model := serverResourceModel{serverBaseModel: testServerModel("C-1001"), WaitForRunning: types.BoolValue(true)}
+	model.SSHKeyID = types.Int64Null()
+	model.PasswordWO = types.StringValue("s3cr3t")
+	config := tfsdk.Config{Schema: s.Schema, Raw: testPlan(t, s, &model).Raw}
+
+	// Write-only values are always null in the plan.
+	model.PasswordWO = types.StringNull()
+	resp := &resource.CreateResponse{State: tfsdk.State{Schema: s.Schema}}
+	r.Create(context.Background(), resource.CreateRequest{Config: config, Plan: testPlan(t, s, &model)}, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	assert.Equal(t, "s3cr3t", password)
+}
+
+func TestServerResourceCreate_UserDataWO(t *testing.T) {
+	fake := NewFakeClient()
+	fake.AddContract("C-1001")
+	r, s := newTestServerResource(t, fake)
+
+	var script string
+	r.client.Use(func(ctx context.Context, call Call, next Invoker) (any, error) {
+		if call.Method == "CreateServer" {
+			script = call.Args[0].(*gona.CreateServerRequest).ScriptContent
+		}
+		return next(ctx)
+	})
+
+	model := serverResourceModel{serverBaseModel: testServerModel("C-1001"), WaitForRunning: types.BoolValue(true)}
+	model.UserDataWO = types.StringValue("#!/bin/sh\necho s3cr3t > /root/token\n")
+	model.UserDataWOVersion = types.Int64Value(1)
+	config := tfsdk.Config{Schema: s.Schema, Raw: testPlan(t, s, &model).Raw}
+
+	// Write-only values are always null in the plan.
+	model.UserDataWO = types.StringNull()
+	resp := &resource.CreateResponse{State: tfsdk.State{Schema: s.Schema}}
+	r.Create(context.Background(), resource.CreateRequest{Config: config, Plan: testPlan(t, s, &model)}, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("#!/bin/sh\necho s3cr3t > /root/token\n")), script)
+}
+
+func TestDeleteServer_Billing(t *testing.T) {
+	tests := []struct {
+		name          string
+		cancelBilling types.Bool
+		unlinkOnly    types.Bool
+		wantCancel    bool
+		wantUnlink    bool
+	}{
+		{name: "default", cancelBilling: types.BoolValue(true), unlinkOnly: types.BoolValue(false), wantCancel: true},
+		{name: "state without the attributes", cancelBilling: types.BoolNull(), unlinkOnly: types.BoolNull(), wantCancel: true},
+		{name: "keep billing", cancelBilling: types.BoolValue(false), unlinkOnly: types.BoolValue(false)},
+		{name: "unlink only", cancelBilling: types.BoolValue(true), unlinkOnly: types.BoolValue(true), wantUnlink: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFakeClient()
+			id := fake.AddServer(gona.Server{Name: "web01.example.com", Installed: 1, ServerStatus: "RUNNING"}).LastServerID()
+
+			m := testServerModel("C-1001")
+			m.ID = types.StringValue(strconv.Itoa(id))
+			m.CancelBillingOnDestroy, m.UnlinkOnly = tt.cancelBilling, tt.unlinkOnly
+			require.False(t, deleteServer(context.Background(), newFakeAPIClient(fake), &m).HasError())
+
+			assert.Equal(t, []Call{{Method: "DeleteServer", Args: []any{id, tt.wantCancel}}}, fake.CallsTo("DeleteServer"))
+			wantUnlink := []Call{}
+			if tt.wantUnlink {
+				wantUnlink = []Call{{Method: "UnlinkServer", Args: []any{id}}}
+			}
+			assert.Equal(t, wantUnlink, fake.CallsTo("UnlinkServer"))
+		})
+	}
+}
+
+func TestServerOperations_InvalidID(t *testing.T) {
+	ctx := context.Background()
+	c := newFakeAPIClient(NewFakeClient())
+	m := testServerModel("C-1001")
+	m.ID = types.StringValue("web01")
+
+	for name, diags := range map[string]diag.Diagnostics{
+		"read":   readServer(ctx, c, &m),
+		"update": updateServer(ctx, c, &m, &m, true, nil),
+		"delete": deleteServer(ctx, c, &m),
+	} {
+		require.Len(t, diags, 1, name)
+		assert.True(t, strings.HasPrefix(diags[0].Summary, "[NA1002] "), "%s: %s", name, diags[0].Summary)
+	}
+}
@@ -2,129 +2,298 @@ package netactuate
 
 import (
 	"context"
+	"fmt"
 	"strconv"
-	"strings"
 	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netactuate/gona/gona"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func resourceSshKey() *schema.Resource {
-	return &schema.Resource{
-		CreateContext: resourceSshKeyCreate,
-		ReadContext:   resourceSshKeyRead,
-		DeleteContext: resourceSshKeyDelete,
-		UpdateContext: resourceSshKeyUpdate,
-		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
-		},
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+// sshKeyRecreateDelay is how long the API needs after deleting a key before
+// a key with the same name can be created again.
+const sshKeyRecreateDelay = 3 * time.Second
+
+var (
+	_ resource.Resource                     = (*SSHKeyResource)(nil)
+	_ resource.ResourceWithConfigure        = (*SSHKeyResource)(nil)
+	_ resource.ResourceWithImportState      = (*SSHKeyResource)(nil)
+	_ resource.ResourceWithModifyPlan       = (*SSHKeyResource)(nil)
+	_ resource.ResourceWithConfigValidators = (*SSHKeyResource)(nil)
+)
+
+// SSHKeyResource manages an SSH key of the account, netactuate_sshkey.
+type SSHKeyResource struct {
+	client *Client
+}
+
+// sshKeyResourceModel describes the netactuate_sshkey state
+type sshKeyResourceModel struct {
+	ID            types.String      `tfsdk:"id"`
+	Name          types.String      `tfsdk:"name"`
+	Key           sshPublicKeyValue `tfsdk:"key"`
+	PublicKeyFile types.String      `tfsdk:"public_key_file"`
+	Fingerprint   types.String      `tfsdk:"fingerprint"`
+	LastUpdated   types.String      `tfsdk:"last_updated"`
+}
+
+// NewSSHKeyResource creates a new netactuate_sshkey resource
+func NewSSHKeyResource() resource.Resource {
+	return &SSHKeyResource{}
+}
+
+// Metadata returns the resource type name
+func (r *SSHKeyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_sshkey"
+}
+
+// Schema returns the resource schema
+func (r *SSHKeyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of this resource.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"key": {
-				Type:     schema.TypeString,
+			"name": schema.StringAttribute{
 				Required: true,
-				ForceNew: true,
-				StateFunc: func(val any) string {
-					return strings.TrimSpace(val.(string))
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"key": schema.StringAttribute{
+				CustomType:  sshPublicKeyType{},
+				Optional:    true,
+				Computed:    true,
+				Validators:  []validator.String{sshPublicKeyValidator{}},
+				Description: "Public key in the authorized_keys format, e.g. `file(\"~/.ssh/id_ed25519.pub\")`. Read from `public_key_file` when that is set instead",
+				PlanModifiers: []planmodifier.String{
+					// Set from public_key_file by ModifyPlan.
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplaceIf(
+						func(_ context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+							resp.RequiresReplace = !sameSSHPublicKey(req.StateValue.ValueString(), req.PlanValue.ValueString())
+						},
+						"Changing the key, other than its surrounding whitespace, replaces the resource.",
+						"Changing the key, other than its surrounding whitespace, replaces the resource.",
+					),
 				},
 			},
-			"last_updated": {
-				Type:     schema.TypeString,
+			"public_key_file": schema.StringAttribute{
+				Optional: true,
+				Description: "Path of a file holding the public key, e.g. `~/.ssh/id_ed25519.pub`, as an alternative to `key`. " +
+					"The file is read while planning; blank lines, `#` comments and the whitespace around the key are ignored",
+			},
+			"fingerprint": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA256 fingerprint of the key as printed by `ssh-keygen -l`, e.g. `SHA256:Pa9OjCGK...`. Known during plan",
+			},
+			"last_updated": schema.StringAttribute{
 				Optional: true,
 				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
 }
 
-func resourceSshKeyCreate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	c := m.(*gona.Client)
-
-	sshKey, err := c.CreateSSHKey(ctx, d.Get("name").(string), d.Get("key").(string))
-	if err != nil {
-		return diag.FromErr(err)
+// ConfigValidators returns the validators of attribute combinations
+func (r *SSHKeyResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		exactlyOneOf("key", "public_key_file"),
 	}
+}
 
-	d.SetId(strconv.Itoa(sshKey.ID))
-
-	return nil
+// Configure stores the API client configured by the provider
+func (r *SSHKeyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = configuredClient(req.ProviderData, &resp.Diagnostics)
 }
 
-func resourceSshKeyRead(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	c := m.(*gona.Client)
+// ModifyPlan reads the key from public_key_file, computes the fingerprint
+// of the planned key, and marks the ID unknown when last_updated changes, as
+// the key is recreated under a new ID then.
+func (r *SSHKeyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
 
-	id, err := strconv.Atoi(d.Id())
-	if err != nil {
-		return diag.FromErr(err)
+	var plan sshKeyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	sshKey, err := c.GetSSHKey(ctx, id)
-	if err != nil {
-		return diag.FromErr(err)
+	if plan.PublicKeyFile.IsUnknown() {
+		plan.Key = sshPublicKeyValue{StringValue: types.StringUnknown()}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("key"), plan.Key)...)
+	} else if !plan.PublicKeyFile.IsNull() {
+		key, err := readSSHPublicKeyFile(plan.PublicKeyFile.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("public_key_file"),
+				codedSummary(CodePublicKeyFileUnreadable, "Unable to read the public key file"), err.Error())
+			return
+		}
+		plan.Key = newSSHPublicKeyValue(key)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("key"), plan.Key)...)
+
+		// The plan modifiers of key only saw the key in state.
+		var stateKey sshPublicKeyValue
+		if !req.State.Raw.IsNull() {
+			resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("key"), &stateKey)...)
+			if !sameSSHPublicKey(stateKey.ValueString(), key) {
+				resp.RequiresReplace = append(resp.RequiresReplace, path.Root("key"))
+			}
+		}
 	}
 
-	var diags diag.Diagnostics
+	if !plan.Key.IsUnknown() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("fingerprint"), sshKeyFingerprint(plan.Key.ValueString()))...)
+	}
 
-	setValue("name", sshKey.Name, d, &diags)
-	setValue("key", sshKey.Key, d, &diags)
+	if req.State.Raw.IsNull() {
+		return
+	}
 
-	return diags
+	var state sshKeyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.LastUpdated.Equal(state.LastUpdated) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), types.StringUnknown())...)
+	}
 }
 
-func resourceSshKeyDelete(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	c := m.(*gona.Client)
+func (r *SSHKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan sshKeyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	id, err := strconv.Atoi(d.Id())
+	sshKey, err := r.client.CreateSSHKey(ctx, plan.Name.ValueString(), plan.Key.ValueString())
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(frameworkDiags(apiErrorDiag(err))...)
+		return
 	}
 
-	if id == 0 {
-		return nil
+	plan.ID = types.StringValue(strconv.Itoa(sshKey.ID))
+	plan.Fingerprint = sshKeyFingerprint(plan.Key.ValueString())
+	if plan.LastUpdated.IsUnknown() {
+		plan.LastUpdated = types.StringNull()
 	}
 
-	err = c.DeleteSSHKey(ctx, id)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *SSHKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state sshKeyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id, err := parseResourceID(state.ID.ValueString())
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(frameworkDiags(errDiag(CodeInvalidResourceID, err))...)
+		return
 	}
 
-	return nil
-}
+	sshKey, err := r.client.GetSSHKey(ctx, id)
+	if removedOutOfBand(ctx, err, fmt.Sprintf("ssh key %d", id)) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.Append(frameworkDiags(apiErrorDiag(err))...)
+		return
+	}
 
-func resourceSshKeyUpdate(ctx context.Context, d *schema.ResourceData, m any) diag.Diagnostics {
-	c := m.(*gona.Client)
+	state.Name = types.StringValue(sshKey.Name)
+	state.Key = newSSHPublicKeyValue(sshKey.Key)
+	state.Fingerprint = sshKeyFingerprint(sshKey.Key)
 
-	// Delete the first Key
-	id, err := strconv.Atoi(d.Id())
-	if err != nil {
-		return diag.FromErr(err)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update recreates the key when last_updated changes. Changes of the key's
+// surrounding whitespace only are recorded without an API call.
+func (r *SSHKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state sshKeyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if id == 0 {
-		return nil
+	if plan.LastUpdated.Equal(state.LastUpdated) {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+		return
 	}
 
-	err = c.DeleteSSHKey(ctx, id)
+	id, err := parseResourceID(state.ID.ValueString())
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(frameworkDiags(errDiag(CodeInvalidResourceID, err))...)
+		return
 	}
 
-	// Sleep 3 seconds.
-	time.Sleep(3 * time.Second)
+	if id != 0 {
+		if err := r.client.DeleteSSHKey(ctx, id); err != nil {
+			resp.Diagnostics.Append(frameworkDiags(apiErrorDiag(err))...)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			resp.Diagnostics.Append(frameworkDiags(errDiag(CodeAPIRequestFailed, ctx.Err()))...)
+			return
+		case <-r.client.pollClock().After(sshKeyRecreateDelay):
+		}
+
+		sshKey, err := r.client.CreateSSHKey(ctx, plan.Name.ValueString(), plan.Key.ValueString())
+		if err != nil {
+			resp.Diagnostics.Append(frameworkDiags(apiErrorDiag(err))...)
+			return
+		}
+		plan.ID = types.StringValue(strconv.Itoa(sshKey.ID))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *SSHKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state sshKeyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	// Create the second key
-	sshKey, err := c.CreateSSHKey(ctx, d.Get("name").(string), d.Get("key").(string))
+	id, err := parseResourceID(state.ID.ValueString())
 	if err != nil {
-		return diag.FromErr(err)
+		resp.Diagnostics.Append(frameworkDiags(errDiag(CodeInvalidResourceID, err))...)
+		return
 	}
 
-	d.SetId(strconv.Itoa(sshKey.ID))
+	if id == 0 {
+		return
+	}
+
+	if err := r.client.DeleteSSHKey(ctx, id); err != nil && !IsNotFound(err) {
+		resp.Diagnostics.Append(frameworkDiags(apiErrorDiag(err))...)
+	}
+}
 
-	return nil
+func (r *SSHKeyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
@@ -0,0 +1,423 @@
+package netactuate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSSHKeyResource returns a netactuate_sshkey resource using fake and
+// its schema.
+func newTestSSHKeyResource(t *testing.T, fake *FakeClient) (*SSHKeyResource, *Client, resource.SchemaResponse) {
+	t.Helper()
+
+	c := newFakeAPIClient(fake)
+	r := &SSHKeyResource{}
+	resp := &resource.ConfigureResponse{}
+	r.Configure(context.Background(), resource.ConfigureRequest{ProviderData: c}, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(context.Background(), resource.SchemaRequest{}, &schemaResp)
+	require.False(t, schemaResp.Diagnostics.HasError(), "%v", schemaResp.Diagnostics)
+
+	return r, c, schemaResp
+}
+
+// sshKeyValue builds a netactuate_sshkey object, attributes missing from
+// values are null.
+func sshKeyValue(t *testing.T, s resource.SchemaResponse, values map[string]tftypes.Value) tftypes.Value {
+	t.Helper()
+
+	typ := s.Schema.Type().TerraformType(context.Background()).(tftypes.Object)
+	attrs := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+	for name, attrType := range typ.AttributeTypes {
+		attrs[name] = tftypes.NewValue(attrType, nil)
+		if v, ok := values[name]; ok {
+			attrs[name] = v
+		}
+	}
+	return tftypes.NewValue(typ, attrs)
+}
+
+func tfString(v string) tftypes.Value {
+	return tftypes.NewValue(tftypes.String, v)
+}
+
+func TestSSHKeyResourceCreate(t *testing.T) {
+	fake := NewFakeClient()
+	r, _, s := newTestSSHKeyResource(t, fake)
+
+	plan := sshKeyValue(t, s, map[string]tftypes.Value{
+		"id":           tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"name":         tfString("deploy"),
+		"key":          tfString("ssh-ed25519 AAAA deploy\n"),
+		"last_updated": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+	})
+	resp := &resource.CreateResponse{State: tfsdk.State{Schema: s.Schema}}
+	r.Create(context.Background(), resource.CreateRequest{Plan: tfsdk.Plan{Schema: s.Schema, Raw: plan}}, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	var state sshKeyResourceModel
+	require.False(t, resp.State.Get(context.Background(), &state).HasError())
+	assert.NotEmpty(t, state.ID.ValueString())
+	assert.Equal(t, "ssh-ed25519 AAAA deploy\n", state.Key.ValueString(), "the configured key should be kept")
+	assert.True(t, state.LastUpdated.IsNull())
+	assert.Equal(t, []string{"CreateSSHKey(deploy)"}, fake.GetCalls())
+}
+
+func TestSSHKeyResourceCreate_APIError(t *testing.T) {
+	r, _, s := newTestSSHKeyResource(t, NewFakeClient())
+
+	plan := sshKeyValue(t, s, map[string]tftypes.Value{
+		"id":   tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"name": tfString("deploy"),
+		"key":  tfString(" "),
+	})
+	resp := &resource.CreateResponse{State: tfsdk.State{Schema: s.Schema}}
+	r.Create(context.Background(), resource.CreateRequest{Plan: tfsdk.Plan{Schema: s.Schema, Raw: plan}}, resp)
+
+	require.True(t, resp.Diagnostics.HasError())
+	assert.Equal(t, codedSummary(CodeAPIValidationFailed, "NetActuate API rejected the request"), resp.Diagnostics.Errors()[0].Summary())
+}
+
+func TestSSHKeyResourceRead(t *testing.T) {
+	fake := NewFakeClient()
+	r, c, s := newTestSSHKeyResource(t, fake)
+
+	key, err := c.CreateSSHKey(context.Background(), "renamed", "ssh-ed25519 AAAA deploy")
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc        string
+		id          string
+		wantRemoved bool
+		wantErr     string
+	}{
+		{desc: "found", id: strconv.Itoa(key.ID)},
+		{desc: "not found", id: "999", wantRemoved: true},
+		{desc: "invalid id", id: "abc", wantErr: "[" + string(CodeInvalidResourceID) + "]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			state := tfsdk.State{Schema: s.Schema, Raw: sshKeyValue(t, s, map[string]tftypes.Value{
+				"id":   tfString(tt.id),
+				"name": tfString("deploy"),
+				"key":  tfString("ssh-ed25519 AAAA deploy\n"),
+			})}
+			resp := &resource.ReadResponse{State: state}
+			r.Read(context.Background(), resource.ReadRequest{State: state}, resp)
+
+			if tt.wantErr != "" {
+				require.True(t, resp.Diagnostics.HasError())
+				assert.Contains(t, resp.Diagnostics.Errors()[0].Summary(), tt.wantErr)
+				return
+			}
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+			if tt.wantRemoved {
+				assert.True(t, resp.State.Raw.IsNull(), "a deleted key should be removed from state")
+				return
+			}
+
+			var got sshKeyResourceModel
+			require.False(t, resp.State.Get(context.Background(), &got).HasError())
+			assert.Equal(t, "renamed", got.Name.ValueString())
+			assert.Equal(t, "ssh-ed25519 AAAA deploy", got.Key.ValueString())
+		})
+	}
+}
+
+func TestSSHKeyResourceUpdate(t *testing.T) {
+	tests := []struct {
+		desc        string
+		lastUpdated string
+		wantCalls   []string
+		wantNewID   bool
+	}{
+		{desc: "last_updated changed", lastUpdated: "2024-02-01", wantCalls: []string{"DeleteSSHKey(101)", "CreateSSHKey(deploy)"}, wantNewID: true},
+		{desc: "key whitespace only", lastUpdated: "2024-01-01", wantCalls: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			fake := NewFakeClient()
+			r, c, s := newTestSSHKeyResource(t, fake)
+
+			key, err := c.CreateSSHKey(context.Background(), "deploy", "ssh-ed25519 AAAA deploy")
+			require.NoError(t, err)
+			before := len(fake.GetCalls())
+			start := c.pollClock().Now()
+
+			state := tfsdk.State{Schema: s.Schema, Raw: sshKeyValue(t, s, map[string]tftypes.Value{
+				"id":           tfString(strconv.Itoa(key.ID)),
+				"name":         tfString("deploy"),
+				"key":          tfString("ssh-ed25519 AAAA deploy"),
+				"last_updated": tfString("2024-01-01"),
+			})}
+			plan := tfsdk.Plan{Schema: s.Schema, Raw: sshKeyValue(t, s, map[string]tftypes.Value{
+				"id":           tfString(strconv.Itoa(key.ID)),
+				"name":         tfString("deploy"),
+				"key":          tfString("ssh-ed25519 AAAA deploy\n"),
+				"last_updated": tfString(tt.lastUpdated),
+			})}
+			resp := &resource.UpdateResponse{State: state}
+			r.Update(context.Background(), resource.UpdateRequest{Plan: plan, State: state}, resp)
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+			var got sshKeyResourceModel
+			require.False(t, resp.State.Get(context.Background(), &got).HasError())
+			assert.Equal(t, "ssh-ed25519 AAAA deploy\n", got.Key.ValueString())
+			assert.Equal(t, tt.lastUpdated, got.LastUpdated.ValueString())
+
+			assert.Equal(t, tt.wantCalls, fake.GetCalls()[before:])
+
+			if tt.wantNewID {
+				assert.NotEqual(t, strconv.Itoa(key.ID), got.ID.ValueString())
+				assert.Equal(t, sshKeyRecreateDelay, c.pollClock().Now().Sub(start), "the key should be recreated after a delay")
+			} else {
+				assert.Equal(t, strconv.Itoa(key.ID), got.ID.ValueString())
+				assert.Equal(t, time.Duration(0), c.pollClock().Now().Sub(start))
+			}
+		})
+	}
+}
+
+func TestSSHKeyResourceModifyPlan(t *testing.T) {
+	r, _, s := newTestSSHKeyResource(t, NewFakeClient())
+
+	state := tfsdk.State{Schema: s.Schema, Raw: sshKeyValue(t, s, map[string]tftypes.Value{
+		"id":           tfString("5"),
+		"name":         tfString("deploy"),
+		"key":          tfString("ssh-ed25519 AAAA deploy"),
+		"last_updated": tfString("2024-01-01"),
+	})}
+
+	for _, lastUpdated := range []string{"2024-01-01", "2024-02-01"} {
+		t.Run(lastUpdated, func(t *testing.T) {
+			plan := tfsdk.Plan{Schema: s.Schema, Raw: sshKeyValue(t, s, map[string]tftypes.Value{
+				"id":           tfString("5"),
+				"name":         tfString("deploy"),
+				"key":          tfString("ssh-ed25519 AAAA deploy"),
+				"last_updated": tfString(lastUpdated),
+			})}
+			resp := &resource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(context.Background(), resource.ModifyPlanRequest{Plan: plan, State: state}, resp)
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+			var got sshKeyResourceModel
+			require.False(t, resp.Plan.Get(context.Background(), &got).HasError())
+			assert.Equal(t, lastUpdated != "2024-01-01", got.ID.IsUnknown(), "the ID is unknown only when the key is recreated")
+		})
+	}
+}
+
+// testSSHPublicKey is a valid public key, with the fingerprint printed by
+// ssh-keygen -l in testSSHKeyFingerprint.
+const (
+	testSSHPublicKey      = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEq3DWps448lANZjA+QiN3pD3vMpg45L846d02boEOcl deploy"
+	testSSHKeyFingerprint = "SHA256:Pa9OjCGKQ+VMrZxs8+6MKZA00Bsk84UOOFqnnLinxVc"
+)
+
+func TestSSHKeyResourceModifyPlan_Fingerprint(t *testing.T) {
+	r, _, s := newTestSSHKeyResource(t, NewFakeClient())
+
+	plan := tfsdk.Plan{Schema: s.Schema, Raw: sshKeyValue(t, s, map[string]tftypes.Value{
+		"id":           tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"name":         tfString("deploy"),
+		"key":          tfString(testSSHPublicKey + "\n"),
+		"fingerprint":  tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"last_updated": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+	})}
+	state := tfsdk.State{Schema: s.Schema, Raw: tftypes.NewValue(s.Schema.Type().TerraformType(context.Background()), nil)}
+	resp := &resource.ModifyPlanResponse{Plan: plan}
+	r.ModifyPlan(context.Background(), resource.ModifyPlanRequest{Plan: plan, State: state}, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	var got sshKeyResourceModel
+	require.False(t, resp.Plan.Get(context.Background(), &got).HasError())
+	assert.Equal(t, testSSHKeyFingerprint, got.Fingerprint.ValueString())
+}
+
+func TestSSHKeyResourceModifyPlan_PublicKeyFile(t *testing.T) {
+	r, _, s := newTestSSHKeyResource(t, NewFakeClient())
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "id_ed25519.pub")
+	require.NoError(t, os.WriteFile(file, []byte("# deploy key\n\n  "+testSSHPublicKey+"  \n\n"), 0o600))
+	other := filepath.Join(dir, "other.pub")
+	require.NoError(t, os.WriteFile(other, []byte(strings.TrimSuffix(testSSHPublicKey, "deploy")+"renamed\n"), 0o600))
+
+	state := tfsdk.State{Schema: s.Schema, Raw: sshKeyValue(t, s, map[string]tftypes.Value{
+		"id":              tfString("5"),
+		"name":            tfString("deploy"),
+		"key":             tfString(testSSHPublicKey),
+		"public_key_file": tfString(file),
+		"fingerprint":     tfString(testSSHKeyFingerprint),
+	})}
+
+	tests := []struct {
+		desc        string
+		file        string
+		wantKey     string
+		wantReplace bool
+		wantErr     string
+	}{
+		{desc: "unchanged", file: file, wantKey: testSSHPublicKey},
+		{desc: "changed", file: other, wantKey: strings.TrimSuffix(testSSHPublicKey, "deploy") + "renamed", wantReplace: true},
+		{desc: "missing", file: filepath.Join(dir, "missing.pub"), wantErr: "[NA1025] Unable to read the public key file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			// The plan modifiers of key keep the key in state.
+			plan := tfsdk.Plan{Schema: s.Schema, Raw: sshKeyValue(t, s, map[string]tftypes.Value{
+				"id":              tfString("5"),
+				"name":            tfString("deploy"),
+				"key":             tfString(testSSHPublicKey),
+				"public_key_file": tfString(tt.file),
+				"fingerprint":     tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+			})}
+			resp := &resource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(context.Background(), resource.ModifyPlanRequest{Plan: plan, State: state}, resp)
+
+			if tt.wantErr != "" {
+				require.True(t, resp.Diagnostics.HasError())
+				assert.Equal(t, tt.wantErr, resp.Diagnostics.Errors()[0].Summary())
+				return
+			}
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+			var got sshKeyResourceModel
+			require.False(t, resp.Plan.Get(context.Background(), &got).HasError())
+			assert.Equal(t, tt.wantKey, got.Key.ValueString())
+			assert.Equal(t, sshKeyFingerprint(tt.wantKey), got.Fingerprint)
+			assert.Equal(t, tt.wantReplace, resp.RequiresReplace.Contains(path.Root("key")))
+		})
+	}
+}
+
+func TestReadSSHPublicKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"empty.pub":   "# no key\n",
+		"two.pub":     testSSHPublicKey + "\n" + testSSHPublicKey + "\n",
+		"invalid.pub": "ssh-ed25519 AAAA deploy\n",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	_, err := readSSHPublicKeyFile(filepath.Join(dir, "empty.pub"))
+	assert.ErrorContains(t, err, "expected a single public key")
+	_, err = readSSHPublicKeyFile(filepath.Join(dir, "two.pub"))
+	assert.ErrorContains(t, err, "found 2")
+	_, err = readSSHPublicKeyFile(filepath.Join(dir, "invalid.pub"))
+	assert.Error(t, err)
+}
+
+func TestSSHPublicKeyValidator(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{key: testSSHPublicKey},
+		{key: "  " + testSSHPublicKey + "\n"},
+		{key: "ssh-ed25519 AAAA deploy", wantErr: true},
+		{key: "not a key", wantErr: true},
+		{key: testSSHPublicKey + "\n" + testSSHPublicKey, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		resp := &validator.StringResponse{}
+		sshPublicKeyValidator{}.ValidateString(context.Background(), validator.StringRequest{
+			Path:        path.Root("key"),
+			ConfigValue: types.StringValue(tt.key),
+		}, resp)
+		assert.Equal(t, tt.wantErr, resp.Diagnostics.HasError(), "%q", tt.key)
+		if tt.wantErr {
+			assert.Contains(t, resp.Diagnostics[0].Summary(), "[NA1018]")
+		}
+	}
+}
+
+func TestSSHKeyFingerprint(t *testing.T) {
+	assert.Equal(t, testSSHKeyFingerprint, sshKeyFingerprint(testSSHPublicKey).ValueString())
+	assert.True(t, sshKeyFingerprint("ssh-ed25519 AAAA deploy").IsNull())
+}
+
+func TestSSHKeyResourceDelete(t *testing.T) {
+	tests := []struct {
+		desc      string
+		id        func(c *Client) string
+		wantCalls bool
+	}{
+		{desc: "existing", id: func(c *Client) string {
+			key, err := c.CreateSSHKey(context.Background(), "deploy", "ssh-ed25519 AAAA deploy")
+			require.NoError(t, err)
+			return strconv.Itoa(key.ID)
+		}, wantCalls: true},
+		{desc: "already gone", id: func(*Client) string { return "999" }, wantCalls: true},
+		{desc: "zero id", id: func(*Client) string { return "0" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			fake := NewFakeClient()
+			r, c, s := newTestSSHKeyResource(t, fake)
+			id := tt.id(c)
+			before := len(fake.GetCalls())
+
+			state := tfsdk.State{Schema: s.Schema, Raw: sshKeyValue(t, s, map[string]tftypes.Value{
+				"id":   tfString(id),
+				"name": tfString("deploy"),
+				"key":  tfString("ssh-ed25519 AAAA deploy"),
+			})}
+			resp := &resource.DeleteResponse{State: state}
+			r.Delete(context.Background(), resource.DeleteRequest{State: state}, resp)
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+			assert.Equal(t, tt.wantCalls, len(fake.GetCalls()) > before)
+		})
+	}
+}
+
+func TestSSHKeyResourceImportState(t *testing.T) {
+	r, _, s := newTestSSHKeyResource(t, NewFakeClient())
+
+	resp := &resource.ImportStateResponse{State: tfsdk.State{Schema: s.Schema, Raw: sshKeyValue(t, s, nil)}}
+	r.ImportState(context.Background(), resource.ImportStateRequest{ID: "42"}, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	var id string
+	require.False(t, resp.State.GetAttribute(context.Background(), path.Root("id"), &id).HasError())
+	assert.Equal(t, "42", id)
+}
+
+func TestSSHPublicKeySemanticEquals(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "ssh-ed25519 AAAA", b: "ssh-ed25519 AAAA\n", want: true},
+		{a: "  ssh-ed25519 AAAA", b: "ssh-ed25519 AAAA", want: true},
+		{a: "ssh-ed25519 AAAA", b: "ssh-ed25519 BBBB", want: false},
+	}
+
+	for _, tt := range tests {
+		got, diags := newSSHPublicKeyValue(tt.a).StringSemanticEquals(context.Background(), newSSHPublicKeyValue(tt.b))
+		require.False(t, diags.HasError())
+		assert.Equal(t, tt.want, got, "%q and %q", tt.a, tt.b)
+	}
+}
@@ -0,0 +1,56 @@
+package netactuate
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// billingValidator checks that the billing keys fit package_billing:
+// usage billing needs a contract and package billing the opt-in. A server
+// configured with neither is left to exactlyOneOf(billingKeys...).
+type billingValidator struct{}
+
+var _ resource.ConfigValidator = billingValidator{}
+
+func (v billingValidator) Description(_ context.Context) string {
+	return "`package_billing` \"usage\" requires `package_billing_contract_id`, \"package\" requires `package_billing_opt_in` \"yes\""
+}
+
+func (v billingValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v billingValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var billing, optIn, contractID types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("package_billing"), &billing)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("package_billing_opt_in"), &optIn)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("package_billing_contract_id"), &contractID)...)
+	if resp.Diagnostics.HasError() || billing.IsUnknown() || optIn.IsUnknown() || contractID.IsUnknown() {
+		return
+	}
+	if optIn.IsNull() && contractID.IsNull() {
+		return
+	}
+
+	switch billing.ValueString() {
+	case "", "usage":
+		if contractID.ValueString() == "" {
+			resp.Diagnostics.AddAttributeError(path.Root("package_billing_contract_id"),
+				codedSummary(CodeBillingContractRequired, "package_billing_contract_id is required for usage billing"),
+				`Set package_billing_contract_id to your contract ID with NetActuate, or set package_billing = "package" `+
+					`and package_billing_opt_in = "yes" to bill the server as a package.`,
+			)
+		}
+	case "package":
+		if optIn.ValueString() != "yes" {
+			resp.Diagnostics.AddAttributeError(path.Root("package_billing_opt_in"),
+				codedSummary(CodeBillingOptInRequired, `package_billing_opt_in must be "yes" for package billing`),
+				`Package billing has to be opted into with package_billing_opt_in = "yes". `+
+					`To bill the server by usage instead, set package_billing = "usage" and package_billing_contract_id.`,
+			)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBillingValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(m *serverBaseModel)
+		wantErr string
+	}{
+		{
+			name:   "usage with contract",
+			modify: func(m *serverBaseModel) {},
+		},
+		{
+			name:   "default billing with contract",
+			modify: func(m *serverBaseModel) { m.PackageBilling = types.StringNull() },
+		},
+		{
+			name: "usage with opt-in",
+			modify: func(m *serverBaseModel) {
+				m.PackageBillingContractID = types.StringNull()
+				m.PackageBillingOptIn = types.StringValue("yes")
+			},
+			wantErr: "[NA1005] package_billing_contract_id is required for usage billing",
+		},
+		{
+			name: "package with opt-in",
+			modify: func(m *serverBaseModel) {
+				m.PackageBilling = types.StringValue("package")
+				m.PackageBillingContractID = types.StringNull()
+				m.PackageBillingOptIn = types.StringValue("yes")
+			},
+		},
+		{
+			name:    "package with contract",
+			modify:  func(m *serverBaseModel) { m.PackageBilling = types.StringValue("package") },
+			wantErr: `[NA1004] package_billing_opt_in must be "yes" for package billing`,
+		},
+		{
+			name: "package with opt-out",
+			modify: func(m *serverBaseModel) {
+				m.PackageBilling = types.StringValue("package")
+				m.PackageBillingContractID = types.StringNull()
+				m.PackageBillingOptIn = types.StringValue("no")
+			},
+			wantErr: `[NA1004] package_billing_opt_in must be "yes" for package billing`,
+		},
+		{
+			name:   "unknown contract",
+			modify: func(m *serverBaseModel) { m.PackageBillingContractID = types.StringUnknown() },
+		},
+		{
+			name: "no billing keys",
+			modify: func(m *serverBaseModel) {
+				m.PackageBillingContractID = types.StringNull()
+			},
+		},
+	}
+
+	_, s := newTestServerResource(t, NewFakeClient())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testServerModel("C-1001")
+			tt.modify(&m)
+			config := tfsdk.Config{Schema: s.Schema, Raw: testPlan(t, s, &serverResourceModel{serverBaseModel: m}).Raw}
+
+			resp := &resource.ValidateConfigResponse{}
+			billingValidator{}.ValidateResource(context.Background(), resource.ValidateConfigRequest{Config: config}, resp)
+
+			if tt.wantErr == "" {
+				assert.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+				return
+			}
+			if assert.Len(t, resp.Diagnostics, 1) {
+				assert.Equal(t, tt.wantErr, resp.Diagnostics[0].Summary())
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// Expiry actions, what happens once a server is past its expires_at.
+const (
+	expiryActionWarn    = "warn"
+	expiryActionReplace = "replace"
+)
+
+var expiryActions = []string{expiryActionWarn, expiryActionReplace}
+
+// expiryAttributes returns the schema attributes of server expiry, for
+// short-lived servers that should not be forgotten on a paid contract.
+func expiryAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"ttl_hours": schema.Int64Attribute{
+			Optional:    true,
+			Validators:  []validator.Int64{int64AtLeastValidator{min: 1}},
+			Description: "Hours the server is meant to live. Sets `expires_at` when the server is created or `ttl_hours` changes",
+		},
+		"expires_at": schema.StringAttribute{
+			Optional:    true,
+			Computed:    true,
+			Validators:  []validator.String{timestampValidator{}},
+			Description: "RFC 3339 timestamp after which the server is expired, set explicitly or computed from `ttl_hours`. Refreshing an expired server reports a warning",
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		},
+		"expiry_action": schema.StringAttribute{
+			Optional:   true,
+			Computed:   true,
+			Default:    stringdefault.StaticString(expiryActionWarn),
+			Validators: []validator.String{oneOfValidator{code: CodeInvalidExpiryAction, values: expiryActions}},
+			Description: "What to do with an expired server: `warn` only reports it, `replace` plans to destroy it and build a new one " +
+				"with a fresh `expires_at`, which requires `ttl_hours`. Terraform can't plan to only destroy a resource, remove it " +
+				"from the configuration for that",
+		},
+	}
+}
+
+// expiryConfigValidators returns the validators of the expiry attributes.
+func expiryConfigValidators() []resource.ConfigValidator {
+	return []resource.ConfigValidator{atMostOneOf("ttl_hours", "expires_at")}
+}
+
+// setExpiry sets expires_at of a created or updated server from ttl_hours,
+// unless it was planned.
+func setExpiry(m *serverBaseModel, now time.Time) {
+	if !m.ExpiresAt.IsUnknown() {
+		return
+	}
+	if m.TTLHours.IsNull() || m.TTLHours.IsUnknown() {
+		m.ExpiresAt = types.StringNull()
+		return
+	}
+
+	ttl := time.Duration(m.TTLHours.ValueInt64()) * time.Hour
+	m.ExpiresAt = types.StringValue(now.Add(ttl).UTC().Format(time.RFC3339))
+}
+
+// expired reports whether the server is past its expires_at.
+func (m *serverBaseModel) expired(now time.Time) bool {
+	if m.ExpiresAt.IsNull() || m.ExpiresAt.IsUnknown() {
+		return false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, m.ExpiresAt.ValueString())
+	return err == nil && !now.Before(expiresAt)
+}
+
+// expiryDiags warns about an expired server.
+func expiryDiags(m *serverBaseModel, now time.Time) diag.Diagnostics {
+	if !m.expired(now) {
+		return nil
+	}
+
+	return diag.Diagnostics{codedDiag(diag.Warning, CodeServerExpired,
+		fmt.Sprintf("Server %s expired at %s", m.Hostname.ValueString(), m.ExpiresAt.ValueString()),
+		"The server is still running and billed. Remove it from the configuration, extend expires_at or ttl_hours, "+
+			"or set expiry_action to \"replace\" to rebuild it as a new server.",
+	)}
+}
+
+// planServerExpiry plans expires_at: it is recomputed when ttl_hours
+// changes, removed along with ttl_hours and, with expiry_action "replace",
+// an expired server is replaced.
+func planServerExpiry(ctx context.Context, c *Client, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, plan, state *serverBaseModel) {
+	var configured types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("expires_at"), &configured)...)
+	if resp.Diagnostics.HasError() || !configured.IsNull() {
+		return
+	}
+
+	expiresAt := path.Root("expires_at")
+	switch {
+	case plan.TTLHours.IsNull():
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, expiresAt, types.StringNull())...)
+	case !plan.TTLHours.Equal(state.TTLHours) || state.ExpiresAt.IsNull():
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, expiresAt, types.StringUnknown())...)
+	case c != nil && plan.ExpiryAction.ValueString() == expiryActionReplace && state.expired(c.pollClock().Now()):
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, expiresAt, types.StringUnknown())...)
+		resp.RequiresReplace = append(resp.RequiresReplace, expiresAt)
+	}
+}
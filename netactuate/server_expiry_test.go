@@ -0,0 +1,110 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	m := serverBaseModel{TTLHours: types.Int64Value(36), ExpiresAt: types.StringUnknown()}
+	setExpiry(&m, now)
+	assert.Equal(t, "2024-01-03T00:00:00Z", m.ExpiresAt.ValueString())
+
+	m = serverBaseModel{TTLHours: types.Int64Null(), ExpiresAt: types.StringUnknown()}
+	setExpiry(&m, now)
+	assert.True(t, m.ExpiresAt.IsNull())
+
+	m = serverBaseModel{TTLHours: types.Int64Value(1), ExpiresAt: types.StringValue("2024-06-01T00:00:00Z")}
+	setExpiry(&m, now)
+	assert.Equal(t, "2024-06-01T00:00:00Z", m.ExpiresAt.ValueString(), "a planned expiry is kept")
+}
+
+func TestExpiryDiags(t *testing.T) {
+	m := serverBaseModel{
+		Hostname:  types.StringValue("web01.example.com"),
+		ExpiresAt: types.StringValue("2024-01-01T00:00:00Z"),
+	}
+
+	assert.Empty(t, expiryDiags(&m, time.Date(2023, 12, 31, 23, 59, 0, 0, time.UTC)))
+
+	diags := expiryDiags(&m, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.Len(t, diags, 1)
+	assert.False(t, diags.HasError(), "an expired server is only reported")
+	assert.Equal(t, "[NA3011] Server web01.example.com expired at 2024-01-01T00:00:00Z", diags[0].Summary)
+}
+
+func TestResourceServerCreate_TTL(t *testing.T) {
+	fake := NewFakeClient()
+	fake.AddContract("C-1001")
+	c := newFakeAPIClient(fake)
+
+	m := testServerModel("C-1001")
+	m.TTLHours = types.Int64Value(24)
+	m.ExpiresAt = types.StringUnknown()
+	require.Empty(t, createServer(context.Background(), c, &m, false))
+
+	assert.Equal(t, "2024-01-02T00:00:00Z", m.ExpiresAt.ValueString())
+}
+
+func TestServerResourceModifyPlan_Expiry(t *testing.T) {
+	tests := []struct {
+		desc        string
+		action      string
+		ttl         int64
+		wantReplace bool
+		wantUnknown bool
+	}{
+		{desc: "warn", action: expiryActionWarn, ttl: 24},
+		{desc: "replace", action: expiryActionReplace, ttl: 24, wantReplace: true, wantUnknown: true},
+		{desc: "ttl changed", action: expiryActionWarn, ttl: 48, wantUnknown: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r, s := newTestServerResource(t, NewFakeClient())
+
+			// The fake clock starts at 2024-01-01.
+			state := serverResourceModel{serverBaseModel: testServerModel("C-1001"), WaitForRunning: types.BoolValue(true)}
+			state.ID = types.StringValue("101")
+			state.PowerState = types.StringValue(powerStateOn)
+			state.InstallComplete = types.BoolValue(true)
+			state.PrimaryIPv4 = types.StringValue("192.0.2.10")
+			state.PrimaryIPv6 = types.StringValue("2001:db8::10")
+			state.BuildID = types.Int64Value(1)
+			state.LastBuild = types.StringValue("ok")
+			state.TTLHours = types.Int64Value(24)
+			state.ExpiresAt = types.StringValue("2023-12-31T00:00:00Z")
+			state.ExpiryAction = types.StringValue(expiryActionWarn)
+
+			planned := state
+			planned.TTLHours = types.Int64Value(tt.ttl)
+			planned.ExpiryAction = types.StringValue(tt.action)
+			config := planned
+			config.ID, config.ExpiresAt = types.StringNull(), types.StringNull()
+
+			req := resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: s.Schema, Raw: testPlan(t, s, &config).Raw},
+				State:  tfsdk.State{Schema: s.Schema, Raw: testPlan(t, s, &state).Raw},
+				Plan:   testPlan(t, s, &planned),
+			}
+			resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+			r.ModifyPlan(context.Background(), req, resp)
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+			var plan serverResourceModel
+			require.False(t, resp.Plan.Get(context.Background(), &plan).HasError())
+			assert.Equal(t, tt.wantUnknown, plan.ExpiresAt.IsUnknown())
+			assert.Equal(t, tt.wantReplace, resp.RequiresReplace.Contains(path.Root("expires_at")))
+		})
+	}
+}
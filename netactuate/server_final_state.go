@@ -0,0 +1,85 @@
+package netactuate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// serverFinalState is the record appended to final_state_file before a
+// server is destroyed.
+type serverFinalState struct {
+	ID          int       `json:"id"`
+	Hostname    string    `json:"hostname"`
+	Package     string    `json:"package"`
+	PlanID      int       `json:"plan_id"`
+	Location    string    `json:"location"`
+	LocationID  int       `json:"location_id"`
+	Image       string    `json:"image"`
+	ImageID     int       `json:"image_id"`
+	PrimaryIPv4 string    `json:"primary_ipv4"`
+	PrimaryIPv6 string    `json:"primary_ipv6"`
+	IPv4        []string  `json:"ipv4"`
+	IPv6        []string  `json:"ipv6"`
+	DestroyedAt time.Time `json:"destroyed_at"`
+}
+
+// recordFinalState appends a JSON line describing the server to path. A
+// server that no longer exists has nothing left to record.
+func recordFinalState(ctx context.Context, c *Client, id int, path string) error {
+	server, err := c.GetServer(ctx, id)
+	if IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ips, err := c.GetIPs(ctx, id)
+	if err != nil && !IsNotFound(err) {
+		return err
+	}
+
+	record := serverFinalState{
+		ID:          server.ID,
+		Hostname:    server.Name,
+		Package:     server.Package,
+		PlanID:      server.PlanID,
+		Location:    server.Location,
+		LocationID:  server.LocationID,
+		Image:       server.OS,
+		ImageID:     server.OSID,
+		PrimaryIPv4: server.PrimaryIPv4,
+		PrimaryIPv6: server.PrimaryIPv6,
+		IPv4:        make([]string, 0, len(ips.IPv4)),
+		IPv6:        make([]string, 0, len(ips.IPv6)),
+		DestroyedAt: c.pollClock().Now().UTC(),
+	}
+	for _, ip := range ips.IPv4 {
+		record.IPv4 = append(record.IPv4, ip.IP)
+	}
+	for _, ip := range ips.IPv6 {
+		record.IPv6 = append(record.IPv6, ip.IP)
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package netactuate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordFinalState(t *testing.T) {
+	api := newTestAPI(t, map[string]string{
+		"cloud/server": `{"mbpkgid": 42, "fqdn": "web01.example.com", "os": "Ubuntu 22.04 LTS x64", "os_id": 1000,
+			"primary_ipv4": "192.0.2.10", "plan_id": 5, "location_id": 11}`,
+		"cloud/networkips/42": `{"IPv4": [{"id": 1, "ip": "192.0.2.10"}], "IPv6": [{"id": 2, "ip": "2001:db8::10"}]}`,
+	})
+	c, err := NewClient("test-api-key", api.URL+"/api/", DefaultAPIVersion)
+	require.NoError(t, err)
+	clk := newFakeClock()
+	c.clock = clk
+
+	path := filepath.Join(t.TempDir(), "destroyed.jsonl")
+	require.NoError(t, recordFinalState(context.Background(), c, 42, path))
+	require.NoError(t, recordFinalState(context.Background(), c, 42, path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2, "records should be appended")
+
+	var record serverFinalState
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, 42, record.ID)
+	assert.Equal(t, "web01.example.com", record.Hostname)
+	assert.Equal(t, "Ubuntu 22.04 LTS x64", record.Image)
+	assert.Equal(t, 5, record.PlanID)
+	assert.Equal(t, []string{"192.0.2.10"}, record.IPv4)
+	assert.Equal(t, []string{"2001:db8::10"}, record.IPv6)
+	assert.Equal(t, clk.Now(), record.DestroyedAt)
+}
+
+func TestRecordFinalState_ServerGone(t *testing.T) {
+	api := newTestAPI(t, nil)
+	c, err := NewClient("test-api-key", api.URL+"/api/", DefaultAPIVersion)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "destroyed.jsonl")
+	require.NoError(t, recordFinalState(context.Background(), c, 42, path))
+	assert.NoFileExists(t, path)
+}
@@ -0,0 +1,154 @@
+package netactuate
+
+import (
+	"cmp"
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/netactuate/gona/gona"
+)
+
+var (
+	slugSeparator = regexp.MustCompile(`[^a-z0-9.]+`)
+	versionRegex  = regexp.MustCompile(`\d+(\.\d+)*`)
+)
+
+// imageAttributes returns the schema attributes selecting the image of a
+// server.
+func imageAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"image": schema.StringAttribute{
+			Optional: true,
+			Description: "Name of the image as listed by the netactuate_oses data source, e.g. `Ubuntu 22.04 LTS x64`, " +
+				"or the start of it in lowercase with dashes, e.g. `ubuntu-22.04`. Several matching images resolve to the newest " +
+				"version. The name is resolved to `image_id` when planning, the server is only rebuilt when that changes",
+		},
+		"image_family": schema.StringAttribute{
+			Optional: true,
+			Description: "Family of images to build the newest one of: the image subtype, optionally followed by words of " +
+				"the image name, e.g. `debian` or `ubuntu-lts`. Resolved to `image_id` when planning, so the server is " +
+				"rebuilt once a newer image of the family is released",
+		},
+		"image_id": schema.Int64Attribute{
+			Optional:    true,
+			Computed:    true,
+			Description: "ID of the image. Computed from `image` or `image_family` when either is set",
+			PlanModifiers: []planmodifier.Int64{
+				int64planmodifier.UseStateForUnknown(),
+			},
+		},
+	}
+}
+
+// planServerImage resolves a configured image name or family to the
+// planned image_id, on every plan so that a newer image of the family is
+// picked up. It is unknown until the provider is configured and the name
+// is known.
+func planServerImage(ctx context.Context, c *Client, resp *resource.ModifyPlanResponse, plan *serverBaseModel) {
+	if !isSet(plan.Image) && !isSet(plan.ImageFamily) {
+		return
+	}
+
+	plan.ImageID = types.Int64Unknown()
+	if c != nil && !plan.Image.IsUnknown() && !plan.ImageFamily.IsUnknown() {
+		os, diags := resolveImage(ctx, c, plan.Image.ValueString(), plan.ImageFamily.ValueString())
+		if diags.HasError() {
+			attribute := path.Root("image")
+			if isSet(plan.ImageFamily) {
+				attribute = path.Root("image_family")
+			}
+			resp.Diagnostics.AddAttributeError(attribute, diags[0].Summary, diags[0].Detail)
+			return
+		}
+		plan.ImageID = types.Int64Value(int64(os.ID))
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("image_id"), plan.ImageID)...)
+}
+
+// resolveImage returns the newest image matching the name or, when name is
+// empty, the family.
+func resolveImage(ctx context.Context, c *Client, name, family string) (*gona.OS, diag.Diagnostics) {
+	oss, err := c.GetOSs(ctx)
+	if err != nil {
+		return nil, apiErrorDiag(err)
+	}
+
+	// An exact name wins over newer images it is the start of.
+	if i := slices.IndexFunc(oss, func(os gona.OS) bool { return name != "" && os.Os == name }); i >= 0 {
+		return &oss[i], nil
+	}
+
+	oss = slices.DeleteFunc(oss, func(os gona.OS) bool {
+		if name != "" {
+			return !imageMatches(name, os.Os)
+		}
+		return !inImageFamily(family, os)
+	})
+	if len(oss) == 0 {
+		if name != "" {
+			return nil, errorDiag(CodeImageNotFound, "Provided image %q doesn't exist", name)
+		}
+		return nil, errorDiag(CodeImageNotFound, "No image of the family %q exists", family)
+	}
+
+	newest := slices.MaxFunc(oss, compareImages)
+	return &newest, nil
+}
+
+// imageMatches reports whether name is the name of the image or the start
+// of its slug, e.g. "ubuntu-22.04" of "Ubuntu 22.04 LTS x64".
+func imageMatches(name, image string) bool {
+	if name == image {
+		return true
+	}
+	slug, prefix := imageSlug(image), imageSlug(name)
+	return prefix != "" && (slug == prefix || strings.HasPrefix(slug, prefix+"-"))
+}
+
+// inImageFamily reports whether the image is of the family, its subtype
+// followed by words of its name, e.g. "ubuntu-lts".
+func inImageFamily(family string, os gona.OS) bool {
+	words := strings.Split(imageSlug(family), "-")
+	if words[0] == "" || !strings.EqualFold(words[0], os.Subtype) {
+		return false
+	}
+
+	name := strings.Split(imageSlug(os.Os), "-")
+	for _, word := range words[1:] {
+		if !slices.Contains(name, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// imageSlug returns the name in lowercase with dashes between its words.
+func imageSlug(name string) string {
+	return strings.Trim(slugSeparator.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// compareImages orders images by the first version in their name, e.g.
+// 22.04 before 24.04, and then by ID.
+func compareImages(a, b gona.OS) int {
+	return cmp.Or(slices.Compare(imageVersion(a.Os), imageVersion(b.Os)), cmp.Compare(a.ID, b.ID))
+}
+
+func imageVersion(name string) []int {
+	var version []int
+	for _, part := range strings.Split(versionRegex.FindString(name), ".") {
+		if n, err := strconv.Atoi(part); err == nil {
+			version = append(version, n)
+		}
+	}
+	return version
+}
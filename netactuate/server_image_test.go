@@ -0,0 +1,93 @@
+package netactuate
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		image   string
+		family  string
+		want    int
+		wantErr string
+	}{
+		{name: "exact name", image: "Ubuntu 22.04 LTS x64", want: 1000},
+		{name: "slug", image: "ubuntu-22.04", want: 1000},
+		{name: "newest of several", image: "ubuntu", want: 1200},
+		{name: "partial word", image: "ubu", wantErr: `[NA1008] Provided image "ubu" doesn't exist`},
+		{name: "family", family: "ubuntu-lts", want: 1200},
+		{name: "family subtype", family: "debian", want: 800},
+		{name: "unknown family", family: "ubuntu-minimal", wantErr: `[NA1008] No image of the family "ubuntu-minimal" exists`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os, diags := resolveImage(context.Background(), newFakeAPIClient(NewFakeClient()), tt.image, tt.family)
+			if tt.wantErr != "" {
+				require.True(t, diags.HasError())
+				assert.Equal(t, tt.wantErr, diags[0].Summary)
+				return
+			}
+			require.False(t, diags.HasError(), "%v", diags)
+			assert.Equal(t, tt.want, os.ID)
+		})
+	}
+}
+
+func TestCompareImages(t *testing.T) {
+	older := gona.OS{ID: 900, Os: "Ubuntu 9.10 x64"}
+	newer := gona.OS{ID: 100, Os: "Ubuntu 10.04 x64"}
+	assert.Negative(t, compareImages(older, newer), "versions are compared as numbers")
+	assert.Positive(t, compareImages(gona.OS{ID: 2, Os: "Debian"}, gona.OS{ID: 1, Os: "Debian"}))
+}
+
+func TestServerResourceModifyPlan_ImageFamily(t *testing.T) {
+	r, s := newTestServerResource(t, NewFakeClient())
+
+	model := serverResourceModel{serverBaseModel: testServerModel("C-1001"), WaitForRunning: types.BoolValue(true)}
+	model.ImageFamily = types.StringValue("ubuntu-lts")
+	model.ImageID = types.Int64Unknown()
+
+	plan := testPlan(t, s, &model)
+	req := resource.ModifyPlanRequest{
+		Plan:  plan,
+		State: tfsdk.State{Schema: s.Schema, Raw: tftypes.NewValue(s.Schema.Type().TerraformType(context.Background()), nil)},
+	}
+	resp := &resource.ModifyPlanResponse{Plan: plan}
+	r.ModifyPlan(context.Background(), req, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+	var got serverResourceModel
+	require.False(t, resp.Plan.Get(context.Background(), &got).HasError())
+	assert.Equal(t, int64(1200), got.ImageID.ValueInt64())
+	assert.Equal(t, "linux", got.OSType.ValueString())
+}
+
+func TestReadServer_ImageName(t *testing.T) {
+	fake := NewFakeClient()
+	id := fake.AddServer(gona.Server{Name: "web01.example.com", ServerStatus: "RUNNING", OS: "Ubuntu 22.04 LTS x64", OSID: 1000, Installed: 1}).LastServerID()
+	c := newFakeAPIClient(fake)
+
+	m := testServerModel("C-1001")
+	m.ID = types.StringValue(strconv.Itoa(id))
+	m.Image = types.StringValue("ubuntu-22.04")
+	m.ImageID = types.Int64Null()
+
+	require.False(t, readServer(context.Background(), c, &m).HasError())
+	assert.Equal(t, "ubuntu-22.04", m.Image.ValueString(), "a name of the image is kept")
+	assert.Equal(t, int64(1000), m.ImageID.ValueInt64())
+
+	m.Image = types.StringValue("debian")
+	require.False(t, readServer(context.Background(), c, &m).HasError())
+	assert.Equal(t, "Ubuntu 22.04 LTS x64", m.Image.ValueString())
+}
@@ -0,0 +1,163 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/netactuate/gona/gona"
+)
+
+// ipAttributes returns the schema attributes listing every address of a
+// server, beyond its primary ones.
+func ipAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"refresh_ips": refreshFlagAttribute("Read `ipv4_addresses` and `ipv6_addresses` with an extra API call"),
+		"ipv4_addresses": schema.ListAttribute{
+			ElementType: types.StringType,
+			Computed:    true,
+			Description: "All IPv4 addresses of the server, ordered by assignment. Addresses added or removed outside of Terraform are reported as a warning on refresh",
+			PlanModifiers: []planmodifier.List{
+				listplanmodifier.UseStateForUnknown(),
+			},
+		},
+		"ipv6_addresses": schema.ListAttribute{
+			ElementType: types.StringType,
+			Computed:    true,
+			Description: "All IPv6 addresses of the server, ordered by assignment. Addresses added or removed outside of Terraform are reported as a warning on refresh",
+			PlanModifiers: []planmodifier.List{
+				listplanmodifier.UseStateForUnknown(),
+			},
+		},
+	}
+}
+
+// readServerIPs refreshes the addresses of the server and warns about
+// addresses added or removed since the last refresh. An empty list in state
+// isn't compared, the addresses of a new server may be assigned after its
+// creation.
+func readServerIPs(ctx context.Context, c *Client, m *serverBaseModel, id int) diag.Diagnostics {
+	if !refreshFlagEnabled(m.RefreshIPs) {
+		return nil
+	}
+
+	ips, err := c.GetIPs(ctx, id)
+	if err != nil {
+		return apiErrorDiag(err)
+	}
+	ipv4, ipv6 := serverAddresses(ips)
+
+	var diags diag.Diagnostics
+	for _, family := range []struct {
+		name  string
+		state *types.List
+		addrs []string
+	}{
+		{name: "IPv4", state: &m.IPv4Addresses, addrs: ipv4},
+		{name: "IPv6", state: &m.IPv6Addresses, addrs: ipv6},
+	} {
+		if prev := stringList(*family.state); len(prev) > 0 {
+			if added, removed := diffAddresses(prev, family.addrs); len(added) > 0 || len(removed) > 0 {
+				diags = append(diags, ipDriftDiag(m, family.name, added, removed))
+			}
+		}
+		*family.state = stringListValue(family.addrs)
+	}
+
+	return diags
+}
+
+// setServerIPs fills in the addresses of a created or updated server if
+// they are unknown.
+func setServerIPs(ctx context.Context, c *Client, m *serverBaseModel, id int) diag.Diagnostics {
+	if !m.IPv4Addresses.IsUnknown() && !m.IPv6Addresses.IsUnknown() {
+		return nil
+	}
+
+	ips, err := c.GetIPs(ctx, id)
+	if err != nil {
+		return apiErrorDiag(err)
+	}
+	ipv4, ipv6 := serverAddresses(ips)
+	if m.IPv4Addresses.IsUnknown() {
+		m.IPv4Addresses = stringListValue(ipv4)
+	}
+	if m.IPv6Addresses.IsUnknown() {
+		m.IPv6Addresses = stringListValue(ipv6)
+	}
+	return nil
+}
+
+// serverAddresses returns the addresses of each family, ordered by ID like
+// the public addresses of FlattenIPs.
+func serverAddresses(ips gona.IPs) (ipv4 []string, ipv6 []string) {
+	addresses := func(ips []gona.IP) []string {
+		result := make([]string, 0, len(ips))
+		for _, ip := range slices.SortedFunc(slices.Values(ips), compareIPs) {
+			result = append(result, ip.IP)
+		}
+		return result
+	}
+	return addresses(ips.IPv4), addresses(ips.IPv6)
+}
+
+// diffAddresses returns the addresses only in current and only in prev.
+func diffAddresses(prev, current []string) (added []string, removed []string) {
+	for _, addr := range current {
+		if !slices.Contains(prev, addr) {
+			added = append(added, addr)
+		}
+	}
+	for _, addr := range prev {
+		if !slices.Contains(current, addr) {
+			removed = append(removed, addr)
+		}
+	}
+	return added, removed
+}
+
+func ipDriftDiag(m *serverBaseModel, family string, added, removed []string) diag.Diagnostic {
+	var changes []string
+	if len(added) > 0 {
+		changes = append(changes, "added "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		changes = append(changes, "removed "+strings.Join(removed, ", "))
+	}
+
+	return codedDiag(diag.Warning, CodeServerIPsChanged,
+		fmt.Sprintf("%s addresses of server %s changed outside of Terraform", family, m.Hostname.ValueString()),
+		fmt.Sprintf("The %s addresses of server %s were changed outside of Terraform: %s.",
+			family, m.ID.ValueString(), strings.Join(changes, "; ")),
+	)
+}
+
+// stringList returns the elements of a known list of strings.
+func stringList(l types.List) []string {
+	if l.IsNull() || l.IsUnknown() {
+		return nil
+	}
+	result := make([]string, 0, len(l.Elements()))
+	for _, e := range l.Elements() {
+		if s, ok := e.(types.String); ok {
+			result = append(result, s.ValueString())
+		}
+	}
+	return result
+}
+
+// stringListValue converts strings into a list value.
+func stringListValue(values []string) types.List {
+	elements := make([]attr.Value, len(values))
+	for i, v := range values {
+		elements[i] = types.StringValue(v)
+	}
+	return types.ListValueMust(types.StringType, elements)
+}
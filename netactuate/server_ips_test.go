@@ -0,0 +1,67 @@
+package netactuate
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadServerIPs(t *testing.T) {
+	fake := NewFakeClient()
+	id := fake.AddServer(gona.Server{Name: "web01.example.com", PrimaryIPv4: "192.0.2.10", PrimaryIPv6: "2001:db8::10"}).LastServerID()
+	c := newFakeAPIClient(fake)
+
+	m := testServerModel("C-1001")
+	m.ID = types.StringValue(strconv.Itoa(id))
+	m.Hostname = types.StringValue("web01.example.com")
+
+	// The first refresh after a create only records the addresses.
+	require.Empty(t, readServerIPs(context.Background(), c, &m, id))
+	assert.Equal(t, []string{"192.0.2.10"}, stringList(m.IPv4Addresses))
+	assert.Equal(t, []string{"2001:db8::10"}, stringList(m.IPv6Addresses))
+
+	fake.SetIPs(id, gona.IPs{
+		IPv4: []gona.IP{{ID: 300, IP: "192.0.2.11"}, {ID: id, Primary: 1, IP: "192.0.2.10"}},
+	})
+
+	diags := readServerIPs(context.Background(), c, &m, id)
+	require.Len(t, diags, 2)
+	assert.False(t, diags.HasError())
+	assert.Equal(t, "[NA3013] IPv4 addresses of server web01.example.com changed outside of Terraform", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "added 192.0.2.11.")
+	assert.Contains(t, diags[1].Detail, "removed 2001:db8::10.")
+	assert.Equal(t, []string{"192.0.2.10", "192.0.2.11"}, stringList(m.IPv4Addresses))
+	assert.Empty(t, stringList(m.IPv6Addresses))
+
+	// Refreshing without changes doesn't warn again.
+	assert.Empty(t, readServerIPs(context.Background(), c, &m, id))
+}
+
+func TestReadServerIPs_RefreshDisabled(t *testing.T) {
+	t.Setenv(forceRefreshEnv, "")
+	fake := NewFakeClient()
+	id := fake.AddServer(gona.Server{PrimaryIPv4: "192.0.2.10"}).LastServerID()
+
+	m := testServerModel("C-1001")
+	m.RefreshIPs = types.BoolValue(false)
+	m.IPv4Addresses = stringListValue([]string{"198.51.100.10"})
+
+	require.Empty(t, readServerIPs(context.Background(), newFakeAPIClient(fake), &m, id))
+	assert.Equal(t, []string{"198.51.100.10"}, stringList(m.IPv4Addresses))
+	assert.NotContains(t, fake.GetCalls(), "GetIPs("+strconv.Itoa(id)+")")
+}
+
+func TestDiffAddresses(t *testing.T) {
+	added, removed := diffAddresses([]string{"192.0.2.10", "192.0.2.11"}, []string{"192.0.2.11", "192.0.2.12"})
+	assert.Equal(t, []string{"192.0.2.12"}, added)
+	assert.Equal(t, []string{"192.0.2.10"}, removed)
+
+	added, removed = diffAddresses([]string{"192.0.2.10"}, []string{"192.0.2.10"})
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
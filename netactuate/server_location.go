@@ -0,0 +1,96 @@
+package netactuate
+
+import (
+	"context"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/netactuate/gona/gona"
+)
+
+// planServerLocation checks the location of a new or moved server exists,
+// accepts deployments and is part of its cloud pool, so a typo fails the
+// plan instead of the apply, after other resources were created. The location is looked up once the
+// provider is configured and the location is known.
+func planServerLocation(ctx context.Context, c *Client, resp *resource.ModifyPlanResponse, plan, state *serverBaseModel) {
+	if c == nil || plan.Location.IsUnknown() {
+		return
+	}
+
+	// A planned location_id is the current one unless it was changed, the
+	// location is looked up by name then. A server replaced in another
+	// cloud pool stays in its location.
+	id, name := 0, plan.Location.ValueString()
+	if state == nil {
+		id = int(plan.LocationID.ValueInt64())
+	} else {
+		changed := serverChanges(plan, state)
+		moved := hasChanges(changed, locationKeys...)
+		if !moved && !stringChanged(plan.CloudPool, state.CloudPool) {
+			return
+		}
+		if !moved || hasChanges(changed, "location_id") {
+			id = int(plan.LocationID.ValueInt64())
+		}
+	}
+	if id == 0 && name == "" {
+		return
+	}
+
+	attribute := path.Root("location")
+	if id != 0 {
+		attribute = path.Root("location_id")
+	}
+
+	location, diags := findLocation(ctx, c, id, name)
+	if diags.HasError() {
+		resp.Diagnostics.AddAttributeError(attribute, diags[0].Summary, diags[0].Detail)
+		return
+	}
+	if location.Disabled == 1 {
+		resp.Diagnostics.AddAttributeError(attribute,
+			codedSummary(CodeLocationDisabled, "Location "+location.Name+" doesn't accept new deployments"),
+			"Choose another location, the netactuate_locations data source lists the enabled ones with disabled = false.",
+		)
+		return
+	}
+
+	pool := gona.CloudPoolFromName(plan.CloudPool.ValueString())
+	if plan.CloudPool.IsUnknown() || pool == gona.CloudPoolDefault {
+		return
+	}
+	locations, err := c.GetLocationForPool(ctx, pool)
+	if err != nil {
+		resp.Diagnostics.Append(frameworkDiags(apiErrorDiag(err))...)
+		return
+	}
+	if !slices.ContainsFunc(locations, func(l gona.Location) bool { return l.ID == location.ID }) {
+		resp.Diagnostics.AddAttributeError(path.Root("cloud_pool"),
+			codedSummary(CodeLocationNotInPool, "Location "+location.Name+" is not part of the cloud pool "+pool.Name()),
+			"Choose another location or cloud pool.",
+		)
+	}
+}
+
+// findLocation returns the location with the ID or, when id is 0, the name
+// or the code it starts with.
+func findLocation(ctx context.Context, c *Client, id int, name string) (*gona.Location, diag.Diagnostics) {
+	locations, err := c.GetLocations(ctx)
+	if err != nil {
+		return nil, apiErrorDiag(err)
+	}
+
+	for _, location := range locations {
+		if id != 0 && location.ID == id ||
+			id == 0 && (location.Name == name || sameLocation(location.Name, name)) {
+			return &location, nil
+		}
+	}
+
+	if id != 0 {
+		return nil, errorDiag(CodeLocationNotFound, "Provided location %d doesn't exist", id)
+	}
+	return nil, errorDiag(CodeLocationNotFound, "Provided location %q doesn't exist", name)
+}
@@ -0,0 +1,111 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerResourceModifyPlan_Location(t *testing.T) {
+	tests := []struct {
+		name       string
+		location   string
+		locationID int64
+		wantError  string
+	}{
+		{name: "id", locationID: 12},
+		{name: "name", location: "Frankfurt, DE"},
+		{name: "unknown id", locationID: 99, wantError: "[NA1007] Provided location 99 doesn't exist"},
+		{name: "unknown name", location: "Atlantis", wantError: `[NA1007] Provided location "Atlantis" doesn't exist`},
+		{name: "disabled", location: "Sydney, AU", wantError: "[NA1022] Location Sydney, AU doesn't accept new deployments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, s := newTestServerResource(t, NewFakeClient())
+
+			model := serverResourceModel{serverBaseModel: testServerModel("C-1001"), WaitForRunning: types.BoolValue(true)}
+			model.Location = newLocationValue(tt.location)
+			model.LocationID = types.Int64Unknown()
+			if tt.location == "" {
+				model.Location = locationValue{StringValue: types.StringNull()}
+				model.LocationID = types.Int64Value(tt.locationID)
+			}
+
+			plan := testPlan(t, s, &model)
+			req := resource.ModifyPlanRequest{
+				Plan:  plan,
+				State: tfsdk.State{Schema: s.Schema, Raw: tftypes.NewValue(s.Schema.Type().TerraformType(context.Background()), nil)},
+			}
+			resp := &resource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(context.Background(), req, resp)
+
+			if tt.wantError == "" {
+				require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+				return
+			}
+			require.True(t, resp.Diagnostics.HasError())
+			assert.Equal(t, tt.wantError, resp.Diagnostics.Errors()[0].Summary())
+		})
+	}
+}
+
+func TestServerResourceModifyPlan_LocationUnchanged(t *testing.T) {
+	fake := NewFakeClient()
+	r, s := newTestServerResource(t, fake)
+
+	// Servers already in a location that was disabled since are kept.
+	state := serverResourceModel{serverBaseModel: testServerModel("C-1001"), WaitForRunning: types.BoolValue(true)}
+	state.ID = types.StringValue("101")
+	state.Location = newLocationValue("Sydney, AU")
+	state.LocationID = types.Int64Value(40)
+
+	req := resource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: s.Schema, Raw: testPlan(t, s, &state).Raw},
+		State:  tfsdk.State{Schema: s.Schema, Raw: testPlan(t, s, &state).Raw},
+		Plan:   testPlan(t, s, &state),
+	}
+	resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+	r.ModifyPlan(context.Background(), req, resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+	assert.NotContains(t, fake.GetCalls(), "GetLocations")
+}
+
+func TestServerResourceModifyPlan_LocationNotInPool(t *testing.T) {
+	fake := NewFakeClient()
+	fake.SetPoolLocations(gona.CloudPoolAMDEPYC, 12)
+	r, s := newTestServerResource(t, fake)
+
+	for _, tt := range []struct {
+		locationID int64
+		wantError  string
+	}{
+		{locationID: 12},
+		{locationID: 3, wantError: "[NA1023] Location Amsterdam, NL is not part of the cloud pool AMD EPYC"},
+	} {
+		model := serverResourceModel{serverBaseModel: testServerModel("C-1001"), WaitForRunning: types.BoolValue(true)}
+		model.LocationID = types.Int64Value(tt.locationID)
+		model.CloudPool = types.StringValue(gona.CloudPoolAMDEPYC.Name())
+
+		plan := testPlan(t, s, &model)
+		req := resource.ModifyPlanRequest{
+			Plan:  plan,
+			State: tfsdk.State{Schema: s.Schema, Raw: tftypes.NewValue(s.Schema.Type().TerraformType(context.Background()), nil)},
+		}
+		resp := &resource.ModifyPlanResponse{Plan: plan}
+		r.ModifyPlan(context.Background(), req, resp)
+
+		if tt.wantError == "" {
+			assert.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+			continue
+		}
+		require.True(t, resp.Diagnostics.HasError())
+		assert.Equal(t, tt.wantError, resp.Diagnostics.Errors()[0].Summary())
+	}
+}
@@ -0,0 +1,123 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/netactuate/gona/gona"
+)
+
+// osTypeWindows is the type of the Windows images, as listed by GetOSs.
+const osTypeWindows = "windows"
+
+// osAttributes returns the schema attributes describing the operating
+// system of the server's image.
+func osAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"os_type": schema.StringAttribute{
+			Computed:    true,
+			Description: "Type of the operating system of the image, e.g. `linux` or `windows`, as listed by the netactuate_oses data source",
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		},
+	}
+}
+
+// windowsIncompatible returns the configured attributes a Windows image
+// can't be built with: it has no SSH key injection or cloud-init.
+func windowsIncompatible(m *serverBaseModel) []string {
+	var names []string
+	for _, a := range []struct {
+		name  string
+		value attr.Value
+	}{
+		{"ssh_key_id", m.SSHKeyID},
+		{"ssh_key", m.SSHKey},
+		{"cloud_config", m.CloudConfig},
+	} {
+		if !a.value.IsNull() {
+			names = append(names, a.name)
+		}
+	}
+	return names
+}
+
+// planServerOS plans os_type from the image of a new or rebuilt server and
+// rejects the attributes its operating system doesn't support, so a
+// Windows server with an SSH key fails at plan time instead of in the
+// build. The image is looked up once the provider is configured and
+// image_id is known, os_type is unknown until then.
+func planServerOS(ctx context.Context, c *Client, resp *resource.ModifyPlanResponse, plan, state *serverBaseModel) {
+	osType := plan.OSType
+	if state == nil || hasChanges(serverChanges(plan, state), imageKeys...) {
+		osType = types.StringUnknown()
+		if c != nil && isSet(plan.ImageID) && !plan.ImageID.IsUnknown() {
+			os, diags := findOS(ctx, c, int(plan.ImageID.ValueInt64()))
+			resp.Diagnostics.Append(frameworkDiags(diags)...)
+			if diags.HasError() {
+				return
+			}
+			osType = types.StringValue(os.Type)
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("os_type"), osType)...)
+	}
+
+	resp.Diagnostics.Append(validateServerOS(osType.ValueString(), plan)...)
+}
+
+// validateServerOS reports the configured attributes an operating system
+// of the type doesn't support.
+func validateServerOS(osType string, m *serverBaseModel) fwdiag.Diagnostics {
+	if !strings.EqualFold(osType, osTypeWindows) {
+		return nil
+	}
+
+	var diags fwdiag.Diagnostics
+	for _, name := range windowsIncompatible(m) {
+		diags.AddAttributeError(path.Root(name),
+			codedSummary(CodeUnsupportedByOS, fmt.Sprintf("%s is not supported by Windows images", name)),
+			"Windows images have no SSH key injection or cloud-init. Set password or password_wo instead.",
+		)
+	}
+	return diags
+}
+
+// setServerOSType fills in os_type of a created or updated server if it is
+// unknown, from the image the server was built with.
+func setServerOSType(ctx context.Context, c *Client, m *serverBaseModel, imageID int) diag.Diagnostics {
+	if !m.OSType.IsUnknown() {
+		return nil
+	}
+
+	os, diags := findOS(ctx, c, imageID)
+	if diags.HasError() {
+		return diags
+	}
+	m.OSType = types.StringValue(os.Type)
+	return nil
+}
+
+// findOS returns the image with the ID.
+func findOS(ctx context.Context, c *Client, id int) (*gona.OS, diag.Diagnostics) {
+	oss, err := c.GetOSs(ctx)
+	if err != nil {
+		return nil, apiErrorDiag(err)
+	}
+	for _, os := range oss {
+		if os.ID == id {
+			return &os, nil
+		}
+	}
+	return nil, errorDiag(CodeImageNotFound, "Provided image %d doesn't exist", id)
+}
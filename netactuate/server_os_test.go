@@ -0,0 +1,88 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerResourceModifyPlan_OSType(t *testing.T) {
+	tests := []struct {
+		name      string
+		imageID   int64
+		sshKey    bool
+		wantType  string
+		wantError string
+	}{
+		{name: "linux", imageID: 1200, sshKey: true, wantType: "linux"},
+		{name: "windows", imageID: 700, wantType: "windows"},
+		{name: "windows with ssh key", imageID: 700, sshKey: true, wantError: "[NA1021] ssh_key_id is not supported by Windows images"},
+		{name: "unknown image", imageID: 1001, wantError: "[NA1008] Provided image 1001 doesn't exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, s := newTestServerResource(t, NewFakeClient())
+
+			model := serverResourceModel{serverBaseModel: testServerModel("C-1001"), WaitForRunning: types.BoolValue(true)}
+			model.ImageID = types.Int64Value(tt.imageID)
+			model.CloudConfig = types.StringNull()
+			if !tt.sshKey {
+				model.SSHKeyID = types.Int64Null()
+				model.Password = types.StringValue("s3cr3t")
+			}
+			model.OSType = types.StringUnknown()
+
+			plan := testPlan(t, s, &model)
+			req := resource.ModifyPlanRequest{
+				Plan:  plan,
+				State: tfsdk.State{Schema: s.Schema, Raw: tftypes.NewValue(s.Schema.Type().TerraformType(context.Background()), nil)},
+			}
+			resp := &resource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(context.Background(), req, resp)
+
+			if tt.wantError != "" {
+				require.True(t, resp.Diagnostics.HasError())
+				assert.Equal(t, tt.wantError, resp.Diagnostics.Errors()[0].Summary())
+				return
+			}
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+			var osType types.String
+			require.False(t, resp.Plan.GetAttribute(context.Background(), path.Root("os_type"), &osType).HasError())
+			assert.Equal(t, tt.wantType, osType.ValueString())
+		})
+	}
+}
+
+func TestValidateServerOS(t *testing.T) {
+	m := testServerModel("C-1001")
+	m.SSHKeyID = types.Int64Null()
+	m.CloudConfig = types.StringValue("#cloud-config")
+
+	diags := validateServerOS("Windows", &m)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "[NA1021] cloud_config is not supported by Windows images", diags[0].Summary())
+
+	assert.Empty(t, validateServerOS("linux", &m))
+	assert.Empty(t, validateServerOS("", &m), "an unknown OS isn't validated")
+}
+
+func TestSetServerOSType(t *testing.T) {
+	c := newFakeAPIClient(NewFakeClient())
+
+	m := testServerModel("C-1001")
+	m.OSType = types.StringUnknown()
+	require.Empty(t, setServerOSType(context.Background(), c, &m, 700))
+	assert.Equal(t, "windows", m.OSType.ValueString())
+
+	// A planned os_type is kept.
+	require.Empty(t, setServerOSType(context.Background(), c, &m, 1200))
+	assert.Equal(t, "windows", m.OSType.ValueString())
+}
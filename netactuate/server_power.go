@@ -0,0 +1,67 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+const (
+	powerStateOn  = "on"
+	powerStateOff = "off"
+)
+
+var powerStates = []string{powerStateOn, powerStateOff}
+
+// powerState maps the power status reported by the API to a power_state
+// value. It returns an empty string for transitional or unknown statuses.
+func powerState(status string) string {
+	switch strings.ToUpper(status) {
+	case "RUNNING", "ON":
+		return powerStateOn
+	case "STOPPED", "SHUTDOWN", "SHUTOFF", "OFF":
+		return powerStateOff
+	default:
+		return ""
+	}
+}
+
+// setPowerState starts or stops the server and waits until the API reports
+// the requested power state.
+func setPowerState(ctx context.Context, c *Client, id int, want string, timeout time.Duration) diag.Diagnostics {
+	var err error
+	switch want {
+	case powerStateOn:
+		err = c.StartServer(ctx, id)
+	case powerStateOff:
+		err = c.StopServer(ctx, id)
+	default:
+		return errorDiag(CodeInvalidPowerState, "unsupported power_state %q", want)
+	}
+	if err != nil {
+		return apiErrorDiag(err)
+	}
+
+	err = waitFor(ctx, c.pollClock(), intervalSec*time.Second, timeout, func() (bool, error) {
+		server, err := c.GetServer(ctx, id)
+		if err != nil {
+			if IsRetryable(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return powerState(server.PowerStatus) == want, nil
+	})
+	if errors.Is(err, errWaitTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return errorDiag(CodePowerStateTimeout, "Timeout of waiting the server to be powered %s", want)
+	}
+	if err != nil {
+		return apiErrorDiag(fmt.Errorf("waiting for server %d to be powered %s: %w", id, want, err))
+	}
+
+	return nil
+}
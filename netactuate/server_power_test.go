@@ -0,0 +1,74 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPowerState(t *testing.T) {
+	assert.Equal(t, powerStateOn, powerState("RUNNING"))
+	assert.Equal(t, powerStateOn, powerState("on"))
+	assert.Equal(t, powerStateOff, powerState("STOPPED"))
+	assert.Equal(t, powerStateOff, powerState("Shutdown"))
+	assert.Empty(t, powerState("BUILDING"))
+	assert.Empty(t, powerState(""))
+}
+
+func TestSetPowerState(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeClient()
+	id := fake.AddServer(gona.Server{ServerStatus: "RUNNING", PowerStatus: "RUNNING"}).LastServerID()
+	c := newFakeAPIClient(fake)
+
+	require.Empty(t, setPowerState(ctx, c, id, powerStateOff, time.Minute))
+	server, _ := fake.Server(id)
+	assert.Equal(t, "STOPPED", server.PowerStatus)
+
+	require.Empty(t, setPowerState(ctx, c, id, powerStateOn, time.Minute))
+	server, _ = fake.Server(id)
+	assert.Equal(t, "RUNNING", server.PowerStatus)
+
+	assert.Equal(t, []string{
+		fmt.Sprintf("StopServer(%d)", id),
+		fmt.Sprintf("GetServer(%d)", id),
+		fmt.Sprintf("StartServer(%d)", id),
+		fmt.Sprintf("GetServer(%d)", id),
+	}, fake.GetCalls())
+}
+
+func TestSetPowerState_Timeout(t *testing.T) {
+	fake := NewFakeClient()
+	id := fake.AddServer(gona.Server{ServerStatus: "RUNNING", PowerStatus: "RUNNING"}).LastServerID()
+	c := newFakeAPIClient(fake)
+
+	// The server ignores the shutdown request.
+	c.Use(func(ctx context.Context, call Call, next Invoker) (any, error) {
+		if call.Method == "StopServer" {
+			return nil, nil
+		}
+		return next(ctx)
+	})
+
+	diags := setPowerState(context.Background(), c, id, powerStateOff, 10*time.Second)
+	require.True(t, diags.HasError())
+	assert.Equal(t, "[NA3002] Timeout of waiting the server to be powered off", diags[0].Summary)
+}
+
+func TestResourceServerCreate_PoweredOff(t *testing.T) {
+	fake := NewFakeClient()
+	fake.AddContract("C-1001")
+
+	m := testServerModel("C-1001")
+	m.PowerState = types.StringValue(powerStateOff)
+
+	require.Empty(t, createServer(context.Background(), newFakeAPIClient(fake), &m, true))
+	assert.Equal(t, powerStateOff, m.PowerState.ValueString())
+	assert.Contains(t, fake.GetCalls(), "StopServer("+m.ID.ValueString()+")")
+}
@@ -0,0 +1,88 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// relocateServer moves a server to its new location without a gap in
+// service: a new server is built there and, once it is running and
+// relocated succeeded, e.g. re-established the BGP sessions of an anycast
+// node, the old server is terminated the way destroying it would. It
+// returns the ID of the new server and records it, with its build, in the
+// plan.
+//
+// When the new server fails, it is deleted and the old one is kept.
+func relocateServer(ctx context.Context, c *Client, plan *serverBaseModel, oldID int, relocated func(ctx context.Context, id int) diag.Diagnostics) (int, diag.Diagnostics) {
+	timeout := plan.timeout("update")
+
+	locationId, imageId, diags := getParams(ctx, c, plan)
+	if diags != nil {
+		return oldID, diags
+	}
+
+	req := serverCreateRequest(plan, locationId, imageId)
+	s, err := c.CreateServer(ctx, req)
+	if err != nil {
+		return oldID, apiErrorDiag(err)
+	}
+	newID := s.ServerID
+
+	abort := func(diags diag.Diagnostics) (int, diag.Diagnostics) {
+		if deleted := terminateServer(ctx, c, plan, newID, timeout); deleted.HasError() {
+			diags = append(diags, codedDiag(diag.Warning, CodeRelocationNotRolledBack,
+				fmt.Sprintf("Unable to delete server %d after its relocation failed", newID),
+				fmt.Sprintf("Server %d keeps running in its old location. Delete server %d in the NetActuate portal: %s",
+					oldID, newID, firstError(deleted)),
+			))
+		}
+		return oldID, diags
+	}
+
+	if diags := waitForBuild(ctx, newID, s.Build, c, timeout); diags.HasError() {
+		return abort(diags)
+	}
+	if relocated != nil {
+		if diags := relocated(ctx, newID); diags.HasError() {
+			return abort(diags)
+		}
+	}
+
+	if path := plan.FinalStateFile.ValueString(); path != "" {
+		if err := recordFinalState(ctx, c, oldID, path); err != nil {
+			return abort(errorDiag(CodeFinalStateNotRecorded, "Unable to record the final state of server %d to %s: %s", oldID, path, redactAPIKey(err.Error())))
+		}
+	}
+
+	// The new server is in service from here on, keep it even if the old
+	// one can't be terminated.
+	plan.ID = types.StringValue(strconv.Itoa(newID))
+	plan.BuildID = types.Int64Value(int64(s.Build))
+	plan.LastBuild = types.StringValue(s.Status)
+	diags = diag.Diagnostics{}
+
+	if deleted := terminateServer(ctx, c, plan, oldID, timeout); deleted.HasError() {
+		diags = append(diags, codedDiag(diag.Warning, CodeRelocatedServerNotDeleted,
+			fmt.Sprintf("Unable to terminate server %d after relocating it", oldID),
+			fmt.Sprintf("The server now runs as %d in its new location. Delete server %d in the NetActuate portal: %s",
+				newID, oldID, firstError(deleted)),
+		))
+	}
+
+	return newID, diags
+}
+
+// firstError returns the summary and detail of the first error in diags.
+func firstError(diags diag.Diagnostics) string {
+	for _, d := range diags {
+		if d.Severity == diag.Error {
+			return strings.TrimSuffix(d.Summary+": "+d.Detail, ": ")
+		}
+	}
+	return ""
+}
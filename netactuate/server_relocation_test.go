@@ -0,0 +1,125 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelocates(t *testing.T) {
+	tests := []struct {
+		desc    string
+		allow   bool
+		changed []string
+		want    bool
+	}{
+		{"location change", true, []string{"location_id"}, true},
+		{"location name change", true, []string{"location"}, true},
+		{"not allowed", false, []string{"location_id"}, false},
+		{"image change", true, []string{"image_id"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, relocates(tt.changed, tt.allow))
+		})
+	}
+}
+
+func relocationTestData(fake *FakeClient) (*serverBaseModel, int) {
+	fake.AddContract("C-1001")
+	oldID := fake.AddServer(gona.Server{Name: "web01.example.com", LocationID: 12, ServerStatus: "RUNNING"}).LastServerID()
+
+	m := testServerModel("C-1001")
+	m.ID = types.StringValue(strconv.Itoa(oldID))
+	m.AllowRelocation = types.BoolValue(true)
+	return &m, oldID
+}
+
+func TestRelocateServer(t *testing.T) {
+	fake := NewFakeClient()
+	m, oldID := relocationTestData(fake)
+
+	var relocatedTo int
+	newID, diags := relocateServer(context.Background(), newFakeAPIClient(fake), m, oldID, func(_ context.Context, id int) diag.Diagnostics {
+		relocatedTo = id
+		return nil
+	})
+	require.Empty(t, diags)
+
+	assert.NotEqual(t, oldID, newID)
+	assert.Equal(t, newID, relocatedTo)
+	assert.Equal(t, strconv.Itoa(newID), m.ID.ValueString())
+
+	server, _ := fake.Server(newID)
+	assert.Equal(t, 3, server.LocationID)
+	assert.Equal(t, "RUNNING", server.ServerStatus)
+
+	old, _ := fake.Server(oldID)
+	assert.Empty(t, old.ServerStatus, "the old package should be cancelled")
+
+	calls := fake.GetCalls()
+	created := slices.Index(calls, "CreateServer(web01.example.com)")
+	deleted := slices.Index(calls, fmt.Sprintf("DeleteServer(%d, true)", oldID))
+	require.GreaterOrEqual(t, created, 0)
+	assert.Greater(t, deleted, created, "the old server must only be deleted after the new one was built")
+}
+
+func TestRelocateServer_RollsBack(t *testing.T) {
+	fake := NewFakeClient()
+	m, oldID := relocationTestData(fake)
+
+	var newID int
+	id, diags := relocateServer(context.Background(), newFakeAPIClient(fake), m, oldID, func(_ context.Context, id int) diag.Diagnostics {
+		newID = id
+		return diag.Errorf("no sessions")
+	})
+	require.True(t, diags.HasError())
+
+	assert.Equal(t, oldID, id)
+	assert.Equal(t, strconv.Itoa(oldID), m.ID.ValueString())
+
+	old, _ := fake.Server(oldID)
+	assert.Equal(t, "RUNNING", old.ServerStatus, "the old server should be kept")
+	assert.Contains(t, fake.GetCalls(), fmt.Sprintf("DeleteServer(%d, true)", newID))
+}
+
+func TestRelocateServer_UnlinkOnly(t *testing.T) {
+	fake := NewFakeClient()
+	m, oldID := relocationTestData(fake)
+	m.UnlinkOnly = types.BoolValue(true)
+
+	newID, diags := relocateServer(context.Background(), newFakeAPIClient(fake), m, oldID, nil)
+	require.Empty(t, diags)
+
+	assert.Equal(t, []Call{{Method: "DeleteServer", Args: []any{oldID, false}}}, fake.CallsTo("DeleteServer"),
+		"the package of the old server should be kept")
+	assert.Equal(t, []Call{{Method: "UnlinkServer", Args: []any{oldID}}}, fake.CallsTo("UnlinkServer"))
+
+	server, _ := fake.Server(newID)
+	assert.Equal(t, "RUNNING", server.ServerStatus)
+}
+
+func TestRelocateServer_KeepBilling(t *testing.T) {
+	fake := NewFakeClient()
+	m, oldID := relocationTestData(fake)
+	m.CancelBillingOnDestroy = types.BoolValue(false)
+
+	var newID int
+	_, diags := relocateServer(context.Background(), newFakeAPIClient(fake), m, oldID, func(_ context.Context, id int) diag.Diagnostics {
+		newID = id
+		return diag.Errorf("no sessions")
+	})
+	require.True(t, diags.HasError())
+
+	assert.Equal(t, []Call{{Method: "DeleteServer", Args: []any{newID, false}}}, fake.CallsTo("DeleteServer"),
+		"rolling back should keep the billing package as destroying would")
+}
@@ -0,0 +1,100 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// serverSchemaVersion is the schema version of netactuate_server and
+// netactuate_anycast_node.
+//
+//   - 0: written by the SDK v2 versions of the resources, which stored ""
+//     and 0 for unset attributes, and by the Plugin Framework versions
+//     before the upgrade to 1.
+//   - 1: unset attributes are null.
+const serverSchemaVersion = 1
+
+// legacyZeroAttributes are the optional attributes SDK v2 stored "" or 0
+// for when they weren't configured. Left in place, the null of the
+// configuration plans an in-place update for each of them, and image_id 0
+// hides that the image was configured by name.
+var legacyZeroAttributes = []string{
+	"package_billing_opt_in",
+	"package_billing_contract_id",
+	"location",
+	"image",
+	"image_id",
+	"password",
+	"ssh_key_id",
+	"ssh_key",
+	"cloud_config",
+	"user_data",
+	"user_data_base64",
+	"params",
+}
+
+// serverStateUpgraders returns the state upgraders of a server resource
+// with the current schema s. Version 0 has the attributes of version 1:
+// attributes added since were missing from SDK v2 states and are decoded
+// as null, and the next refresh fills in the computed ones, image_id too.
+// A configuration moving from image to image_id then only rebuilds the
+// server for another image, see serverChanges.
+func serverStateUpgraders(s schema.Schema) map[int64]resource.StateUpgrader {
+	prior := s
+	prior.Version = 0
+
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &prior,
+			StateUpgrader: func(_ context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				upgraded, err := tftypes.Transform(req.State.Raw, nullLegacyZero)
+				if err != nil {
+					resp.Diagnostics.AddError(
+						codedSummary(CodeStateUpgradeFailed, "Unable to upgrade the server state"),
+						err.Error(),
+					)
+					return
+				}
+				resp.State.Raw = upgraded
+			},
+		},
+	}
+}
+
+// nullLegacyZero replaces the "" and 0 of legacyZeroAttributes with null.
+func nullLegacyZero(p *tftypes.AttributePath, v tftypes.Value) (tftypes.Value, error) {
+	steps := p.Steps()
+	if len(steps) != 1 || v.IsNull() || !v.IsKnown() {
+		return v, nil
+	}
+	name, ok := steps[0].(tftypes.AttributeName)
+	if !ok || !slices.Contains(legacyZeroAttributes, string(name)) {
+		return v, nil
+	}
+
+	switch {
+	case v.Type().Is(tftypes.String):
+		var s string
+		if err := v.As(&s); err != nil {
+			return v, fmt.Errorf("%s: %w", name, err)
+		}
+		if s == "" {
+			return tftypes.NewValue(v.Type(), nil), nil
+		}
+	case v.Type().Is(tftypes.Number):
+		var n big.Float
+		if err := v.As(&n); err != nil {
+			return v, fmt.Errorf("%s: %w", name, err)
+		}
+		if n.Sign() == 0 {
+			return tftypes.NewValue(v.Type(), nil), nil
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,138 @@
+package netactuate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSDKServerState is a netactuate_server state as written by the SDK v2
+// versions of the resource, with "" and 0 for the unset attributes.
+const testSDKServerState = `{
+	"id": "1234",
+	"hostname": "web01.example.com",
+	"plan": "VR1x1x25",
+	"package_billing": "usage",
+	"package_billing_opt_in": "",
+	"package_billing_contract_id": "C-1001",
+	"location": "AMS",
+	"location_id": 3,
+	"image": "Ubuntu 22.04 LTS x64",
+	"image_id": 0,
+	"password": "",
+	"ssh_key_id": 7,
+	"ssh_key": "",
+	"cloud_config": "",
+	"user_data": "",
+	"user_data_base64": "",
+	"primary_ipv4": "192.0.2.10",
+	"primary_ipv6": "",
+	"params": ""
+}`
+
+func upgradeTestState(t *testing.T, typeName, state string) map[string]tftypes.Value {
+	t.Helper()
+
+	var attributes map[string]tftypes.Value
+	require.NoError(t, upgradeTestValue(t, typeName, state).As(&attributes))
+	return attributes
+}
+
+// upgradeTestValue returns state upgraded by the provider.
+func upgradeTestValue(t *testing.T, typeName, state string) tftypes.Value {
+	t.Helper()
+	ctx := context.Background()
+
+	providerServer, err := NewProviderServer(ctx, "test")
+	require.NoError(t, err)
+	server := providerServer()
+
+	resp, err := server.UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: typeName,
+		Version:  0,
+		RawState: &tfprotov6.RawState{JSON: []byte(state)},
+	})
+	require.NoError(t, err)
+	for _, d := range resp.Diagnostics {
+		require.NotEqual(t, tfprotov6.DiagnosticSeverityError, d.Severity, "%s: %s", d.Summary, d.Detail)
+	}
+
+	schemas, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+	assert.EqualValues(t, serverSchemaVersion, schemas.ResourceSchemas[typeName].Version)
+
+	value, err := resp.UpgradedState.Unmarshal(schemas.ResourceSchemas[typeName].ValueType())
+	require.NoError(t, err)
+	return value
+}
+
+func TestServerStateUpgrade_SDKState(t *testing.T) {
+	attributes := upgradeTestState(t, "netactuate_server", testSDKServerState)
+
+	for _, name := range []string{"package_billing_opt_in", "image_id", "password", "ssh_key", "cloud_config", "user_data", "user_data_base64", "params"} {
+		assert.True(t, attributes[name].IsNull(), "%s should be null", name)
+	}
+	for name, want := range map[string]tftypes.Value{
+		"id":                          tftypes.NewValue(tftypes.String, "1234"),
+		"image":                       tftypes.NewValue(tftypes.String, "Ubuntu 22.04 LTS x64"),
+		"location":                    tftypes.NewValue(tftypes.String, "AMS"),
+		"location_id":                 tftypes.NewValue(tftypes.Number, 3),
+		"ssh_key_id":                  tftypes.NewValue(tftypes.Number, 7),
+		"package_billing_contract_id": tftypes.NewValue(tftypes.String, "C-1001"),
+		// Computed attributes keep what the API reported.
+		"primary_ipv6": tftypes.NewValue(tftypes.String, ""),
+	} {
+		assert.True(t, want.Equal(attributes[name]), "%s is %s, want %s", name, attributes[name], want)
+	}
+	// Attributes added since SDK v2 are null until the next refresh.
+	assert.True(t, attributes["os_type"].IsNull())
+}
+
+func TestServerStateUpgrade_ImageIDConfigured(t *testing.T) {
+	fake := NewFakeClient()
+	fake.AddServer(gona.Server{ID: 1234, Name: "web01.example.com", ServerStatus: "RUNNING", Installed: 1, OS: "Ubuntu 22.04 LTS x64", OSID: 1000})
+	_, s := newTestServerResource(t, fake)
+
+	var state serverResourceModel
+	upgraded := tfsdk.State{Schema: s.Schema, Raw: upgradeTestValue(t, "netactuate_server", testSDKServerState)}
+	require.False(t, upgraded.Get(context.Background(), &state).HasError())
+	require.False(t, readServer(context.Background(), newFakeAPIClient(fake), &state.serverBaseModel).HasError())
+
+	// The configuration moved from the image name to its ID.
+	plan := state
+	plan.Image = types.StringNull()
+	plan.ImageID = types.Int64Value(1000)
+	changed := serverChanges(&plan.serverBaseModel, &state.serverBaseModel)
+	assert.NotContains(t, changed, "image")
+	assert.False(t, needsRebuild(changed, false), "the server already runs image 1000")
+
+	plan.ImageID = types.Int64Value(1200)
+	assert.True(t, needsRebuild(serverChanges(&plan.serverBaseModel, &state.serverBaseModel), false), "another image rebuilds")
+}
+
+func TestServerStateUpgrade_AnycastNode(t *testing.T) {
+	state := `{"id": "1234", "hostname": "anycast01.example.com", "image_id": 1000, "password": "", "bgp_group_id": 4242}`
+	attributes := upgradeTestState(t, "netactuate_anycast_node", state)
+
+	assert.True(t, attributes["password"].IsNull())
+	assert.True(t, tftypes.NewValue(tftypes.Number, 1000).Equal(attributes["image_id"]))
+	assert.True(t, tftypes.NewValue(tftypes.Number, 4242).Equal(attributes["bgp_group_id"]))
+}
+
+func TestServerStateUpgraders_PriorSchema(t *testing.T) {
+	var resp resource.SchemaResponse
+	(&ServerResource{}).Schema(context.Background(), resource.SchemaRequest{}, &resp)
+
+	upgraders := serverStateUpgraders(resp.Schema)
+	require.Contains(t, upgraders, int64(0))
+	assert.EqualValues(t, 0, upgraders[0].PriorSchema.Version)
+	assert.EqualValues(t, serverSchemaVersion, resp.Schema.Version, "the current schema should keep its version")
+}
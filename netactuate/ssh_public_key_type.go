@@ -0,0 +1,153 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	_ basetypes.StringTypable                    = sshPublicKeyType{}
+	_ basetypes.StringValuableWithSemanticEquals = sshPublicKeyValue{}
+)
+
+// sshPublicKeyType is a string holding an SSH public key. Keys differing
+// only in surrounding whitespace, such as the trailing newline of a key read
+// with file(), are semantically equal.
+type sshPublicKeyType struct {
+	basetypes.StringType
+}
+
+func (t sshPublicKeyType) String() string {
+	return "sshPublicKeyType"
+}
+
+func (t sshPublicKeyType) Equal(o attr.Type) bool {
+	other, ok := o.(sshPublicKeyType)
+	return ok && t.StringType.Equal(other.StringType)
+}
+
+func (t sshPublicKeyType) ValueType(_ context.Context) attr.Value {
+	return sshPublicKeyValue{}
+}
+
+func (t sshPublicKeyType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return sshPublicKeyValue{StringValue: in}, nil
+}
+
+func (t sshPublicKeyType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	v, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := v.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type %T", v)
+	}
+	return sshPublicKeyValue{StringValue: s}, nil
+}
+
+// sshPublicKeyValue is a value of sshPublicKeyType.
+type sshPublicKeyValue struct {
+	basetypes.StringValue
+}
+
+func newSSHPublicKeyValue(key string) sshPublicKeyValue {
+	return sshPublicKeyValue{StringValue: basetypes.NewStringValue(key)}
+}
+
+func (v sshPublicKeyValue) Type(_ context.Context) attr.Type {
+	return sshPublicKeyType{}
+}
+
+func (v sshPublicKeyValue) Equal(o attr.Value) bool {
+	other, ok := o.(sshPublicKeyValue)
+	return ok && v.StringValue.Equal(other.StringValue)
+}
+
+func (v sshPublicKeyValue) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(sshPublicKeyValue)
+	if !ok {
+		diags.AddError("Semantic Equality Check Error", fmt.Sprintf("Expected sshPublicKeyValue, got %T", newValuable))
+		return false, diags
+	}
+
+	return sameSSHPublicKey(v.ValueString(), newValue.ValueString()), diags
+}
+
+// sameSSHPublicKey reports whether the keys differ in surrounding whitespace
+// only.
+func sameSSHPublicKey(a, b string) bool {
+	return strings.TrimSpace(a) == strings.TrimSpace(b)
+}
+
+// readSSHPublicKeyFile reads the public key in the file at path, which may
+// start with "~/" for the home directory. Blank lines and "#" comment lines
+// are dropped and the key is trimmed, so editing the file around the key
+// doesn't change it.
+func readSSHPublicKeyFile(path string) (string, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, rest)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var keys []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			keys = append(keys, line)
+		}
+	}
+	if len(keys) != 1 {
+		return "", fmt.Errorf("expected a single public key in %s, found %d", path, len(keys))
+	}
+	if _, err := parseSSHPublicKey(keys[0]); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return keys[0], nil
+}
+
+// parseSSHPublicKey parses a single public key in the authorized_keys
+// format, e.g. "ssh-ed25519 AAAA... comment".
+func parseSSHPublicKey(key string) (ssh.PublicKey, error) {
+	pub, _, _, rest, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(key)))
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(rest))) > 0 {
+		return nil, errors.New("expected a single public key")
+	}
+	return pub, nil
+}
+
+// sshKeyFingerprint returns the SHA256 fingerprint of a public key as
+// printed by ssh-keygen -l, e.g. "SHA256:Pa9OjCGK...". It is null for keys
+// that can't be parsed.
+func sshKeyFingerprint(key string) types.String {
+	pub, err := parseSSHPublicKey(key)
+	if err != nil {
+		return types.StringNull()
+	}
+	return types.StringValue(ssh.FingerprintSHA256(pub))
+}
@@ -0,0 +1,118 @@
+package netactuate
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// stateCipherPrefix marks, and versions the format of, values encrypted by
+// encryptStateValue: the prefix is followed by the base64 encoded AES-GCM
+// nonce and sealed value.
+const stateCipherPrefix = "aes256gcm:"
+
+const stateKeySize = 32
+
+// parseStateKey decodes a base64 encoded 256-bit key, as generated by e.g.
+// `openssl rand -base64 32` or a KMS data key.
+func parseStateKey(key string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return nil, fmt.Errorf("encryption key must be base64 encoded: %w", err)
+	}
+	if len(raw) != stateKeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes long, got %d", stateKeySize, len(raw))
+	}
+	return raw, nil
+}
+
+func validateStateKey(i any, path cty.Path) diag.Diagnostics {
+	if _, err := parseStateKey(i.(string)); err != nil {
+		return diag.Diagnostics{{Severity: diag.Error, Summary: err.Error(), AttributePath: path}}
+	}
+	return nil
+}
+
+func stateCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptStateValue encrypts plaintext so it can be stored in state. Empty
+// values stay empty.
+func encryptStateValue(key []byte, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	aead, err := stateCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return stateCipherPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptStateValue decrypts a value the provider stored encrypted in
+// state, such as the password_encrypted of a BGP session, with the base64
+// encoded key it was encrypted with.
+func DecryptStateValue(key, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	raw, err := parseStateKey(key)
+	if err != nil {
+		return "", err
+	}
+	encoded, ok := strings.CutPrefix(value, stateCipherPrefix)
+	if !ok {
+		return "", fmt.Errorf("value is not encrypted by the provider")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("decoding encrypted value: %w", err)
+	}
+
+	aead, err := stateCipher(raw)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("encrypted value is truncated")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypting value: wrong key or corrupted value")
+	}
+	return string(plaintext), nil
+}
+
+// writeOnlyString returns the configured value of a write-only attribute.
+// Write-only values are only sent with the configuration during plan and
+// apply, it is empty on refresh.
+func writeOnlyString(d *schema.ResourceData, key string) string {
+	if d.GetRawConfig().IsNull() {
+		return ""
+	}
+	v, diags := d.GetRawConfigAt(cty.GetAttrPath(key))
+	if diags.HasError() || v.IsNull() || !v.IsKnown() || !v.Type().Equals(cty.String) {
+		return ""
+	}
+	return v.AsString()
+}
@@ -0,0 +1,49 @@
+package netactuate
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStateKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune(b)), stateKeySize)))
+}
+
+func TestStateEncryption(t *testing.T) {
+	key, err := parseStateKey(testStateKey('k'))
+	require.NoError(t, err)
+
+	encrypted, err := encryptStateValue(key, "s3cr3t")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, stateCipherPrefix))
+	assert.NotContains(t, encrypted, "s3cr3t")
+
+	again, err := encryptStateValue(key, "s3cr3t")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again, "every value gets its own nonce")
+
+	plaintext, err := DecryptStateValue(testStateKey('k'), encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", plaintext)
+
+	_, err = DecryptStateValue(testStateKey('x'), encrypted)
+	assert.EqualError(t, err, "decrypting value: wrong key or corrupted value")
+
+	_, err = DecryptStateValue(testStateKey('k'), "s3cr3t")
+	assert.EqualError(t, err, "value is not encrypted by the provider")
+
+	empty, err := encryptStateValue(key, "")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestValidateStateKey(t *testing.T) {
+	assert.Empty(t, validateStateKey(testStateKey('k'), cty.Path{}))
+	assert.NotEmpty(t, validateStateKey("not base64!", cty.Path{}))
+	assert.NotEmpty(t, validateStateKey(base64.StdEncoding.EncodeToString([]byte("short")), cty.Path{}))
+}
@@ -0,0 +1,75 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sweep deletes every server and SSH key in the account whose name starts
+// with prefix, cancelling the billing of the servers. BGP sessions have no
+// delete call in the API and go away together with their server. Objects
+// that disappear while sweeping are ignored, other failures are collected
+// and returned together once everything has been attempted.
+func Sweep(ctx context.Context, client ClientInterface, prefix string) error {
+	if err := checkSweepPrefix(prefix); err != nil {
+		return err
+	}
+	return errors.Join(SweepServers(ctx, client, prefix), SweepSSHKeys(ctx, client, prefix))
+}
+
+// SweepServers deletes the servers of Sweep, together with their BGP
+// sessions.
+func SweepServers(ctx context.Context, client ClientInterface, prefix string) error {
+	if err := checkSweepPrefix(prefix); err != nil {
+		return err
+	}
+
+	servers, err := client.GetServers(ctx)
+	if err != nil {
+		return fmt.Errorf("listing servers: %w", err)
+	}
+
+	var errs []error
+	for _, server := range servers {
+		// Cancelled packages linger with a blank status, see wait4Status.
+		if !strings.HasPrefix(server.Name, prefix) || server.ServerStatus == "" {
+			continue
+		}
+		if err := client.DeleteServer(ctx, server.ID, true); err != nil && !IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("deleting server %d (%s): %w", server.ID, server.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SweepSSHKeys deletes the SSH keys of Sweep.
+func SweepSSHKeys(ctx context.Context, client ClientInterface, prefix string) error {
+	if err := checkSweepPrefix(prefix); err != nil {
+		return err
+	}
+
+	keys, err := client.GetSSHKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("listing SSH keys: %w", err)
+	}
+
+	var errs []error
+	for _, key := range keys {
+		if !strings.HasPrefix(key.Name, prefix) {
+			continue
+		}
+		if err := client.DeleteSSHKey(ctx, key.ID); err != nil && !IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("deleting SSH key %d (%s): %w", key.ID, key.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkSweepPrefix(prefix string) error {
+	if strings.TrimSpace(prefix) == "" {
+		return errors.New("refusing to sweep without a name prefix")
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweep(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeClient()
+
+	testServer := fake.AddServer(gona.Server{Name: "tf-acc-web01.example.com", ServerStatus: "RUNNING"}).LastServerID()
+	keptServer := fake.AddServer(gona.Server{Name: "web01.example.com", ServerStatus: "RUNNING"}).LastServerID()
+	testKey, err := fake.CreateSSHKey(ctx, "tf-acc-deploy", "ssh-ed25519 AAAA test")
+	require.NoError(t, err)
+	keptKey, err := fake.CreateSSHKey(ctx, "deploy", "ssh-ed25519 AAAA prod")
+	require.NoError(t, err)
+
+	require.NoError(t, Sweep(ctx, fake, "tf-acc-"))
+
+	assert.Contains(t, fake.GetCalls(), fmt.Sprintf("DeleteServer(%d, true)", testServer))
+	server, _ := fake.Server(testServer)
+	assert.Empty(t, server.ServerStatus, "swept server should be cancelled")
+	server, _ = fake.Server(keptServer)
+	assert.Equal(t, "RUNNING", server.ServerStatus)
+
+	_, err = fake.GetSSHKey(ctx, testKey.ID)
+	assert.True(t, IsNotFound(err))
+	_, err = fake.GetSSHKey(ctx, keptKey.ID)
+	assert.NoError(t, err)
+
+	// Sweeping again finds nothing left to delete.
+	calls := len(fake.GetCalls())
+	require.NoError(t, Sweep(ctx, fake, "tf-acc-"))
+	assert.Equal(t, []string{"GetServers()", "GetSSHKeys()"}, fake.GetCalls()[calls:])
+}
+
+func TestSweep_EmptyPrefix(t *testing.T) {
+	fake := NewFakeClient()
+	fake.AddServer(gona.Server{Name: "web01.example.com", ServerStatus: "RUNNING"})
+
+	assert.Error(t, Sweep(context.Background(), fake, " "))
+	assert.Empty(t, fake.GetCalls())
+}
+
+func TestSweep_CollectsErrors(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeClient()
+	fake.AddServer(gona.Server{Name: "tf-acc-web01.example.com", ServerStatus: "RUNNING"})
+	key, err := fake.CreateSSHKey(ctx, "tf-acc-deploy", "ssh-ed25519 AAAA test")
+	require.NoError(t, err)
+
+	boom := errors.New("boom")
+	c := newFakeAPIClient(fake)
+	c.Use(func(ctx context.Context, call Call, next Invoker) (any, error) {
+		if call.Method == "DeleteServer" {
+			return nil, boom
+		}
+		return next(ctx)
+	})
+
+	err = Sweep(ctx, c, "tf-acc-")
+	assert.ErrorIs(t, err, boom)
+	assert.Contains(t, fake.GetCalls(), fmt.Sprintf("DeleteSSHKey(%d)", key.ID), "keys should be swept despite server failures")
+}
+
+func TestSweepSSHKeys_KeepsServers(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeClient()
+	server := fake.AddServer(gona.Server{Name: "tf-acc-web01.example.com", ServerStatus: "RUNNING"}).LastServerID()
+	_, err := fake.CreateSSHKey(ctx, "tf-acc-deploy", "ssh-ed25519 AAAA test")
+	require.NoError(t, err)
+
+	require.NoError(t, SweepSSHKeys(ctx, fake, "tf-acc-"))
+
+	keys, err := fake.GetSSHKeys(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+	s, _ := fake.Server(server)
+	assert.Equal(t, "RUNNING", s.ServerStatus)
+}
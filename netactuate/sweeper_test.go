@@ -0,0 +1,61 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// testAccPrefix starts the names of everything the acceptance tests create,
+// so that the sweepers can clean up after interrupted runs.
+const testAccPrefix = "tf-acc-"
+
+// TestMain runs the sweepers instead of the tests with -sweep, e.g.
+//
+//	go test ./netactuate -v -sweep=all
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	resource.AddTestSweepers("netactuate_server", &resource.Sweeper{
+		Name: "netactuate_server",
+		F:    sweeper(SweepServers),
+	})
+	// BGP sessions can't be deleted on their own, they are removed by
+	// sweeping the servers they belong to.
+	resource.AddTestSweepers("netactuate_bgp_sessions", &resource.Sweeper{
+		Name:         "netactuate_bgp_sessions",
+		Dependencies: []string{"netactuate_server"},
+		F:            func(string) error { return nil },
+	})
+	resource.AddTestSweepers("netactuate_sshkey", &resource.Sweeper{
+		Name:         "netactuate_sshkey",
+		Dependencies: []string{"netactuate_server"},
+		F:            sweeper(SweepSSHKeys),
+	})
+}
+
+// sweeper returns a sweeper function deleting the objects named with
+// testAccPrefix from the account of NETACTUATE_API_KEY. NetActuate has no
+// regions, the region passed with -sweep is ignored.
+func sweeper(sweep func(context.Context, ClientInterface, string) error) func(string) error {
+	return func(string) error {
+		apiKey := os.Getenv("NETACTUATE_API_KEY")
+		if apiKey == "" {
+			return errors.New("NETACTUATE_API_KEY must be set for sweeping")
+		}
+		version := APIVersion(os.Getenv("NETACTUATE_API_VERSION"))
+		if version == "" {
+			version = DefaultAPIVersion
+		}
+		client, err := NewClient(apiKey, "", version)
+		if err != nil {
+			return err
+		}
+		return sweep(context.Background(), client, testAccPrefix)
+	}
+}
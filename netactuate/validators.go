@@ -0,0 +1,225 @@
+package netactuate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// Validators of Plugin Framework resources. Summaries of validation errors
+// the SDK v2 resources reported with a code keep it.
+
+var _ validator.String = hostnameValidator{}
+
+// hostnameValidator checks that a string is a valid hostname or FQDN.
+type hostnameValidator struct{}
+
+func (v hostnameValidator) Description(_ context.Context) string {
+	return "value must be a valid hostname"
+}
+
+func (v hostnameValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v hostnameValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if hostname := req.ConfigValue.ValueString(); !hostnameRegex.MatchString(hostname) {
+		resp.Diagnostics.AddAttributeError(req.Path, codedSummary(CodeInvalidHostname, fmt.Sprintf("%q is not a valid hostname", hostname)), "")
+	}
+}
+
+var _ validator.String = oneOfValidator{}
+
+// oneOfValidator checks that a string is one of a fixed set of values.
+type oneOfValidator struct {
+	code   DiagCode
+	values []string
+}
+
+func (v oneOfValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("value must be one of %q", v.values)
+}
+
+func (v oneOfValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v oneOfValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if value := req.ConfigValue.ValueString(); !slices.Contains(v.values, value) {
+		resp.Diagnostics.AddAttributeError(req.Path,
+			codedSummary(v.code, fmt.Sprintf("expected %s to be one of %q, got %s", req.Path, v.values, value)), "")
+	}
+}
+
+var _ validator.String = durationValidator{}
+
+// durationValidator checks that a string is a duration accepted by
+// time.ParseDuration, e.g. "30m".
+type durationValidator struct{}
+
+func (v durationValidator) Description(_ context.Context) string {
+	return `value must be a duration, e.g. "30m" or "2h"`
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := time.ParseDuration(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, codedSummary(CodeInvalidDuration, "Invalid duration"), err.Error())
+	}
+}
+
+var _ resource.ConfigValidator = oneOfAttributesValidator{}
+
+// oneOfAttributesValidator checks that at most one of the attributes is
+// configured, and with required exactly one, like ConflictsWith and
+// ExactlyOneOf of SDK v2 schemas.
+type oneOfAttributesValidator struct {
+	attributes []string
+	required   bool
+}
+
+func exactlyOneOf(attributes ...string) oneOfAttributesValidator {
+	return oneOfAttributesValidator{attributes: attributes, required: true}
+}
+
+func atMostOneOf(attributes ...string) oneOfAttributesValidator {
+	return oneOfAttributesValidator{attributes: attributes}
+}
+
+func (v oneOfAttributesValidator) Description(_ context.Context) string {
+	if v.required {
+		return fmt.Sprintf("exactly one of `%s` must be specified", strings.Join(v.attributes, ","))
+	}
+	return fmt.Sprintf("at most one of `%s` can be specified", strings.Join(v.attributes, ","))
+}
+
+func (v oneOfAttributesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v oneOfAttributesValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var specified []string
+	for _, name := range v.attributes {
+		var value attr.Value
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(name), &value)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		// An unknown value may still turn out null, leave it to the apply.
+		if value.IsUnknown() {
+			return
+		}
+		if !value.IsNull() {
+			specified = append(specified, name)
+		}
+	}
+
+	switch {
+	case len(specified) == 0 && v.required:
+		resp.Diagnostics.AddError(codedSummary(CodeConflictingArguments, "Invalid combination of arguments"),
+			fmt.Sprintf("one of `%s` must be specified", strings.Join(v.attributes, ",")))
+	case len(specified) > 1:
+		resp.Diagnostics.AddError(codedSummary(CodeConflictingArguments, "Invalid combination of arguments"),
+			fmt.Sprintf("only one of `%s` can be specified, but `%s` were specified.",
+				strings.Join(v.attributes, ","), strings.Join(specified, ",")))
+	}
+}
+
+var _ validator.String = timestampValidator{}
+
+// timestampValidator checks that a string is an RFC 3339 timestamp, e.g.
+// "2026-01-31T18:00:00Z".
+type timestampValidator struct{}
+
+func (v timestampValidator) Description(_ context.Context) string {
+	return `value must be an RFC 3339 timestamp, e.g. "2026-01-31T18:00:00Z"`
+}
+
+func (v timestampValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v timestampValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := time.Parse(time.RFC3339, req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path,
+			codedSummary(CodeInvalidTimestamp, fmt.Sprintf("%s must be an RFC 3339 timestamp", req.Path)), err.Error())
+	}
+}
+
+var _ validator.String = sshPublicKeyValidator{}
+
+// sshPublicKeyValidator checks that a string is a single SSH public key in
+// the authorized_keys format.
+type sshPublicKeyValidator struct{}
+
+func (v sshPublicKeyValidator) Description(_ context.Context) string {
+	return `value must be an SSH public key, e.g. "ssh-ed25519 AAAA... user@host"`
+}
+
+func (v sshPublicKeyValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v sshPublicKeyValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := parseSSHPublicKey(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path,
+			codedSummary(CodeInvalidSSHKey, fmt.Sprintf("%s must be an SSH public key", req.Path)), err.Error())
+	}
+}
+
+var _ validator.Int64 = int64AtLeastValidator{}
+
+// int64AtLeastValidator checks that a number is at least min.
+type int64AtLeastValidator struct {
+	min int64
+}
+
+func (v int64AtLeastValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("value must be at least %d", v.min)
+}
+
+func (v int64AtLeastValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v int64AtLeastValidator) ValidateInt64(_ context.Context, req validator.Int64Request, resp *validator.Int64Response) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if value := req.ConfigValue.ValueInt64(); value < v.min {
+		resp.Diagnostics.AddAttributeError(req.Path, codedSummary(CodeValueOutOfRange, "Invalid value"),
+			fmt.Sprintf("expected %s to be at least (%d), got %d", req.Path, v.min, value))
+	}
+}
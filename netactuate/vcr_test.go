@@ -0,0 +1,203 @@
+package netactuate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// A cassette is a recording of the HTTP interactions of the gona client with
+// the NetActuate API, replayed by tests to reproduce real API behavior
+// without credentials.
+//
+// No cassettes are recorded from the API yet, which needs an account to
+// record with; until then only the recorder and the replay are tested.
+type cassette struct {
+	// Endpoint is the path of the API endpoint, e.g. "/api/".
+	Endpoint     string        `yaml:"endpoint"`
+	Interactions []interaction `yaml:"interactions"`
+}
+
+type interaction struct {
+	Method string `yaml:"method"`
+	// URL is the path and query of the request, without the API key.
+	URL          string `yaml:"url"`
+	RequestBody  string `yaml:"request_body,omitempty"`
+	Status       int    `yaml:"status"`
+	ResponseBody string `yaml:"response_body"`
+}
+
+var (
+	// Passwords in form encoded request bodies and JSON responses, e.g. of
+	// servers being built or BGP sessions.
+	formPasswordRegex = regexp.MustCompile(`(^|&)([a-z_]*password)=[^&]*`)
+	jsonPasswordRegex = regexp.MustCompile(`("[a-z_]*password"\s*:\s*)"[^"]*"`)
+)
+
+// sanitizeInteraction removes the API key and passwords from an interaction,
+// before it is stored or matched.
+func sanitizeInteraction(i interaction) interaction {
+	i.URL = redactAPIKey(i.URL)
+	i.RequestBody = formPasswordRegex.ReplaceAllString(i.RequestBody, "${1}${2}=REDACTED")
+	i.ResponseBody = jsonPasswordRegex.ReplaceAllString(i.ResponseBody, `${1}"REDACTED"`)
+	return i
+}
+
+// recordCassette returns a client calling the API at upstream through a
+// proxy recording the interactions, which are written to path when the
+// test finishes.
+func recordCassette(t *testing.T, path, upstream, apiKey string) *Client {
+	t.Helper()
+
+	upstreamURL, err := url.Parse(upstream)
+	require.NoError(t, err)
+
+	var (
+		mu       sync.Mutex
+		recorded = cassette{Endpoint: upstreamURL.Path}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		req, err := http.NewRequestWithContext(r.Context(), r.Method, upstreamURL.Scheme+"://"+upstreamURL.Host+r.URL.RequestURI(), bytes.NewReader(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		req.Header = r.Header.Clone()
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			http.Error(w, redactAPIKey(err.Error()), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		mu.Lock()
+		recorded.Interactions = append(recorded.Interactions, sanitizeInteraction(interaction{
+			Method:       r.Method,
+			URL:          r.URL.RequestURI(),
+			RequestBody:  string(body),
+			Status:       resp.StatusCode,
+			ResponseBody: string(respBody),
+		}))
+		mu.Unlock()
+
+		w.WriteHeader(resp.StatusCode)
+		_, _ = w.Write(respBody)
+	}))
+	t.Cleanup(func() {
+		server.Close()
+
+		data, err := yaml.Marshal(&recorded)
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+	})
+
+	c, err := NewClient(apiKey, server.URL+upstreamURL.Path, DefaultAPIVersion)
+	require.NoError(t, err)
+	return c
+}
+
+// replayCassette returns a client answered from the cassette at path. Every
+// request is answered by the first unused interaction with the same method,
+// URL and body; requests without one fail the test.
+func replayCassette(t *testing.T, path string) *Client {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var recorded cassette
+	require.NoError(t, yaml.Unmarshal(data, &recorded))
+
+	var (
+		mu   sync.Mutex
+		used = make([]bool, len(recorded.Interactions))
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req := sanitizeInteraction(interaction{Method: r.Method, URL: r.URL.RequestURI(), RequestBody: string(body)})
+
+		mu.Lock()
+		defer mu.Unlock()
+		for i, recorded := range recorded.Interactions {
+			if used[i] || recorded.Method != req.Method || recorded.URL != req.URL || recorded.RequestBody != req.RequestBody {
+				continue
+			}
+			used[i] = true
+			w.WriteHeader(recorded.Status)
+			_, _ = io.WriteString(w, recorded.ResponseBody)
+			return
+		}
+
+		t.Errorf("no interaction recorded in %s for %s %s", path, req.Method, req.URL)
+		w.WriteHeader(http.StatusNotImplemented)
+		_, _ = fmt.Fprintf(w, `{"result": "error", "code": %d, "message": "not recorded"}`, http.StatusNotImplemented)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewClient("vcr-api-key", server.URL+recorded.Endpoint, DefaultAPIVersion)
+	require.NoError(t, err)
+	return c
+}
+
+func TestVCR_RecordReplay(t *testing.T) {
+	ctx := context.Background()
+	api := newTestAPI(t, map[string]string{
+		"cloud/server":     `{"mbpkgid": 42, "fqdn": "web01.example.com", "status": "RUNNING"}`,
+		"bgp/bgpsession/7": `{"id": 7, "group_id": 12, "password": "s3cr3t"}`,
+	})
+	path := filepath.Join(t.TempDir(), "record_replay.yaml")
+
+	t.Run("record", func(t *testing.T) {
+		c := recordCassette(t, path, api.URL+"/api/", "test-api-key")
+
+		server, err := c.GetServer(ctx, 42)
+		require.NoError(t, err)
+		assert.Equal(t, "web01.example.com", server.Name)
+		session, err := c.GetBGPSession(ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", session.Password)
+	})
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "test-api-key", "the API key must not be recorded")
+	assert.NotContains(t, string(data), "s3cr3t", "passwords must not be recorded")
+
+	c := replayCassette(t, path)
+	server, err := c.GetServer(ctx, 42)
+	require.NoError(t, err)
+	assert.Equal(t, "web01.example.com", server.Name)
+	session, err := c.GetBGPSession(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, 12, session.GroupID)
+	assert.Equal(t, "REDACTED", session.Password)
+}
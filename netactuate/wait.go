@@ -0,0 +1,56 @@
+package netactuate
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// errWaitTimeout is returned by waitFor when the condition wasn't met in time.
+var errWaitTimeout = errors.New("timed out")
+
+// clock abstracts time so polling loops can be tested without real sleeps.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// waitFor calls check every interval until it reports done or fails. It
+// gives up with errWaitTimeout once timeout has elapsed, or with the context
+// error if ctx is cancelled first.
+func waitFor(ctx context.Context, clk clock, interval, timeout time.Duration, check func() (bool, error)) error {
+	deadline := clk.Now().Add(timeout)
+
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		if !clk.Now().Before(deadline) {
+			return errWaitTimeout
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clk.After(interval):
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package netactuate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/netactuate/gona/gona"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock advances instantly whenever a caller waits on it.
+type fakeClock struct {
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// fakeServerPoller returns the queued responses in order, repeating the last
+// one once the queue is drained.
+type fakeServerPoller struct {
+	clock     *fakeClock
+	responses []fakeServerResponse
+	calls     int
+}
+
+type fakeServerResponse struct {
+	server gona.Server
+	err    error
+}
+
+func (p *fakeServerPoller) GetServer(_ context.Context, _ int) (gona.Server, error) {
+	r := p.responses[min(p.calls, len(p.responses)-1)]
+	p.calls++
+	return r.server, r.err
+}
+
+func (p *fakeServerPoller) pollClock() clock {
+	return p.clock
+}
+
+func TestWaitFor(t *testing.T) {
+	clk := newFakeClock()
+	start := clk.Now()
+
+	calls := 0
+	err := waitFor(context.Background(), clk, time.Second, time.Minute, func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 2*time.Second, clk.Now().Sub(start))
+}
+
+func TestWaitFor_Timeout(t *testing.T) {
+	clk := newFakeClock()
+	start := clk.Now()
+
+	err := waitFor(context.Background(), clk, time.Second, 10*time.Second, func() (bool, error) {
+		return false, nil
+	})
+
+	assert.ErrorIs(t, err, errWaitTimeout)
+	assert.Equal(t, 10*time.Second, clk.Now().Sub(start))
+}
+
+func TestWaitFor_CheckError(t *testing.T) {
+	boom := errors.New("boom")
+
+	err := waitFor(context.Background(), newFakeClock(), time.Second, time.Minute, func() (bool, error) {
+		return false, boom
+	})
+
+	assert.ErrorIs(t, err, boom)
+}
+
+func TestWaitFor_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := waitFor(ctx, realClock{}, time.Hour, 2*time.Hour, func() (bool, error) {
+		return false, nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWait4Status(t *testing.T) {
+	p := &fakeServerPoller{
+		clock: newFakeClock(),
+		responses: []fakeServerResponse{
+			{err: errors.New("mbpkgid must be a valid mbpkgid")},
+			{server: gona.Server{ID: 1, ServerStatus: "BUILDING"}},
+			{server: gona.Server{ID: 1, ServerStatus: "RUNNING"}},
+		},
+	}
+
+	server, diags := wait4Status(context.Background(), 1, "RUNNING", p, defaultServerTimeout)
+
+	assert.Empty(t, diags)
+	assert.Equal(t, "RUNNING", server.ServerStatus)
+	assert.Equal(t, 3, p.calls)
+}
+
+func TestWait4Status_Terminated(t *testing.T) {
+	p := &fakeServerPoller{
+		clock: newFakeClock(),
+		responses: []fakeServerResponse{
+			{server: gona.Server{ID: 1, ServerStatus: "RUNNING"}},
+			{server: gona.Server{}},
+		},
+	}
+
+	_, diags := wait4Status(context.Background(), 1, "TERMINATED", p, defaultServerTimeout)
+
+	assert.Empty(t, diags)
+	assert.Equal(t, 2, p.calls)
+}
+
+func TestWait4Status_PersistentError(t *testing.T) {
+	p := &fakeServerPoller{
+		clock:     newFakeClock(),
+		responses: []fakeServerResponse{{err: errors.New("boom")}},
+	}
+
+	_, diags := wait4Status(context.Background(), 1, "RUNNING", p, defaultServerTimeout)
+
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "boom", diags[0].Detail)
+	assert.Equal(t, 6, p.calls, "errors should be retried on the first attempts only")
+}
+
+func TestWait4Status_TransientError(t *testing.T) {
+	unavailable := errors.New("got an error response on GET https://vapi2.netactuate.com/api/cloud/server?mbpkgid=1&key=secret: code 503 / 503, response: unavailable / <nil>")
+
+	responses := make([]fakeServerResponse, 0)
+	for range 10 {
+		responses = append(responses, fakeServerResponse{err: unavailable})
+	}
+	responses = append(responses, fakeServerResponse{server: gona.Server{ID: 1, ServerStatus: "RUNNING"}})
+
+	p := &fakeServerPoller{clock: newFakeClock(), responses: responses}
+
+	_, diags := wait4Status(context.Background(), 1, "RUNNING", p, defaultServerTimeout)
+
+	assert.Empty(t, diags)
+	assert.Equal(t, 11, p.calls, "transient errors should be retried past the first attempts")
+}
+
+func TestWait4Status_Timeout(t *testing.T) {
+	p := &fakeServerPoller{
+		clock:     newFakeClock(),
+		responses: []fakeServerResponse{{server: gona.Server{ID: 1, ServerStatus: "BUILDING"}}},
+	}
+
+	_, diags := wait4Status(context.Background(), 1, "RUNNING", p, defaultServerTimeout)
+
+	assert.True(t, diags.HasError())
+	assert.Equal(t, `[NA3001] Timeout of waiting the server to obtain "RUNNING" status`, diags[0].Summary)
+	assert.Equal(t, tries+1, p.calls)
+}
+
+func TestWait4Status_CustomTimeout(t *testing.T) {
+	p := &fakeServerPoller{
+		clock:     newFakeClock(),
+		responses: []fakeServerResponse{{server: gona.Server{ID: 1, ServerStatus: "BUILDING"}}},
+	}
+
+	_, diags := wait4Status(context.Background(), 1, "RUNNING", p, 10*time.Second)
+
+	assert.True(t, diags.HasError())
+	assert.Equal(t, 11, p.calls)
+}
+
+func TestWait4Status_ContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	p := &fakeServerPoller{
+		clock:     newFakeClock(),
+		responses: []fakeServerResponse{{server: gona.Server{ID: 1, ServerStatus: "BUILDING"}}},
+	}
+
+	_, diags := wait4Status(ctx, 1, "RUNNING", p, time.Hour)
+
+	assert.True(t, diags.HasError())
+	assert.Equal(t, `[NA3001] Timeout of waiting the server to obtain "RUNNING" status`, diags[0].Summary)
+}
+
+func TestWait4Status_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p := &fakeServerPoller{
+		clock:     newFakeClock(),
+		responses: []fakeServerResponse{{server: gona.Server{ID: 1, ServerStatus: "BUILDING"}}},
+	}
+
+	_, diags := wait4Status(ctx, 1, "RUNNING", p, time.Hour)
+
+	assert.True(t, diags.HasError())
+	assert.Equal(t, `[NA3001] Cancelled waiting for the server to obtain "RUNNING" status`, diags[0].Summary)
+	assert.Equal(t, 1, p.calls)
+}
+
+func TestWaitForBuild_Stuck(t *testing.T) {
+	p := &fakeServerPoller{
+		clock:     newFakeClock(),
+		responses: []fakeServerResponse{{server: gona.Server{ID: 1, ServerStatus: "BUILDING"}}},
+	}
+
+	diags := waitForBuild(context.Background(), 1, 4242, p, 10*time.Second)
+
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "[NA3015] Server 1 is stuck in BUILDING status", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "Build 4242 didn't finish within 10s")
+}
+
+func TestWaitForBuild_PersistentError(t *testing.T) {
+	p := &fakeServerPoller{
+		clock:     newFakeClock(),
+		responses: []fakeServerResponse{{err: errors.New("boom")}},
+	}
+
+	diags := waitForBuild(context.Background(), 1, 4242, p, defaultServerTimeout)
+
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "boom", diags[0].Detail, "API errors aren't reported as a stuck build")
+}
+
+func TestWait4Status_Progress(t *testing.T) {
+	var out bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &out)
+
+	p := &fakeServerPoller{
+		clock:     newFakeClock(),
+		responses: []fakeServerResponse{{server: gona.Server{ID: 1, ServerStatus: "BUILDING"}}},
+	}
+
+	_, diags := wait4Status(ctx, 1, "RUNNING", p, 95*time.Second)
+	assert.True(t, diags.HasError())
+
+	entries, err := tflogtest.MultilineJSONDecode(&out)
+	require.NoError(t, err)
+	require.Len(t, entries, 3, "progress should be logged every 30s")
+	assert.Equal(t, "Waiting for server status", entries[0]["@message"])
+	assert.Equal(t, "BUILDING", entries[0]["status"])
+	assert.Equal(t, "RUNNING", entries[0]["want"])
+	assert.Equal(t, "30s", entries[0]["elapsed"])
+	assert.Equal(t, "1m30s", entries[2]["elapsed"])
+}